@@ -0,0 +1,61 @@
+// Command reconcile applies the late partial insights kept under late-insights/ to their finalized windows
+// as versioned corrections and requeues the rollups built from them, then exits. It is safe to rerun after a
+// failure. Run it while the server is stopped: windows are only locked within one process, so a correction
+// racing the server's aggregation or finalization of the same window can be overwritten.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"log-analytics/internal/app"
+	"log-analytics/internal/shared/configs"
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/shared/ulid"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := configs.LoadConfig("./configs/configs.yml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger, err := loggers.New(cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	logger = logger.With().
+		Str(loggers.FieldApp, "log-analytics").
+		Str(loggers.FieldComponent, "reconcile").
+		Str(loggers.FieldRequestID, ulid.NewULID()).
+		Logger()
+
+	reconciler, err := app.NewLateInsightReconciler(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize reconciliation: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	windowCorrections, err := reconciler.Reconcile(logger.WithContext(ctx))
+	for _, windowCorrection := range windowCorrections {
+		logger.Info().
+			Msgf("corrected %s window %s of customer %s to revision %d with batches %v",
+				windowCorrection.WindowSize, windowCorrection.WindowStart.Format(time.RFC3339), windowCorrection.CustomerID,
+				windowCorrection.Revision, windowCorrection.BatchIDs)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reconciliation failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Reconciliation done, %d windows corrected\n", len(windowCorrections))
+}
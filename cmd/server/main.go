@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // customer time zones must resolve without system zoneinfo
 
 	"log-analytics/internal/app"
 	"log-analytics/internal/shared/configs"
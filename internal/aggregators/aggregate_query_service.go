@@ -0,0 +1,373 @@
+package aggregators
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/stores"
+)
+
+const (
+	defaultPageLimit = 60
+	maxPageLimit     = 1000
+	maxQuantiles     = 10
+)
+
+var defaultQuantiles = []float64{0.5, 0.95, 0.99}
+
+// AggregateQuery holds the raw query parameters of an aggregate range read, as received from the client.
+// Validation happens in the service so every transport gets the same error codes.
+type AggregateQuery struct {
+	CustomerID string
+	WindowSize string // optional, one of the configured window sizes, defaults to the first
+	From       string // RFC3339, inclusive
+	To         string // RFC3339, exclusive
+	Cursor     string // optional, NextCursor of the previous page
+	Limit      string // optional, defaults to 60, max 1000
+}
+
+// AggregatePage is one page of window aggregate results ordered by window start.
+// NextCursor is empty when there are no more results in the requested range.
+type AggregatePage struct {
+	CustomerID string
+	WindowSize models.WindowSize
+	From       time.Time
+	To         time.Time
+	Items      []*models.WindowAggregateResult
+	NextCursor string
+}
+
+// LatencyQuery holds the raw query parameters of a latency read, merging every window of a time range.
+type LatencyQuery struct {
+	CustomerID string
+	WindowSize string // optional, see AggregateQuery
+	From       string // RFC3339, inclusive
+	To         string // RFC3339, exclusive
+	Path       string // optional, a counted path such as "GET /users/:id", every path when empty
+	Quantiles  string // optional, comma separated quantiles within 0 and 1, defaults to 0.5,0.95,0.99
+}
+
+// LatencyReport holds the latency of every path over a time range, merged from Windows window results.
+type LatencyReport struct {
+	CustomerID string
+	WindowSize models.WindowSize
+	From       time.Time
+	To         time.Time
+	Windows    int
+	Paths      map[string]*PathLatency
+}
+
+// PathLatency is the latency of one path. Quantiles are keyed by name, e.g. "p95" for 0.95, and are
+// empty when none of the merged windows kept a latency sketch.
+type PathLatency struct {
+	Count     int64
+	AvgMs     float64
+	MinMs     float64
+	MaxMs     float64
+	Quantiles map[string]float64
+}
+
+// BreakdownQuery holds the raw query parameters of a path by user agent breakdown read, merging every window
+// of a time range. Exactly one of Path and UserAgent slices the breakdown.
+type BreakdownQuery struct {
+	CustomerID string
+	WindowSize string // optional, see AggregateQuery
+	From       string // RFC3339, inclusive
+	To         string // RFC3339, exclusive
+	Path       string // a counted path such as "POST /checkout", counts its requests per user agent
+	UserAgent  string // a browser family such as "Chrome", counts its requests per path
+}
+
+// BreakdownReport holds one slice of the path by user agent breakdown over a time range, merged from Windows
+// window results. Counts is keyed by user agent when Path is set and by path when UserAgent is set.
+type BreakdownReport struct {
+	CustomerID string
+	WindowSize models.WindowSize
+	From       time.Time
+	To         time.Time
+	Windows    int
+	Path       string
+	UserAgent  string
+	Counts     map[string]int64
+}
+
+//go:generate mockgen -source=aggregate_query_service.go -destination=./mocks/aggregate_query_service_mock.go -package=mocks
+type AggregateQueryService interface {
+	// QueryAggregates returns the stored window aggregate results for a customer within a time range.
+	QueryAggregates(ctx context.Context, query AggregateQuery) (*AggregatePage, error)
+	// QueryLatency returns the latency quantiles per path over a time range of at most 1000 windows.
+	QueryLatency(ctx context.Context, query LatencyQuery) (*LatencyReport, error)
+	// QueryBreakdown returns the requests of a path per user agent, or of a user agent per path, over a time
+	// range of at most 1000 windows. It fails validation for customers not enabling the breakdown.
+	QueryBreakdown(ctx context.Context, query BreakdownQuery) (*BreakdownReport, error)
+}
+
+type aggregateQueryService struct {
+	aggregateResultStore   stores.AggregateResultStore
+	windowSizes            []models.WindowSize
+	pathUserAgentBreakdown map[string]bool
+}
+
+// NewAggregateQueryService creates an AggregateQueryService. windowSizes are the resolutions batches are
+// aggregated into; the first one is used when a query does not name a window size. Only customers set in
+// pathUserAgentBreakdown can query the path by user agent breakdown.
+func NewAggregateQueryService(aggregateResultStore stores.AggregateResultStore, windowSizes []models.WindowSize, pathUserAgentBreakdown map[string]bool) AggregateQueryService {
+	return &aggregateQueryService{
+		aggregateResultStore:   aggregateResultStore,
+		windowSizes:            windowSizes,
+		pathUserAgentBreakdown: pathUserAgentBreakdown,
+	}
+}
+
+func (s *aggregateQueryService) QueryAggregates(ctx context.Context, query AggregateQuery) (*AggregatePage, error) {
+	customerID, windowSize, from, to, err := s.parseRange(query.CustomerID, query.WindowSize, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := s.parseLimit(query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cursor is the window start of the first result on the next page
+	start := from
+	if query.Cursor != "" {
+		cursor, err := time.Parse(time.RFC3339, query.Cursor)
+		if err != nil {
+			return nil, errQueryValidationFailed("invalid cursor", err)
+		}
+		if cursor.Before(from) || !cursor.Before(to) {
+			return nil, errInvalidTimeRange("cursor is outside of the requested range")
+		}
+		start = cursor
+	}
+
+	// Read one extra result to know whether another page exists
+	items, err := s.aggregateResultStore.ListRange(ctx, customerID, windowSize, start, to, limit+1)
+	if err != nil {
+		return nil, errInternalAggregateResultStoreFailed(err)
+	}
+
+	page := &AggregatePage{
+		CustomerID: customerID,
+		WindowSize: windowSize,
+		From:       from,
+		To:         to,
+		Items:      items,
+	}
+	if len(items) > limit {
+		page.NextCursor = items[limit].WindowStart.UTC().Format(time.RFC3339)
+		page.Items = items[:limit]
+	}
+	return page, nil
+}
+
+func (s *aggregateQueryService) QueryLatency(ctx context.Context, query LatencyQuery) (*LatencyReport, error) {
+	customerID, windowSize, from, to, err := s.parseRange(query.CustomerID, query.WindowSize, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	quantiles, err := s.parseQuantiles(query.Quantiles)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.listWindows(ctx, customerID, windowSize, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sketches are mergeable, so quantiles over the range are as accurate as those of a single window
+	merged := make(map[string]*models.LatencyStats)
+	for _, item := range items {
+		for path, stats := range item.LatencyByPath {
+			if query.Path != "" && path != query.Path {
+				continue
+			}
+			if _, ok := merged[path]; !ok {
+				merged[path] = &models.LatencyStats{}
+			}
+			merged[path].Merge(stats)
+		}
+	}
+
+	report := &LatencyReport{
+		CustomerID: customerID,
+		WindowSize: windowSize,
+		From:       from,
+		To:         to,
+		Windows:    len(items),
+		Paths:      make(map[string]*PathLatency, len(merged)),
+	}
+	for path, stats := range merged {
+		if stats.Count == 0 {
+			continue
+		}
+		pathLatency := &PathLatency{
+			Count:     stats.Count,
+			AvgMs:     stats.SumMs / float64(stats.Count),
+			MinMs:     stats.MinMs,
+			MaxMs:     stats.MaxMs,
+			Quantiles: make(map[string]float64, len(quantiles)),
+		}
+		for _, q := range quantiles {
+			if value, ok := stats.Quantile(q); ok {
+				pathLatency.Quantiles[quantileName(q)] = value
+			}
+		}
+		report.Paths[path] = pathLatency
+	}
+	return report, nil
+}
+
+func (s *aggregateQueryService) QueryBreakdown(ctx context.Context, query BreakdownQuery) (*BreakdownReport, error) {
+	customerID, windowSize, from, to, err := s.parseRange(query.CustomerID, query.WindowSize, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimSpace(query.Path)
+	userAgent := strings.TrimSpace(query.UserAgent)
+	if (path == "") == (userAgent == "") {
+		return nil, errQueryValidationFailed("exactly one of path and userAgent is required", nil)
+	}
+	// Tells a disabled breakdown apart from a range without traffic
+	if !s.pathUserAgentBreakdown[customerID] {
+		return nil, errQueryValidationFailed("path by user agent breakdown is not enabled for the customer", nil)
+	}
+
+	items, err := s.listWindows(ctx, customerID, windowSize, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, item := range items {
+		if path != "" {
+			models.AddCounts(counts, item.RequestsByPathAndUserAgent[path])
+			continue
+		}
+		for itemPath, userAgents := range item.RequestsByPathAndUserAgent {
+			if count, ok := userAgents[userAgent]; ok {
+				counts[itemPath] += count
+			}
+		}
+	}
+
+	return &BreakdownReport{
+		CustomerID: customerID,
+		WindowSize: windowSize,
+		From:       from,
+		To:         to,
+		Windows:    len(items),
+		Path:       path,
+		UserAgent:  userAgent,
+		Counts:     counts,
+	}, nil
+}
+
+// listWindows returns every window result of a time range that a single query merges, at most maxPageLimit.
+func (s *aggregateQueryService) listWindows(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time) ([]*models.WindowAggregateResult, error) {
+	items, err := s.aggregateResultStore.ListRange(ctx, customerID, windowSize, from, to, maxPageLimit+1)
+	if err != nil {
+		return nil, errInternalAggregateResultStoreFailed(err)
+	}
+	if len(items) > maxPageLimit {
+		return nil, errQueryValidationFailed(fmt.Sprintf("time range covers more than %d windows, use a larger windowSize", maxPageLimit), nil)
+	}
+	return items, nil
+}
+
+// parseRange validates the customer, window size and time range shared by every query.
+func (s *aggregateQueryService) parseRange(customerIDParam string, windowSizeParam string, fromParam string, toParam string) (string, models.WindowSize, time.Time, time.Time, error) {
+	customerID := strings.TrimSpace(customerIDParam)
+	if customerID == "" || strings.ContainsAny(customerID, `/\`) {
+		return "", "", time.Time{}, time.Time{}, errQueryValidationFailed("customerID is invalid", nil)
+	}
+
+	windowSize, err := s.parseWindowSize(windowSizeParam)
+	if err != nil {
+		return "", "", time.Time{}, time.Time{}, err
+	}
+
+	from, err := s.parseTimeParam("from", fromParam)
+	if err != nil {
+		return "", "", time.Time{}, time.Time{}, err
+	}
+	to, err := s.parseTimeParam("to", toParam)
+	if err != nil {
+		return "", "", time.Time{}, time.Time{}, err
+	}
+	if !from.Before(to) {
+		return "", "", time.Time{}, time.Time{}, errInvalidTimeRange("from must be before to")
+	}
+	return customerID, windowSize, from, to, nil
+}
+
+// parseWindowSize returns the requested window size, which must be one of the aggregated resolutions.
+func (s *aggregateQueryService) parseWindowSize(value string) (models.WindowSize, error) {
+	if value == "" {
+		return s.windowSizes[0], nil
+	}
+	windowSize, err := models.NewWindowSizeFromString(value)
+	if err != nil {
+		return "", errQueryValidationFailed(fmt.Sprintf("unsupported windowSize: %q", value), err)
+	}
+	if !slices.Contains(s.windowSizes, windowSize) {
+		return "", errQueryValidationFailed(fmt.Sprintf("windowSize %q is not aggregated, configured sizes: %v", value, s.windowSizes), nil)
+	}
+	return windowSize, nil
+}
+
+func (s *aggregateQueryService) parseTimeParam(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errInvalidTimeRange(fmt.Sprintf("%s is required", name))
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errInvalidTimeRange(fmt.Sprintf("%s must be an RFC3339 timestamp", name))
+	}
+	return t, nil
+}
+
+func (s *aggregateQueryService) parseLimit(value string) (int, error) {
+	if value == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errQueryValidationFailed(fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), nil)
+	}
+	return limit, nil
+}
+
+func (s *aggregateQueryService) parseQuantiles(value string) ([]float64, error) {
+	if value == "" {
+		return defaultQuantiles, nil
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) > maxQuantiles {
+		return nil, errQueryValidationFailed(fmt.Sprintf("at most %d quantiles can be queried", maxQuantiles), nil)
+	}
+	quantiles := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		q, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || q < 0 || q > 1 {
+			return nil, errQueryValidationFailed(fmt.Sprintf("quantiles must be between 0 and 1: %q", part), nil)
+		}
+		quantiles = append(quantiles, q)
+	}
+	return quantiles, nil
+}
+
+// quantileName names a quantile as a percentile, e.g. "p99.9" for 0.999.
+func quantileName(q float64) string {
+	// Rounded, as 0.999*100 is not exactly 99.9 in floating point
+	return "p" + strconv.FormatFloat(math.Round(q*1e6)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,424 @@
+package aggregators_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/svcerrors"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQueryAggregates_Success_SinglePage(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute, models.WindowHour}, nil)
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	stored := []*models.WindowAggregateResult{
+		models.NewEmptyWindowAggregateResult("cus-axon", from.Add(3*time.Minute), models.WindowMinute),
+		models.NewEmptyWindowAggregateResult("cus-axon", from.Add(4*time.Minute), models.WindowMinute),
+	}
+
+	aggregateResultStore.EXPECT().
+		ListRange(gomock.Any(), "cus-axon", models.WindowMinute, from, to, 61).
+		Return(stored, nil)
+
+	page, err := service.QueryAggregates(context.Background(), aggregators.AggregateQuery{
+		CustomerID: "cus-axon",
+		From:       "2025-12-28T18:00:00Z",
+		To:         "2025-12-28T19:00:00Z",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "cus-axon", page.CustomerID)
+	assert.Equal(t, models.WindowMinute, page.WindowSize)
+	assert.Equal(t, stored, page.Items)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestQueryAggregates_Success_Pagination(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute, models.WindowHour}, nil)
+
+	cursor := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	stored := []*models.WindowAggregateResult{
+		models.NewEmptyWindowAggregateResult("cus-axon", cursor, models.WindowHour),
+		models.NewEmptyWindowAggregateResult("cus-axon", cursor.Add(time.Hour), models.WindowHour),
+		models.NewEmptyWindowAggregateResult("cus-axon", cursor.Add(2*time.Hour), models.WindowHour),
+	}
+
+	aggregateResultStore.EXPECT().
+		ListRange(gomock.Any(), "cus-axon", models.WindowHour, cursor, to, 3).
+		Return(stored, nil)
+
+	page, err := service.QueryAggregates(context.Background(), aggregators.AggregateQuery{
+		CustomerID: "cus-axon",
+		WindowSize: "hour",
+		From:       "2025-12-28T18:00:00Z",
+		To:         "2025-12-28T19:00:00Z",
+		Cursor:     "2025-12-28T18:03:00Z",
+		Limit:      "2",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.WindowHour, page.WindowSize)
+	assert.Equal(t, stored[:2], page.Items)
+	assert.Equal(t, "2025-12-28T20:03:00Z", page.NextCursor)
+}
+
+func TestQueryAggregates_ErrValidationFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute, models.WindowHour}, nil)
+
+	valid := aggregators.AggregateQuery{
+		CustomerID: "cus-axon",
+		From:       "2025-12-28T18:00:00Z",
+		To:         "2025-12-28T19:00:00Z",
+	}
+
+	tests := []struct {
+		name         string
+		modify       func(q *aggregators.AggregateQuery)
+		expectedCode string
+	}{
+		{
+			name:         "missing customer",
+			modify:       func(q *aggregators.AggregateQuery) { q.CustomerID = "" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "customer with path separator",
+			modify:       func(q *aggregators.AggregateQuery) { q.CustomerID = "cus-axon/../cus-other" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "unsupported window size",
+			modify:       func(q *aggregators.AggregateQuery) { q.WindowSize = "fortnight" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "window size not aggregated",
+			modify:       func(q *aggregators.AggregateQuery) { q.WindowSize = "day" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "missing from",
+			modify:       func(q *aggregators.AggregateQuery) { q.From = "" },
+			expectedCode: "AGG_1001",
+		},
+		{
+			name:         "invalid to",
+			modify:       func(q *aggregators.AggregateQuery) { q.To = "yesterday" },
+			expectedCode: "AGG_1001",
+		},
+		{
+			name:         "from after to",
+			modify:       func(q *aggregators.AggregateQuery) { q.From = "2025-12-28T20:00:00Z" },
+			expectedCode: "AGG_1001",
+		},
+		{
+			name:         "empty range",
+			modify:       func(q *aggregators.AggregateQuery) { q.From = q.To },
+			expectedCode: "AGG_1001",
+		},
+		{
+			name:         "limit out of bounds",
+			modify:       func(q *aggregators.AggregateQuery) { q.Limit = "1001" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "non numeric limit",
+			modify:       func(q *aggregators.AggregateQuery) { q.Limit = "ten" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "invalid cursor",
+			modify:       func(q *aggregators.AggregateQuery) { q.Cursor = "abc" },
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "cursor outside range",
+			modify:       func(q *aggregators.AggregateQuery) { q.Cursor = "2025-12-28T19:00:00Z" },
+			expectedCode: "AGG_1001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := valid
+			tt.modify(&query)
+
+			page, err := service.QueryAggregates(context.Background(), query)
+
+			require.Error(t, err)
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, "invalid_argument", svcErr.Category)
+			assert.Nil(t, page)
+		})
+	}
+}
+
+func TestQueryAggregates_ErrStoreFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute, models.WindowHour}, nil)
+
+	aggregateResultStore.EXPECT().
+		ListRange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, assert.AnError)
+
+	page, err := service.QueryAggregates(context.Background(), aggregators.AggregateQuery{
+		CustomerID: "cus-axon",
+		From:       "2025-12-28T18:00:00Z",
+		To:         "2025-12-28T19:00:00Z",
+	})
+
+	require.Error(t, err)
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok, "expected ServiceError")
+	assert.Equal(t, "AGG_9001", svcErr.Code)
+	assert.Nil(t, page)
+}
+
+func TestQueryLatency_MergesWindows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute}, nil)
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	first := models.NewEmptyWindowAggregateResult("cus-axon", from, models.WindowMinute)
+	second := models.NewEmptyWindowAggregateResult("cus-axon", from.Add(time.Minute), models.WindowMinute)
+	for i := 1; i <= 100; i++ {
+		window := first
+		if i > 50 {
+			window = second
+		}
+		for _, path := range []string{"GET /", "POST /checkout"} {
+			if window.LatencyByPath[path] == nil {
+				window.LatencyByPath[path] = &models.LatencyStats{}
+			}
+			window.LatencyByPath[path].Observe(float64(i))
+		}
+	}
+
+	aggregateResultStore.EXPECT().
+		ListRange(gomock.Any(), "cus-axon", models.WindowMinute, from, to, 1001).
+		Return([]*models.WindowAggregateResult{first, second}, nil)
+
+	report, err := service.QueryLatency(context.Background(), aggregators.LatencyQuery{
+		CustomerID: "cus-axon",
+		From:       "2025-12-28T18:00:00Z",
+		To:         "2025-12-28T19:00:00Z",
+		Path:       "POST /checkout",
+		Quantiles:  "0.5,0.999",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Windows)
+	require.Len(t, report.Paths, 1)
+	latency := report.Paths["POST /checkout"]
+	require.NotNil(t, latency)
+	assert.Equal(t, int64(100), latency.Count)
+	assert.Equal(t, 50.5, latency.AvgMs)
+	assert.Equal(t, 1.0, latency.MinMs)
+	assert.Equal(t, 100.0, latency.MaxMs)
+	require.Len(t, latency.Quantiles, 2)
+	assert.InEpsilon(t, 50, latency.Quantiles["p50"], 0.01)
+	assert.InEpsilon(t, 99, latency.Quantiles["p99.9"], 0.01)
+}
+
+func TestQueryLatency_ErrValidationFailed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        aggregators.LatencyQuery
+		windows      int
+		expectedCode string
+	}{
+		{
+			name:         "missing time range",
+			query:        aggregators.LatencyQuery{CustomerID: "cus-axon"},
+			expectedCode: "AGG_1001",
+		},
+		{
+			name:         "quantile out of range",
+			query:        aggregators.LatencyQuery{CustomerID: "cus-axon", From: "2025-12-28T18:00:00Z", To: "2025-12-28T19:00:00Z", Quantiles: "0.5,1.5"},
+			expectedCode: "AGG_1000",
+		},
+		{
+			name:         "too many windows",
+			query:        aggregators.LatencyQuery{CustomerID: "cus-axon", From: "2025-12-28T00:00:00Z", To: "2025-12-29T00:00:00Z"},
+			windows:      1001,
+			expectedCode: "AGG_1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute}, nil)
+			if tt.windows > 0 {
+				aggregateResultStore.EXPECT().
+					ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), 1001).
+					Return(make([]*models.WindowAggregateResult, tt.windows), nil)
+			}
+
+			report, err := service.QueryLatency(context.Background(), tt.query)
+
+			require.Error(t, err)
+			assert.Nil(t, report)
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+		})
+	}
+}
+
+func TestQueryBreakdown_SlicesEitherWay(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	first := models.NewEmptyWindowAggregateResult("cus-axon", from, models.WindowMinute)
+	first.RequestsByPathAndUserAgent = map[string]map[string]int64{
+		"POST /checkout": {"Chrome": 3, "Safari": 1},
+		"GET /":          {"Chrome": 5},
+	}
+	second := models.NewEmptyWindowAggregateResult("cus-axon", from.Add(time.Minute), models.WindowMinute)
+	second.RequestsByPathAndUserAgent = map[string]map[string]int64{
+		"POST /checkout": {"Chrome": 2, "Firefox": 4},
+	}
+	// Stored before the breakdown was enabled
+	third := models.NewEmptyWindowAggregateResult("cus-axon", from.Add(2*time.Minute), models.WindowMinute)
+
+	tests := []struct {
+		name     string
+		query    aggregators.BreakdownQuery
+		expected map[string]int64
+	}{
+		{
+			name:     "user agents of a path",
+			query:    aggregators.BreakdownQuery{Path: "POST /checkout"},
+			expected: map[string]int64{"Chrome": 5, "Safari": 1, "Firefox": 4},
+		},
+		{
+			name:     "paths of a user agent",
+			query:    aggregators.BreakdownQuery{UserAgent: "Chrome"},
+			expected: map[string]int64{"POST /checkout": 5, "GET /": 5},
+		},
+		{
+			name:     "unknown path",
+			query:    aggregators.BreakdownQuery{Path: "GET /missing"},
+			expected: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute}, map[string]bool{"cus-axon": true})
+			aggregateResultStore.EXPECT().
+				ListRange(gomock.Any(), "cus-axon", models.WindowMinute, from, to, 1001).
+				Return([]*models.WindowAggregateResult{first, second, third}, nil)
+
+			query := tt.query
+			query.CustomerID = "cus-axon"
+			query.From = "2025-12-28T18:00:00Z"
+			query.To = "2025-12-28T19:00:00Z"
+			report, err := service.QueryBreakdown(context.Background(), query)
+
+			require.NoError(t, err)
+			assert.Equal(t, 3, report.Windows)
+			assert.Equal(t, tt.query.Path, report.Path)
+			assert.Equal(t, tt.query.UserAgent, report.UserAgent)
+			assert.Equal(t, tt.expected, report.Counts)
+		})
+	}
+}
+
+func TestQueryBreakdown_ErrValidationFailed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query aggregators.BreakdownQuery
+	}{
+		{
+			name:  "neither path nor user agent",
+			query: aggregators.BreakdownQuery{CustomerID: "cus-axon", From: "2025-12-28T18:00:00Z", To: "2025-12-28T19:00:00Z"},
+		},
+		{
+			name:  "both path and user agent",
+			query: aggregators.BreakdownQuery{CustomerID: "cus-axon", From: "2025-12-28T18:00:00Z", To: "2025-12-28T19:00:00Z", Path: "GET /", UserAgent: "Chrome"},
+		},
+		{
+			name:  "breakdown not enabled",
+			query: aggregators.BreakdownQuery{CustomerID: "cus-other", From: "2025-12-28T18:00:00Z", To: "2025-12-28T19:00:00Z", Path: "GET /"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			service := aggregators.NewAggregateQueryService(aggregateResultStore, []models.WindowSize{models.WindowMinute}, map[string]bool{"cus-axon": true})
+
+			report, err := service.QueryBreakdown(context.Background(), tt.query)
+
+			require.Error(t, err)
+			assert.Nil(t, report)
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok)
+			assert.Equal(t, "AGG_1000", svcErr.Code)
+		})
+	}
+}
@@ -1,21 +1,34 @@
 package aggregators
 
 import (
+	"errors"
 	"fmt"
 	"log-analytics/internal/events"
 	"log-analytics/internal/models"
 )
 
+// ErrBatchAlreadyApplied is returned by Rollup when the partial insight's batch was already rolled up
+// into the aggregate. The aggregate is left unchanged.
+var ErrBatchAlreadyApplied = errors.New("batch already applied to window aggregate")
+
 //go:generate mockgen -source=aggregate_rolluper.go -destination=./mocks/aggregate_rolluper_mock.go -package=mocks
 type WindowAggregateRolluper interface {
 	// Rollup mutates agg by accumulating values from partial.
+	// It returns ErrBatchAlreadyApplied when partial.BatchID was already rolled up into agg.
 	Rollup(agg *models.WindowAggregateResult, partial *events.PartialInsightEvent) error
+	// Merge mutates agg by accumulating values from source, a result of a finer window size contained in agg.
+	// Applied batch IDs are not carried over: a merged window is rebuilt from its sources, never updated per batch.
+	Merge(agg *models.WindowAggregateResult, source *models.WindowAggregateResult) error
 }
 
-type aggregateRolluper struct{}
+type aggregateRolluper struct {
+	limits models.CardinalityLimits
+}
 
-func NewAggregateRolluper() WindowAggregateRolluper {
-	return &aggregateRolluper{}
+// NewAggregateRolluper creates a WindowAggregateRolluper that caps the dimensions of every merged window to
+// limits, folding the tail into models.OtherKey.
+func NewAggregateRolluper(limits models.CardinalityLimits) WindowAggregateRolluper {
+	return &aggregateRolluper{limits: limits}
 }
 
 func (a *aggregateRolluper) Rollup(agg *models.WindowAggregateResult, partial *events.PartialInsightEvent) error {
@@ -30,19 +43,38 @@ func (a *aggregateRolluper) Rollup(agg *models.WindowAggregateResult, partial *e
 		return fmt.Errorf("windowSize mismatch: agg=%q, partial=%q", agg.WindowSize, partial.WindowSize)
 	}
 
-	// Merge RequestsByPath
-	if partial.RequestsByPath != nil {
-		for k, v := range partial.RequestsByPath {
-			agg.RequestsByPath[k] += v
-		}
+	// Skip redelivered partial insights
+	if agg.HasAppliedBatch(partial.BatchID) {
+		return ErrBatchAlreadyApplied
 	}
 
-	// Merge RequestsByUserAgent
-	if partial.RequestsByUserAgent != nil {
-		for k, v := range partial.RequestsByUserAgent {
-			agg.RequestsByUserAgent[k] += v
-		}
+	// Merge every dimension
+	agg.Add(partial.WindowAggregates)
+	a.capCounts(agg)
+	agg.MarkBatchApplied(partial.BatchID)
+	return nil
+}
+
+func (a *aggregateRolluper) Merge(agg *models.WindowAggregateResult, source *models.WindowAggregateResult) error {
+	// Validate that source belongs to agg
+	if agg.CustomerID != source.CustomerID {
+		return fmt.Errorf("customerID mismatch: agg=%q, source=%q", agg.CustomerID, source.CustomerID)
+	}
+	if agg.WindowSize == source.WindowSize {
+		return fmt.Errorf("windowSize of source must be finer: agg=%q, source=%q", agg.WindowSize, source.WindowSize)
+	}
+	if source.WindowStart.Before(agg.WindowStart) {
+		return fmt.Errorf("source window starts before agg: agg=%v, source=%v", agg.WindowStart, source.WindowStart)
 	}
 
+	agg.Add(source.WindowAggregates)
+	a.capCounts(agg)
 	return nil
 }
+
+// capCounts applies the cardinality limits to agg after a merge.
+func (a *aggregateRolluper) capCounts(agg *models.WindowAggregateResult) {
+	for _, name := range agg.Cap(a.limits) {
+		metricWindowKeysCappedTotal.WithLabelValues(name).Inc()
+	}
+}
@@ -1,47 +1,58 @@
 package aggregators
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"log-analytics/internal/events"
 	"log-analytics/internal/models"
+	"log-analytics/internal/shared/sketches"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAggregateRolluper_Rollup_MergesOverlappingKeys(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 5, "POST /logs": 3},
-		RequestsByUserAgent: map[string]int64{"Chrome": 4, "Firefox": 2},
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 5, "POST /logs": 3},
+				models.DimensionUserAgent: {"Chrome": 4, "Firefox": 2},
+			},
+		},
 	}
 
 	partial := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch456",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 2, "POST /logs": 1},
-		RequestsByUserAgent: map[string]int64{"Chrome": 3, "Firefox": 1},
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2, "POST /logs": 1},
+				models.DimensionUserAgent: {"Chrome": 3, "Firefox": 1},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial)
 	assert.NoError(t, err)
 
 	// Verify overlapping keys are incremented correctly
-	assert.Equal(t, int64(7), agg.RequestsByPath["GET /"], "GET / should be 5+2=7")
-	assert.Equal(t, int64(4), agg.RequestsByPath["POST /logs"], "POST /logs should be 3+1=4")
-	assert.Equal(t, int64(7), agg.RequestsByUserAgent["Chrome"], "Chrome should be 4+3=7")
-	assert.Equal(t, int64(3), agg.RequestsByUserAgent["Firefox"], "Firefox should be 2+1=3")
+	assert.Equal(t, int64(7), agg.Dimensions[models.DimensionPath]["GET /"], "GET / should be 5+2=7")
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionPath]["POST /logs"], "POST /logs should be 3+1=4")
+	assert.Equal(t, int64(7), agg.Dimensions[models.DimensionUserAgent]["Chrome"], "Chrome should be 4+3=7")
+	assert.Equal(t, int64(3), agg.Dimensions[models.DimensionUserAgent]["Firefox"], "Firefox should be 2+1=3")
 
 	// Verify identity fields unchanged
 	assert.Equal(t, "customer123", agg.CustomerID)
@@ -52,39 +63,47 @@ func TestAggregateRolluper_Rollup_MergesOverlappingKeys(t *testing.T) {
 func TestAggregateRolluper_Rollup_AddsNewKeys(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 5},
-		RequestsByUserAgent: map[string]int64{"Chrome": 4},
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 5},
+				models.DimensionUserAgent: {"Chrome": 4},
+			},
+		},
 	}
 
 	partial := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch456",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"PUT /users": 3, "DELETE /sessions": 1},
-		RequestsByUserAgent: map[string]int64{"Safari": 2, "curl": 1},
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"PUT /users": 3, "DELETE /sessions": 1},
+				models.DimensionUserAgent: {"Safari": 2, "curl": 1},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial)
 	assert.NoError(t, err)
 
 	// Verify existing keys unchanged
-	assert.Equal(t, int64(5), agg.RequestsByPath["GET /"])
-	assert.Equal(t, int64(4), agg.RequestsByUserAgent["Chrome"])
+	assert.Equal(t, int64(5), agg.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionUserAgent]["Chrome"])
 
 	// Verify new keys are created
-	assert.Equal(t, int64(3), agg.RequestsByPath["PUT /users"])
-	assert.Equal(t, int64(1), agg.RequestsByPath["DELETE /sessions"])
-	assert.Equal(t, int64(2), agg.RequestsByUserAgent["Safari"])
-	assert.Equal(t, int64(1), agg.RequestsByUserAgent["curl"])
+	assert.Equal(t, int64(3), agg.Dimensions[models.DimensionPath]["PUT /users"])
+	assert.Equal(t, int64(1), agg.Dimensions[models.DimensionPath]["DELETE /sessions"])
+	assert.Equal(t, int64(2), agg.Dimensions[models.DimensionUserAgent]["Safari"])
+	assert.Equal(t, int64(1), agg.Dimensions[models.DimensionUserAgent]["curl"])
 
 	// Verify all keys are present
 	expectedPaths := map[string]int64{
@@ -97,33 +116,41 @@ func TestAggregateRolluper_Rollup_AddsNewKeys(t *testing.T) {
 		"Safari": 2,
 		"curl":   1,
 	}
-	assert.Equal(t, expectedPaths, agg.RequestsByPath)
-	assert.Equal(t, expectedUserAgents, agg.RequestsByUserAgent)
+	assert.Equal(t, expectedPaths, agg.Dimensions[models.DimensionPath])
+	assert.Equal(t, expectedUserAgents, agg.Dimensions[models.DimensionUserAgent])
 }
 
 func TestAggregateRolluper_Rollup_ComplexMerge(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 10, "POST /logs": 5},
-		RequestsByUserAgent: map[string]int64{"Chrome": 8, "Firefox": 3},
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 10, "POST /logs": 5},
+				models.DimensionUserAgent: {"Chrome": 8, "Firefox": 3},
+			},
+		},
 	}
 
 	// First rollup
 	partial1 := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch1",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 2, "PUT /users": 1},
-		RequestsByUserAgent: map[string]int64{"Chrome": 1, "Safari": 2},
+		CustomerID:  "customer123",
+		BatchID:     "batch1",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2, "PUT /users": 1},
+				models.DimensionUserAgent: {"Chrome": 1, "Safari": 2},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial1)
@@ -131,12 +158,16 @@ func TestAggregateRolluper_Rollup_ComplexMerge(t *testing.T) {
 
 	// Second rollup
 	partial2 := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch2",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"POST /logs": 3, "DELETE /sessions": 1},
-		RequestsByUserAgent: map[string]int64{"Firefox": 2, "curl": 1},
+		CustomerID:  "customer123",
+		BatchID:     "batch2",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"POST /logs": 3, "DELETE /sessions": 1},
+				models.DimensionUserAgent: {"Firefox": 2, "curl": 1},
+			},
+		},
 	}
 
 	err = rolluper.Rollup(agg, partial2)
@@ -156,32 +187,40 @@ func TestAggregateRolluper_Rollup_ComplexMerge(t *testing.T) {
 		"curl":    1,
 	}
 
-	assert.Equal(t, expectedPaths, agg.RequestsByPath)
-	assert.Equal(t, expectedUserAgents, agg.RequestsByUserAgent)
+	assert.Equal(t, expectedPaths, agg.Dimensions[models.DimensionPath])
+	assert.Equal(t, expectedUserAgents, agg.Dimensions[models.DimensionUserAgent])
 }
 
 func TestAggregateRolluper_Rollup_ReturnsErrorOnCustomerIDMismatch(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 5},
-		RequestsByUserAgent: map[string]int64{"Chrome": 4},
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 5},
+				models.DimensionUserAgent: {"Chrome": 4},
+			},
+		},
 	}
 
 	partial := &events.PartialInsightEvent{
-		CustomerID:          "customer456",
-		BatchID:             "batch456",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 2},
-		RequestsByUserAgent: map[string]int64{"Chrome": 1},
+		CustomerID:  "customer456",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2},
+				models.DimensionUserAgent: {"Chrome": 1},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial)
@@ -191,33 +230,41 @@ func TestAggregateRolluper_Rollup_ReturnsErrorOnCustomerIDMismatch(t *testing.T)
 	assert.Contains(t, err.Error(), "customer456")
 
 	// Verify agg was not modified
-	assert.Equal(t, int64(5), agg.RequestsByPath["GET /"])
-	assert.Equal(t, int64(4), agg.RequestsByUserAgent["Chrome"])
+	assert.Equal(t, int64(5), agg.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionUserAgent]["Chrome"])
 }
 
 func TestAggregateRolluper_Rollup_ReturnsErrorOnWindowStartMismatch(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart1 := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 	windowStart2 := time.Date(2025, 12, 21, 14, 22, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart1,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 5},
-		RequestsByUserAgent: map[string]int64{"Chrome": 4},
+		CustomerID:  "customer123",
+		WindowStart: windowStart1,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 5},
+				models.DimensionUserAgent: {"Chrome": 4},
+			},
+		},
 	}
 
 	partial := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch456",
-		WindowStart:         windowStart2,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 2},
-		RequestsByUserAgent: map[string]int64{"Chrome": 1},
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart2,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2},
+				models.DimensionUserAgent: {"Chrome": 1},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial)
@@ -225,32 +272,40 @@ func TestAggregateRolluper_Rollup_ReturnsErrorOnWindowStartMismatch(t *testing.T
 	assert.Contains(t, err.Error(), "windowStart mismatch")
 
 	// Verify agg was not modified
-	assert.Equal(t, int64(5), agg.RequestsByPath["GET /"])
-	assert.Equal(t, int64(4), agg.RequestsByUserAgent["Chrome"])
+	assert.Equal(t, int64(5), agg.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionUserAgent]["Chrome"])
 }
 
 func TestAggregateRolluper_Rollup_ReturnsErrorOnWindowSizeMismatch(t *testing.T) {
 	t.Parallel()
 
-	rolluper := NewAggregateRolluper()
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
 
 	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 
 	agg := &models.WindowAggregateResult{
-		CustomerID:          "customer123",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowMinute,
-		RequestsByPath:      map[string]int64{"GET /": 5},
-		RequestsByUserAgent: map[string]int64{"Chrome": 4},
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 5},
+				models.DimensionUserAgent: {"Chrome": 4},
+			},
+		},
 	}
 
 	partial := &events.PartialInsightEvent{
-		CustomerID:          "customer123",
-		BatchID:             "batch456",
-		WindowStart:         windowStart,
-		WindowSize:          models.WindowHour,
-		RequestsByPath:      map[string]int64{"GET /": 2},
-		RequestsByUserAgent: map[string]int64{"Chrome": 1},
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowHour,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2},
+				models.DimensionUserAgent: {"Chrome": 1},
+			},
+		},
 	}
 
 	err := rolluper.Rollup(agg, partial)
@@ -260,6 +315,354 @@ func TestAggregateRolluper_Rollup_ReturnsErrorOnWindowSizeMismatch(t *testing.T)
 	assert.Contains(t, err.Error(), "hour")
 
 	// Verify agg was not modified
-	assert.Equal(t, int64(5), agg.RequestsByPath["GET /"])
-	assert.Equal(t, int64(4), agg.RequestsByUserAgent["Chrome"])
+	assert.Equal(t, int64(5), agg.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionUserAgent]["Chrome"])
+}
+
+func TestAggregateRolluper_Rollup_SkipsAlreadyAppliedBatch(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+
+	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	agg := models.NewEmptyWindowAggregateResult("customer123", windowStart, models.WindowMinute)
+	partial := &events.PartialInsightEvent{
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 2},
+				models.DimensionUserAgent: {"Chrome": 2},
+			},
+		},
+	}
+
+	err := rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"batch456"}, agg.AppliedBatchIDs)
+
+	// Redelivery of the same batch must not double count
+	err = rolluper.Rollup(agg, partial)
+	assert.ErrorIs(t, err, ErrBatchAlreadyApplied)
+	assert.Equal(t, int64(2), agg.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(2), agg.Dimensions[models.DimensionUserAgent]["Chrome"])
+
+	// Applied batch IDs stay sorted
+	partial.BatchID = "batch123"
+	err = rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"batch123", "batch456"}, agg.AppliedBatchIDs)
+	assert.Equal(t, int64(4), agg.Dimensions[models.DimensionPath]["GET /"])
+}
+
+func TestAggregateRolluper_Merge_AccumulatesFinerWindows(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+
+	hourStart := time.Date(2025, 12, 21, 14, 0, 0, 0, time.UTC)
+	agg := models.NewEmptyWindowAggregateResult("customer123", hourStart, models.WindowHour)
+
+	for _, minute := range []int{0, 21} {
+		source := models.NewEmptyWindowAggregateResult("customer123", hourStart.Add(time.Duration(minute)*time.Minute), models.WindowMinute)
+		source.Counts(models.DimensionPath)["GET /"] = 2
+		source.Counts(models.DimensionUserAgent)["Chrome"] = 2
+		source.MarkBatchApplied("batch-" + source.WindowStart.Format("1504"))
+
+		err := rolluper.Merge(agg, source)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]int64{"GET /": 4}, agg.Dimensions[models.DimensionPath])
+	assert.Equal(t, map[string]int64{"Chrome": 4}, agg.Dimensions[models.DimensionUserAgent])
+	assert.Empty(t, agg.AppliedBatchIDs, "applied batch IDs are not carried over")
+}
+
+func TestAggregateRolluper_Merge_ReturnsErrorOnMismatch(t *testing.T) {
+	t.Parallel()
+
+	hourStart := time.Date(2025, 12, 21, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		source *models.WindowAggregateResult
+	}{
+		{
+			name:   "customer mismatch",
+			source: models.NewEmptyWindowAggregateResult("customer456", hourStart, models.WindowMinute),
+		},
+		{
+			name:   "same window size",
+			source: models.NewEmptyWindowAggregateResult("customer123", hourStart, models.WindowHour),
+		},
+		{
+			name:   "source starts before agg",
+			source: models.NewEmptyWindowAggregateResult("customer123", hourStart.Add(-time.Minute), models.WindowMinute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+			agg := models.NewEmptyWindowAggregateResult("customer123", hourStart, models.WindowHour)
+			err := rolluper.Merge(agg, tt.source)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAggregateRolluper_Rollup_CapsKeysIntoOtherBucket(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{MaxPaths: 2, MaxUserAgents: 1})
+
+	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	agg := &models.WindowAggregateResult{
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 10, "GET /about": 4, models.OtherKey: 3},
+				models.DimensionUserAgent: {"Chrome": 14, models.OtherKey: 3},
+			},
+		},
+	}
+	partial := &events.PartialInsightEvent{
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /users/:id": 5, "GET /x": 1},
+				models.DimensionUserAgent: {"Chrome": 1, "Firefox": 5},
+			},
+		},
+	}
+
+	err := rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+
+	// The heavy hitters stay exact, the total is unchanged
+	assert.Equal(t, map[string]int64{"GET /": 10, "GET /users/:id": 5, models.OtherKey: 8}, agg.Dimensions[models.DimensionPath])
+	assert.Equal(t, map[string]int64{"Chrome": 15, models.OtherKey: 8}, agg.Dimensions[models.DimensionUserAgent])
+	// GET /users/:id may have been folded into the 3 requests of the window's other bucket before
+	assert.Equal(t, models.Dimensions{models.DimensionPath: {"GET /users/:id": 3}}, agg.CountErrors)
+}
+
+func TestAggregateRolluper_KeepsHeavyHitterSpreadAcrossBatches(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{MaxPaths: 2})
+	minuteStart := time.Date(2025, 12, 21, 14, 0, 0, 0, time.UTC)
+	partial := func(batchID string, paths map[string]int64) *events.PartialInsightEvent {
+		return &events.PartialInsightEvent{
+			CustomerID:       "customer123",
+			BatchID:          batchID,
+			WindowStart:      minuteStart,
+			WindowSize:       models.WindowMinute,
+			WindowAggregates: models.WindowAggregates{Dimensions: models.Dimensions{models.DimensionPath: paths}},
+		}
+	}
+
+	// One batch fills the window with two paths, then 30 batches bring one request of GET /hot each
+	minute := models.NewEmptyWindowAggregateResult("customer123", minuteStart, models.WindowMinute)
+	require.NoError(t, rolluper.Rollup(minute, partial("batch-0", map[string]int64{"GET /a": 10, "GET /b": 10})))
+	for i := 1; i <= 30; i++ {
+		require.NoError(t, rolluper.Rollup(minute, partial(fmt.Sprintf("batch-%d", i), map[string]int64{"GET /hot": 1})))
+	}
+
+	hot := minute.Dimensions[models.DimensionPath]["GET /hot"]
+	hotError := minute.CountErrors[models.DimensionPath]["GET /hot"]
+	require.Positive(t, hot)
+	assert.LessOrEqual(t, hot, int64(30))
+	assert.GreaterOrEqual(t, hot+hotError, int64(30))
+
+	// Rolled up with another minute holding GET /hot exactly, the bounds add up
+	hour := models.NewEmptyWindowAggregateResult("customer123", minuteStart, models.WindowHour)
+	require.NoError(t, rolluper.Merge(hour, minute))
+	next := models.NewEmptyWindowAggregateResult("customer123", minuteStart.Add(time.Minute), models.WindowMinute)
+	next.Counts(models.DimensionPath)["GET /hot"] = 5
+	require.NoError(t, rolluper.Merge(hour, next))
+
+	assert.Equal(t, hot+5, hour.Dimensions[models.DimensionPath]["GET /hot"])
+	assert.Equal(t, hotError, hour.CountErrors[models.DimensionPath]["GET /hot"])
+	assert.Equal(t, int64(55), sumCounts(hour.Dimensions[models.DimensionPath]))
+}
+
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+func TestAggregateRolluper_Rollup_MergesUserAgentDimensions(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+
+	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+
+	// Stored before the user agent dimensions existed
+	agg := &models.WindowAggregateResult{
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 1},
+				models.DimensionUserAgent: {"Chrome": 1},
+			},
+		},
+	}
+
+	partial := &events.PartialInsightEvent{
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:                {"GET /": 2},
+				models.DimensionUserAgent:           {"Chrome": 1, "Googlebot": 1},
+				models.DimensionOS:                  {"Windows": 1, models.UnknownKey: 1},
+				models.DimensionDeviceType:          {"desktop": 1, "bot": 1},
+				models.DimensionBrowserMajorVersion: {"Chrome 120": 1, "Googlebot 2": 1},
+				models.DimensionBotOrHuman:          {"bot": 1, "human": 1},
+			},
+		},
+	}
+
+	err := rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{"Chrome": 2, "Googlebot": 1}, agg.Dimensions[models.DimensionUserAgent])
+	assert.Equal(t, map[string]int64{"Windows": 1, models.UnknownKey: 1}, agg.Dimensions[models.DimensionOS])
+	assert.Equal(t, map[string]int64{"desktop": 1, "bot": 1}, agg.Dimensions[models.DimensionDeviceType])
+	assert.Equal(t, map[string]int64{"Chrome 120": 1, "Googlebot 2": 1}, agg.Dimensions[models.DimensionBrowserMajorVersion])
+	assert.Equal(t, map[string]int64{"bot": 1, "human": 1}, agg.Dimensions[models.DimensionBotOrHuman])
+}
+
+func TestAggregateRolluper_Merge_UniqueVisitorsAcrossWindows(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+
+	hourStart := time.Date(2025, 12, 21, 14, 0, 0, 0, time.UTC)
+	hour := models.NewEmptyWindowAggregateResult("customer123", hourStart, models.WindowHour)
+	for minute := range 3 {
+		source := models.NewEmptyWindowAggregateResult("customer123", hourStart.Add(time.Duration(minute)*time.Minute), models.WindowMinute)
+		source.Dimensions[models.DimensionPath] = map[string]int64{"GET /": 2}
+		source.UniqueVisitors = sketches.NewHyperLogLog()
+		source.UniqueVisitorsByPath = map[string]*sketches.HyperLogLog{"GET /": sketches.NewHyperLogLog()}
+		// The same visitor comes back every minute, one new visitor per minute
+		for _, visitor := range []string{"10.0.0.1", fmt.Sprintf("10.0.1.%d", minute)} {
+			source.UniqueVisitors.Add(visitor)
+			source.UniqueVisitorsByPath["GET /"].Add(visitor)
+		}
+		assert.NoError(t, rolluper.Merge(hour, source))
+	}
+
+	require.NotNil(t, hour.UniqueVisitors)
+	assert.Equal(t, int64(4), hour.UniqueVisitors.Estimate())
+	assert.Equal(t, int64(4), hour.UniqueVisitorsByPath["GET /"].Estimate())
+}
+
+func TestAggregateRolluper_Rollup_CapsPathUserAgentBreakdown(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{MaxPaths: 1, MaxUserAgents: 2, MaxBreakdownUserAgents: 1})
+
+	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	agg := &models.WindowAggregateResult{
+		CustomerID:  "customer123",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"POST /checkout": 3},
+				models.DimensionUserAgent: {"Chrome": 3},
+			},
+			RequestsByPathAndUserAgent: map[string]map[string]int64{"POST /checkout": {"Chrome": 3}},
+		},
+	}
+	partial := &events.PartialInsightEvent{
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"POST /checkout": 2, "GET /": 1},
+				models.DimensionUserAgent: {"Firefox": 2, "Chrome": 1},
+			},
+			RequestsByPathAndUserAgent: map[string]map[string]int64{"POST /checkout": {"Firefox": 2}, "GET /": {"Chrome": 1}},
+		},
+	}
+
+	err := rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+
+	// Rows follow the capped paths, each row keeps MaxUserAgents user agents
+	assert.Equal(t, map[string]int64{"POST /checkout": 5, models.OtherKey: 1}, agg.Dimensions[models.DimensionPath])
+	assert.Equal(t, map[string]map[string]int64{
+		"POST /checkout": {"Chrome": 3, models.OtherKey: 2},
+		models.OtherKey:  {"Chrome": 1},
+	}, agg.RequestsByPathAndUserAgent)
+}
+
+func TestAggregateRolluper_Merge_CustomDimensions(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{MaxDimensionKeys: 2})
+
+	hourStart := time.Date(2025, 12, 21, 14, 0, 0, 0, time.UTC)
+	// Stored before any custom dimension was registered
+	hour := models.NewEmptyWindowAggregateResult("customer123", hourStart, models.WindowHour)
+	for minute, host := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		source := models.NewEmptyWindowAggregateResult("customer123", hourStart.Add(time.Duration(minute)*time.Minute), models.WindowMinute)
+		source.Dimensions = models.Dimensions{"host": {host: int64(minute + 1), "a.example.com": 1}}
+		assert.NoError(t, rolluper.Merge(hour, source))
+	}
+
+	assert.Equal(t, models.Dimensions{
+		"host": {"a.example.com": 3, "c.example.com": 3, models.OtherKey: 2},
+	}, hour.Dimensions)
+}
+
+func TestAggregateRolluper_Rollup_MergesAttributes(t *testing.T) {
+	t.Parallel()
+
+	rolluper := NewAggregateRolluper(models.CardinalityLimits{})
+
+	windowStart := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	agg := models.NewEmptyWindowAggregateResult("customer123", windowStart, models.WindowMinute)
+	agg.Dimensions["attribute.region"] = map[string]int64{"eu-west-1": 2}
+	partial := &events.PartialInsightEvent{
+		CustomerID:  "customer123",
+		BatchID:     "batch456",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 3},
+				models.DimensionUserAgent: {"Chrome": 3},
+				"attribute.region":        {"eu-west-1": 1, "us-east-1": 1},
+				"attribute.tenantPlan":    {"pro": 1},
+			},
+		},
+	}
+
+	err := rolluper.Rollup(agg, partial)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{"eu-west-1": 3, "us-east-1": 1}, agg.Dimensions["attribute.region"])
+	assert.Equal(t, map[string]int64{"pro": 1}, agg.Dimensions["attribute.tenantPlan"])
 }
@@ -2,8 +2,11 @@ package aggregators
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"log-analytics/internal/events"
+	"log-analytics/internal/models"
 	"log-analytics/internal/shared/loggers"
 	"log-analytics/internal/shared/svcerrors"
 	"log-analytics/internal/stores"
@@ -17,33 +20,97 @@ type AggregationService interface {
 type aggregationService struct {
 	aggregateRolluper    WindowAggregateRolluper
 	aggregateResultStore stores.AggregateResultStore
+	watermarkTracker     WatermarkTracker
+	lateInsightStore     stores.LateInsightStore
+	timeZones            map[string]*time.Location
 }
 
-func NewAggregationService(aggregateRolluper WindowAggregateRolluper, aggregateResultStore stores.AggregateResultStore) AggregationService {
-	return &aggregationService{aggregateRolluper: aggregateRolluper, aggregateResultStore: aggregateResultStore}
+// NewAggregationService creates an AggregationService. timeZones holds the time zone of every customer with
+// a non-UTC one, see models.WindowSize.BucketID.
+func NewAggregationService(aggregateRolluper WindowAggregateRolluper, aggregateResultStore stores.AggregateResultStore, watermarkTracker WatermarkTracker, lateInsightStore stores.LateInsightStore, timeZones map[string]*time.Location) AggregationService {
+	return &aggregationService{
+		aggregateRolluper:    aggregateRolluper,
+		aggregateResultStore: aggregateResultStore,
+		watermarkTracker:     watermarkTracker,
+		lateInsightStore:     lateInsightStore,
+		timeZones:            timeZones,
+	}
 }
 
 func (s *aggregationService) Aggregate(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) *svcerrors.ServiceError {
 	logger := loggers.Ctx(ctx)
-	logger.Debug().Msg("started aggregating partial insight event for customer ID: " + partialInsightEvent.CustomerID + " and window start: " + partialInsightEvent.WindowSize.BucketID(partialInsightEvent.WindowStart))
-	aggregateResult, err := s.aggregateResultStore.Get(ctx, partialInsightEvent.CustomerID, partialInsightEvent.WindowStart, partialInsightEvent.WindowSize)
-	isNewAggregate := aggregateResult.IsNewAggregate()
+	bucketID := partialInsightEvent.WindowSize.BucketID(partialInsightEvent.WindowStart, s.timeZones[partialInsightEvent.CustomerID])
+	logger.Debug().Msg("started aggregating partial insight event for customer ID: " + partialInsightEvent.CustomerID + " and window start: " + bucketID)
+	watermark, err := s.watermarkTracker.Get(ctx, partialInsightEvent.CustomerID)
 	if err != nil {
-		return errInternalAggregateResultStoreFailed(err)
+		return errInternalWatermarkStoreFailed(err)
 	}
-	err = s.aggregateRolluper.Rollup(aggregateResult, partialInsightEvent)
-	if err != nil {
-		return errInternalAggregateRollupFailed(err)
+	// Windows without a result yet are finalized too once the finalizer moved past them
+	if watermark.IsWindowFinalized(partialInsightEvent.WindowSize, partialInsightEvent.WindowStart) {
+		return s.handleLateInsight(ctx, partialInsightEvent)
+	}
+
+	// The window is read, rolled up and written under its lock, so the finalizer cannot finalize it in between
+	var late, duplicate, isNewAggregate bool
+	var rollupErr error
+	_, err = s.aggregateResultStore.Update(ctx, partialInsightEvent.CustomerID, partialInsightEvent.WindowStart, partialInsightEvent.WindowSize,
+		func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+			if aggregateResult.Finalized {
+				late = true
+				return false, nil
+			}
+			isNewAggregate = aggregateResult.IsNewAggregate()
+			err := s.aggregateRolluper.Rollup(aggregateResult, partialInsightEvent)
+			if errors.Is(err, ErrBatchAlreadyApplied) {
+				duplicate = true
+				return false, nil
+			}
+			if err != nil {
+				rollupErr = err
+				return false, err
+			}
+			return true, nil
+		})
+	if rollupErr != nil {
+		return errInternalAggregateRollupFailed(rollupErr)
 	}
-	err = s.aggregateResultStore.Upsert(ctx, aggregateResult)
 	if err != nil {
 		return errInternalAggregateResultStoreFailed(err)
 	}
+	if late {
+		return s.handleLateInsight(ctx, partialInsightEvent)
+	}
+	if duplicate {
+		// Redelivered event (outbox retry or queue replay), the window already counts it
+		logger.Debug().Msg("skipped already applied batch " + partialInsightEvent.BatchID)
+		metricPartialInsightDuplicateSkippedTotal.WithLabelValues(bucketID).Inc()
+		return s.observe(ctx, partialInsightEvent)
+	}
 
 	if isNewAggregate {
-		bucketID := partialInsightEvent.WindowSize.BucketID(partialInsightEvent.WindowStart)
 		metricWindowAggregateCreatedTotal.WithLabelValues(bucketID).Inc()
 	}
 
+	return s.observe(ctx, partialInsightEvent)
+}
+
+// observe advances the customer's watermark once the partial insight is part of its window.
+func (s *aggregationService) observe(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) *svcerrors.ServiceError {
+	if err := s.watermarkTracker.Observe(ctx, partialInsightEvent.CustomerID, partialInsightEvent.MaxReceivedAt); err != nil {
+		return errInternalWatermarkStoreFailed(err)
+	}
+	return nil
+}
+
+// handleLateInsight is the late-data path: the partial insight of a finalized window is kept in the late
+// insight store for the reconciliation pass, the window itself is left unchanged.
+func (s *aggregationService) handleLateInsight(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) *svcerrors.ServiceError {
+	if err := s.lateInsightStore.Put(ctx, partialInsightEvent); err != nil {
+		return errInternalLateInsightStoreFailed(err)
+	}
+	loggers.Ctx(ctx).Warn().
+		Msgf("late partial insight of batch %s for finalized %s window %s of customer %s",
+			partialInsightEvent.BatchID, partialInsightEvent.WindowSize, partialInsightEvent.WindowStart.Format(time.RFC3339), partialInsightEvent.CustomerID)
+	metricLatePartialInsightTotal.WithLabelValues(string(partialInsightEvent.WindowSize)).Inc()
 	return nil
 }
@@ -0,0 +1,177 @@
+package aggregators_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAggregate_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	service := aggregators.NewAggregationService(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, watermarkTracker, storemocks.NewMockLateInsightStore(ctrl), nil)
+
+	ctx := context.Background()
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	event := &events.PartialInsightEvent{
+		CustomerID:    "cus-axon",
+		BatchID:       "batch-1",
+		WindowStart:   windowStart,
+		WindowSize:    models.WindowMinute,
+		MaxReceivedAt: windowStart.Add(30 * time.Second),
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 3},
+				models.DimensionUserAgent: {"Chrome": 3},
+			},
+		},
+	}
+
+	watermarkTracker.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+	written := false
+	aggregateResultStore.EXPECT().Update(ctx, "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(stored, &written))
+	watermarkTracker.EXPECT().Observe(ctx, "cus-axon", event.MaxReceivedAt).Return(nil)
+
+	svcErr := service.Aggregate(ctx, event)
+	assert.Nil(t, svcErr)
+	assert.True(t, written)
+	assert.Equal(t, int64(3), stored.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, []string{"batch-1"}, stored.AppliedBatchIDs)
+}
+
+func TestAggregate_SkipsAlreadyAppliedBatch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	service := aggregators.NewAggregationService(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, watermarkTracker, storemocks.NewMockLateInsightStore(ctrl), nil)
+
+	ctx := context.Background()
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	event := &events.PartialInsightEvent{
+		CustomerID:  "cus-axon",
+		BatchID:     "batch-1",
+		WindowStart: windowStart,
+		WindowSize:  models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 3},
+				models.DimensionUserAgent: {"Chrome": 3},
+			},
+		},
+	}
+
+	stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+	stored.Counts(models.DimensionPath)["GET /"] = 3
+	stored.Counts(models.DimensionUserAgent)["Chrome"] = 3
+	stored.AppliedBatchIDs = []string{"batch-1"}
+
+	watermarkTracker.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	written := false
+	aggregateResultStore.EXPECT().Update(ctx, "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(stored, &written))
+	watermarkTracker.EXPECT().Observe(ctx, "cus-axon", gomock.Any()).Return(nil)
+
+	svcErr := service.Aggregate(ctx, event)
+	assert.Nil(t, svcErr)
+	assert.False(t, written)
+	assert.Equal(t, int64(3), stored.Dimensions[models.DimensionPath]["GET /"])
+}
+
+func TestAggregate_RoutesLateInsights(t *testing.T) {
+	t.Parallel()
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		setupMocks func(aggregateResultStore *storemocks.MockAggregateResultStore, watermarkTracker *aggregatormocks.MockWatermarkTracker)
+	}{
+		{
+			name: "finalized by the finalizer without a result",
+			setupMocks: func(_ *storemocks.MockAggregateResultStore, watermarkTracker *aggregatormocks.MockWatermarkTracker) {
+				watermark := models.NewEmptyWatermark("cus-axon")
+				watermark.FinalizedUpTo[models.WindowMinute] = windowStart.Add(time.Minute)
+				watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(watermark, nil)
+			},
+		},
+		{
+			name: "finalized result",
+			setupMocks: func(aggregateResultStore *storemocks.MockAggregateResultStore, watermarkTracker *aggregatormocks.MockWatermarkTracker) {
+				watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+				stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+				stored.Finalized = true
+				aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+					DoAndReturn(updateOf(stored, nil))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+			lateInsightStore := storemocks.NewMockLateInsightStore(ctrl)
+			service := aggregators.NewAggregationService(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, watermarkTracker, lateInsightStore, nil)
+			tt.setupMocks(aggregateResultStore, watermarkTracker)
+
+			event := &events.PartialInsightEvent{
+				CustomerID:  "cus-axon",
+				BatchID:     "batch-late",
+				WindowStart: windowStart,
+				WindowSize:  models.WindowMinute,
+				WindowAggregates: models.WindowAggregates{
+					Dimensions: models.Dimensions{
+						models.DimensionPath:      {"GET /": 1},
+						models.DimensionUserAgent: {"Chrome": 1},
+					},
+				},
+			}
+			// The window is left unchanged and the watermark does not move, the event is kept for reconciliation
+			lateInsightStore.EXPECT().Put(gomock.Any(), event).Return(nil)
+			watermarkTracker.EXPECT().Observe(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			svcErr := service.Aggregate(context.Background(), event)
+			assert.Nil(t, svcErr)
+		})
+	}
+}
+
+// updateOf serves AggregateResultStore.Update from stored the way the store does, recording in written
+// whether the update asked for stored to be written.
+func updateOf(stored *models.WindowAggregateResult, written *bool) func(context.Context, string, time.Time, models.WindowSize, func(*models.WindowAggregateResult) (bool, error)) (*models.WindowAggregateResult, error) {
+	return func(_ context.Context, _ string, _ time.Time, _ models.WindowSize, update func(*models.WindowAggregateResult) (bool, error)) (*models.WindowAggregateResult, error) {
+		changed, err := update(stored)
+		if err != nil {
+			return nil, err
+		}
+		if written != nil {
+			*written = changed
+		}
+		return stored, nil
+	}
+}
@@ -7,16 +7,60 @@ import (
 )
 
 const (
-	codeInternalAggregateRollupFailed      = "AGG_9000"
-	codeInternalAggregateResultStoreFailed = "AGG_9001"
+	codeQueryValidationFailed = "AGG_1000"
+	codeInvalidTimeRange      = "AGG_1001"
+
+	codeInternalAggregateRollupFailed       = "AGG_9000"
+	codeInternalAggregateResultStoreFailed  = "AGG_9001"
+	codeInternalRollupCheckpointStoreFailed = "AGG_9002"
+	codeInternalWatermarkStoreFailed        = "AGG_9003"
+	codeInternalWindowFinalizedHookFailed   = "AGG_9004"
+	codeInternalLateInsightStoreFailed      = "AGG_9005"
+	codeInternalWindowCorrectionStoreFailed = "AGG_9006"
 )
 
-// errInternalAggregateRollupFailed returns an error when a aggregate rollup fails.
+// errQueryValidationFailed returns an error when an aggregate query parameter is invalid.
+func errQueryValidationFailed(msg string, cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeQueryValidationFailed, msg, cause)
+}
+
+// errInvalidTimeRange returns an error when the requested time range of an aggregate query is invalid.
+func errInvalidTimeRange(msg string) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeInvalidTimeRange, msg, nil)
+}
+
+// errInternalAggregateRollupFailed returns an error when a aggregate rollup fails. The partial insight does
+// not match its window, so the error is permanent.
 func errInternalAggregateRollupFailed(cause error) *svcerrors.ServiceError {
-	return svcerrors.NewInternalError(codeInternalAggregateRollupFailed, fmt.Errorf("aggregateRollupFailed: %w", cause))
+	return svcerrors.NewPermanentInternalError(codeInternalAggregateRollupFailed, fmt.Errorf("aggregateRollupFailed: %w", cause))
 }
 
 // errInternalAggregateResultStoreFailed returns an error when a aggregate result store operation fails.
 func errInternalAggregateResultStoreFailed(cause error) *svcerrors.ServiceError {
 	return svcerrors.NewInternalError(codeInternalAggregateResultStoreFailed, fmt.Errorf("aggregateResultStoreFailed: %w", cause))
 }
+
+// errInternalRollupCheckpointStoreFailed returns an error when a rollup checkpoint store operation fails.
+func errInternalRollupCheckpointStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalRollupCheckpointStoreFailed, fmt.Errorf("rollupCheckpointStoreFailed: %w", cause))
+}
+
+// errInternalWatermarkStoreFailed returns an error when a watermark store operation fails.
+func errInternalWatermarkStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalWatermarkStoreFailed, fmt.Errorf("watermarkStoreFailed: %w", cause))
+}
+
+// errInternalWindowFinalizedHookFailed returns an error when a window finalized hook fails.
+func errInternalWindowFinalizedHookFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalWindowFinalizedHookFailed, fmt.Errorf("windowFinalizedHookFailed: %w", cause))
+}
+
+// errInternalLateInsightStoreFailed returns an error when a late insight store operation fails.
+func errInternalLateInsightStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalLateInsightStoreFailed, fmt.Errorf("lateInsightStoreFailed: %w", cause))
+}
+
+// errInternalWindowCorrectionStoreFailed returns an error when a window correction store operation fails.
+func errInternalWindowCorrectionStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalWindowCorrectionStoreFailed, fmt.Errorf("windowCorrectionStoreFailed: %w", cause))
+}
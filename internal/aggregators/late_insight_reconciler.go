@@ -0,0 +1,180 @@
+package aggregators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/shared/ulid"
+	"log-analytics/internal/stores"
+)
+
+// LateInsightReconciler applies the late partial insights kept by the late-data path to their finalized
+// windows as versioned corrections. Every corrected window gets its revision bumped and an audit record of
+// the added counts, so downstream consumers can detect and explain restatements. Corrected windows of the rollup
+// source window size requeue the target windows rolled up from them, so the rollup job rebuilds those too.
+//
+//go:generate mockgen -source=late_insight_reconciler.go -destination=./mocks/late_insight_reconciler_mock.go -package=mocks
+type LateInsightReconciler interface {
+	// Reconcile runs a single reconciliation pass over every stored late insight and returns the
+	// corrections it applied.
+	Reconcile(ctx context.Context) ([]*models.WindowCorrection, error)
+}
+
+type lateInsightReconciler struct {
+	aggregateRolluper      WindowAggregateRolluper
+	aggregateResultStore   stores.AggregateResultStore
+	lateInsightStore       stores.LateInsightStore
+	windowCorrectionStore  stores.WindowCorrectionStore
+	rollupCheckpointStore  stores.RollupCheckpointStore
+	rollupSourceWindowSize models.WindowSize
+	rollupWindowSizes      []models.WindowSize
+	timeZones              map[string]*time.Location
+}
+
+// NewLateInsightReconciler creates a LateInsightReconciler. rollupSourceWindowSize and rollupWindowSizes are the
+// source and target window sizes of the rollup job (see NewRollupJob); timeZones holds the time zone day and
+// week target windows of a customer are aligned to.
+func NewLateInsightReconciler(aggregateRolluper WindowAggregateRolluper, aggregateResultStore stores.AggregateResultStore, lateInsightStore stores.LateInsightStore, windowCorrectionStore stores.WindowCorrectionStore, rollupCheckpointStore stores.RollupCheckpointStore, rollupSourceWindowSize models.WindowSize, rollupWindowSizes []models.WindowSize, timeZones map[string]*time.Location) LateInsightReconciler {
+	return &lateInsightReconciler{
+		aggregateRolluper:      aggregateRolluper,
+		aggregateResultStore:   aggregateResultStore,
+		lateInsightStore:       lateInsightStore,
+		windowCorrectionStore:  windowCorrectionStore,
+		rollupCheckpointStore:  rollupCheckpointStore,
+		rollupSourceWindowSize: rollupSourceWindowSize,
+		rollupWindowSizes:      rollupWindowSizes,
+		timeZones:              timeZones,
+	}
+}
+
+func (r *lateInsightReconciler) Reconcile(ctx context.Context) ([]*models.WindowCorrection, error) {
+	lateInsights, err := r.lateInsightStore.List(ctx)
+	if err != nil {
+		return nil, errInternalLateInsightStoreFailed(err)
+	}
+
+	windowCorrections := make([]*models.WindowCorrection, 0)
+	var errs []error
+	// Late insights are listed ordered by window, so each window is corrected once per pass
+	for start := 0; start < len(lateInsights); {
+		end := start + 1
+		for end < len(lateInsights) && isSameWindow(lateInsights[start], lateInsights[end]) {
+			end++
+		}
+		if ctx.Err() != nil {
+			return windowCorrections, ctx.Err()
+		}
+
+		windowCorrection, err := r.correct(ctx, lateInsights[start:end])
+		if err != nil {
+			loggers.Ctx(ctx).Error().Err(err).
+				Msgf("failed to correct %s window %s for customer %s", lateInsights[start].WindowSize,
+					lateInsights[start].WindowStart.Format(time.RFC3339), lateInsights[start].CustomerID)
+			errs = append(errs, err)
+		} else if windowCorrection != nil {
+			windowCorrections = append(windowCorrections, windowCorrection)
+		}
+		start = end
+	}
+	return windowCorrections, errors.Join(errs...)
+}
+
+// correct applies the late insights of one window. The audit record is written before the window, the rollup
+// target windows are requeued after it and the late insights are deleted last: a pass interrupted in between is
+// completed by the next one, which skips the batches the window already applied.
+func (r *lateInsightReconciler) correct(ctx context.Context, lateInsights []*events.PartialInsightEvent) (*models.WindowCorrection, error) {
+	first := lateInsights[0]
+	var windowCorrection *models.WindowCorrection
+	var correctErr error
+	// Corrected under the window's lock so a concurrent finalization or Aggregate is not overwritten
+	_, err := r.aggregateResultStore.Update(ctx, first.CustomerID, first.WindowStart, first.WindowSize,
+		func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+			windowCorrection = models.NewWindowCorrection(aggregateResult)
+			windowCorrection.Revision++
+			for _, lateInsight := range lateInsights {
+				err := r.aggregateRolluper.Rollup(aggregateResult, lateInsight)
+				if errors.Is(err, ErrBatchAlreadyApplied) {
+					continue
+				}
+				if err != nil {
+					correctErr = errInternalAggregateRollupFailed(fmt.Errorf("batch %s: %w", lateInsight.BatchID, err))
+					return false, correctErr
+				}
+				windowCorrection.BatchIDs = append(windowCorrection.BatchIDs, lateInsight.BatchID)
+				windowCorrection.Add(lateInsight.WindowAggregates)
+			}
+			if len(windowCorrection.BatchIDs) == 0 {
+				return false, nil
+			}
+
+			now := time.Now().UTC()
+			windowCorrection.CorrectedAt = now
+			if err := r.windowCorrectionStore.Put(ctx, windowCorrection); err != nil {
+				correctErr = errInternalWindowCorrectionStoreFailed(err)
+				return false, correctErr
+			}
+
+			aggregateResult.Revision = windowCorrection.Revision
+			// A window finalized before it had a result is created by its first correction
+			if !aggregateResult.Finalized {
+				aggregateResult.Finalized = true
+				aggregateResult.FinalizedAt = now
+			}
+			return true, nil
+		})
+	if correctErr != nil {
+		return nil, correctErr
+	}
+	if err != nil {
+		return nil, errInternalAggregateResultStoreFailed(err)
+	}
+	applied := len(windowCorrection.BatchIDs) > 0
+	if applied {
+		metricWindowCorrectedTotal.WithLabelValues(string(first.WindowSize)).Inc()
+	}
+	// Requeued even when nothing was applied, the window may have been corrected by an interrupted pass
+	if err := r.requeueRollups(ctx, first); err != nil {
+		return nil, err
+	}
+
+	for _, lateInsight := range lateInsights {
+		if err := r.lateInsightStore.Delete(ctx, lateInsight); err != nil {
+			return nil, errInternalLateInsightStoreFailed(err)
+		}
+	}
+	if !applied {
+		return nil, nil
+	}
+	return windowCorrection, nil
+}
+
+// requeueRollups requeues the rollup target windows containing the window of lateInsight, when it is a window
+// of the rollup source window size.
+func (r *lateInsightReconciler) requeueRollups(ctx context.Context, lateInsight *events.PartialInsightEvent) error {
+	if lateInsight.WindowSize != r.rollupSourceWindowSize {
+		return nil
+	}
+	loc := r.timeZones[lateInsight.CustomerID]
+	for _, rollupWindowSize := range r.rollupWindowSizes {
+		requeue := &models.RollupRequeue{
+			ID:          ulid.NewULID(),
+			CustomerID:  lateInsight.CustomerID,
+			WindowSize:  rollupWindowSize,
+			WindowStart: rollupWindowSize.Truncate(lateInsight.WindowStart, loc),
+			RequeuedAt:  time.Now().UTC(),
+		}
+		if err := r.rollupCheckpointStore.Requeue(ctx, requeue); err != nil {
+			return errInternalRollupCheckpointStoreFailed(err)
+		}
+	}
+	return nil
+}
+
+func isSameWindow(a *events.PartialInsightEvent, b *events.PartialInsightEvent) bool {
+	return a.CustomerID == b.CustomerID && a.WindowSize == b.WindowSize && a.WindowStart.Equal(b.WindowStart)
+}
@@ -0,0 +1,180 @@
+package aggregators_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/sketches"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcile_AppliesLateInsightsAsCorrection(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	lateInsightStore := storemocks.NewMockLateInsightStore(ctrl)
+	windowCorrectionStore := storemocks.NewMockWindowCorrectionStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	reconciler := aggregators.NewLateInsightReconciler(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, lateInsightStore, windowCorrectionStore,
+		rollupCheckpointStore, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil)
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	lateInsight := func(batchID string, path string) *events.PartialInsightEvent {
+		// One visitor per batch
+		visitors := sketches.NewHyperLogLog()
+		visitors.Add(batchID)
+		return &events.PartialInsightEvent{
+			CustomerID:  "cus-axon",
+			BatchID:     batchID,
+			WindowStart: windowStart,
+			WindowSize:  models.WindowMinute,
+			WindowAggregates: models.WindowAggregates{
+				Dimensions: models.Dimensions{
+					models.DimensionPath:      {path: 2},
+					models.DimensionUserAgent: {"Chrome": 2},
+				},
+				UniqueVisitors:       visitors,
+				UniqueVisitorsByPath: map[string]*sketches.HyperLogLog{path: visitors},
+			},
+		}
+	}
+	// batch-0 was applied already by an interrupted pass
+	lateInsights := []*events.PartialInsightEvent{lateInsight("batch-0", "GET /"), lateInsight("batch-1", "GET /"), lateInsight("batch-2", "GET /about")}
+
+	stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+	stored.Counts(models.DimensionPath)["GET /"] = 5
+	stored.Counts(models.DimensionUserAgent)["Chrome"] = 5
+	stored.AppliedBatchIDs = []string{"batch-0"}
+	stored.Finalized = true
+	stored.Revision = 1
+
+	lateInsightStore.EXPECT().List(gomock.Any()).Return(lateInsights, nil)
+	written := false
+	gomock.InOrder(
+		aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+			DoAndReturn(updateOf(stored, &written)),
+		windowCorrectionStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+			Do(func(ctx context.Context, windowCorrection *models.WindowCorrection) {
+				assert.Equal(t, 2, windowCorrection.Revision)
+				assert.Equal(t, []string{"batch-1", "batch-2"}, windowCorrection.BatchIDs)
+				assert.Equal(t, map[string]int64{"GET /": 2, "GET /about": 2}, windowCorrection.Dimensions[models.DimensionPath])
+				assert.Equal(t, map[string]int64{"Chrome": 4}, windowCorrection.Dimensions[models.DimensionUserAgent])
+				require.NotNil(t, windowCorrection.UniqueVisitors)
+				assert.Equal(t, int64(2), windowCorrection.UniqueVisitors.Estimate())
+				assert.Equal(t, int64(1), windowCorrection.UniqueVisitorsByPath["GET /about"].Estimate())
+			}).
+			Return(nil),
+		// The hour rolled up from the corrected minute is rebuilt by the rollup job
+		rollupCheckpointStore.EXPECT().Requeue(gomock.Any(), gomock.Any()).
+			Do(func(ctx context.Context, requeue *models.RollupRequeue) {
+				assert.Equal(t, "cus-axon", requeue.CustomerID)
+				assert.Equal(t, models.WindowHour, requeue.WindowSize)
+				assert.True(t, windowStart.Truncate(time.Hour).Equal(requeue.WindowStart))
+				assert.NotEmpty(t, requeue.ID)
+			}).
+			Return(nil),
+		lateInsightStore.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(3),
+	)
+
+	windowCorrections, err := reconciler.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.Len(t, windowCorrections, 1)
+	assert.Equal(t, 2, windowCorrections[0].Revision)
+	assert.True(t, written)
+	assert.Equal(t, 2, stored.Revision)
+	assert.Equal(t, int64(7), stored.Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(9), stored.Dimensions[models.DimensionUserAgent]["Chrome"])
+	assert.Equal(t, []string{"batch-0", "batch-1", "batch-2"}, stored.AppliedBatchIDs)
+}
+
+func TestReconcile_OnlyAlreadyAppliedBatches(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	lateInsightStore := storemocks.NewMockLateInsightStore(ctrl)
+	windowCorrectionStore := storemocks.NewMockWindowCorrectionStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	reconciler := aggregators.NewLateInsightReconciler(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, lateInsightStore, windowCorrectionStore,
+		rollupCheckpointStore, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil)
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	lateInsight := &events.PartialInsightEvent{CustomerID: "cus-axon", BatchID: "batch-0", WindowStart: windowStart, WindowSize: models.WindowMinute}
+	stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+	stored.AppliedBatchIDs = []string{"batch-0"}
+
+	lateInsightStore.EXPECT().List(gomock.Any()).Return([]*events.PartialInsightEvent{lateInsight}, nil)
+	written := false
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(stored, &written))
+	windowCorrectionStore.EXPECT().Put(gomock.Any(), gomock.Any()).Times(0)
+	// An interrupted pass may have corrected the window without requeueing its rollups
+	rollupCheckpointStore.EXPECT().Requeue(gomock.Any(), gomock.Any()).Return(nil)
+	lateInsightStore.EXPECT().Delete(gomock.Any(), lateInsight).Return(nil)
+
+	windowCorrections, err := reconciler.Reconcile(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, windowCorrections)
+	assert.False(t, written)
+}
+
+func TestReconcile_KeepsLateInsightsOnFailure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	lateInsightStore := storemocks.NewMockLateInsightStore(ctrl)
+	windowCorrectionStore := storemocks.NewMockWindowCorrectionStore(ctrl)
+	reconciler := aggregators.NewLateInsightReconciler(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, lateInsightStore, windowCorrectionStore,
+		storemocks.NewMockRollupCheckpointStore(ctrl), models.WindowMinute, nil, nil)
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	failing := &events.PartialInsightEvent{CustomerID: "cus-axon", BatchID: "batch-1", WindowStart: windowStart, WindowSize: models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {"GET /": 1},
+			},
+		},
+	}
+	other := &events.PartialInsightEvent{CustomerID: "cus-bolt", BatchID: "batch-2", WindowStart: windowStart, WindowSize: models.WindowMinute,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {"GET /": 1},
+			},
+		},
+	}
+
+	lateInsightStore.EXPECT().List(gomock.Any()).Return([]*events.PartialInsightEvent{failing, other}, nil)
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		Return(nil, errors.New("storage error"))
+	// Other windows are still corrected
+	stored := models.NewEmptyWindowAggregateResult("cus-bolt", windowStart, models.WindowMinute)
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-bolt", windowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(stored, nil))
+	windowCorrectionStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil)
+	lateInsightStore.EXPECT().Delete(gomock.Any(), other).Return(nil)
+
+	windowCorrections, err := reconciler.Reconcile(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AGG_9001")
+	assert.Len(t, windowCorrections, 1)
+	// Finalized without a result before, so the correction creates it
+	assert.True(t, stored.Finalized)
+	assert.Equal(t, 1, stored.Revision)
+}
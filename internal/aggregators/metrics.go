@@ -30,4 +30,71 @@ var (
 		},
 		[]string{"bucket_id"},
 	)
+
+	// metricPartialInsightDuplicateSkippedTotal counts partial insight events skipped because their batch
+	// was already rolled up into the window aggregate (at-least-once redelivery). The bucket_id label
+	// follows metricWindowAggregateCreatedTotal.
+	metricPartialInsightDuplicateSkippedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "partial_insight_duplicate_skipped_total",
+		},
+		[]string{"bucket_id"},
+	)
+
+	// metricWindowRolledUpTotal counts the windows built by the rollup job, by target window size
+	// (e.g. "hour", "day").
+	metricWindowRolledUpTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "window_rolled_up_total",
+		},
+		[]string{"window_size"},
+	)
+
+	// metricWindowFinalizedTotal counts the windows finalized once the watermark passed their end, by window size.
+	metricWindowFinalizedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "window_finalized_total",
+		},
+		[]string{"window_size"},
+	)
+
+	// metricLatePartialInsightTotal counts partial insight events that arrived for an already finalized
+	// window and were routed to the late-data path instead of updating it, by window size.
+	metricLatePartialInsightTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "late_partial_insight_total",
+		},
+		[]string{"window_size"},
+	)
+
+	// metricWindowCorrectedTotal counts the corrections applied to finalized windows by the reconciliation
+	// pass, by window size.
+	metricWindowCorrectedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "window_corrected_total",
+		},
+		[]string{"window_size"},
+	)
+
+	// metricWindowKeysCappedTotal counts the merges after which a window aggregate exceeded its cardinality
+	// limit and had its least requested keys folded into the "__other__" bucket, by dimension ("path",
+	// "user_agent").
+	metricWindowKeysCappedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubAggregation,
+			Name:      "window_keys_capped_total",
+		},
+		[]string{"dimension"},
+	)
 )
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: aggregate_query_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=aggregate_query_service.go -destination=./mocks/aggregate_query_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	aggregators "log-analytics/internal/aggregators"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAggregateQueryService is a mock of AggregateQueryService interface.
+type MockAggregateQueryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAggregateQueryServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAggregateQueryServiceMockRecorder is the mock recorder for MockAggregateQueryService.
+type MockAggregateQueryServiceMockRecorder struct {
+	mock *MockAggregateQueryService
+}
+
+// NewMockAggregateQueryService creates a new mock instance.
+func NewMockAggregateQueryService(ctrl *gomock.Controller) *MockAggregateQueryService {
+	mock := &MockAggregateQueryService{ctrl: ctrl}
+	mock.recorder = &MockAggregateQueryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAggregateQueryService) EXPECT() *MockAggregateQueryServiceMockRecorder {
+	return m.recorder
+}
+
+// QueryAggregates mocks base method.
+func (m *MockAggregateQueryService) QueryAggregates(ctx context.Context, query aggregators.AggregateQuery) (*aggregators.AggregatePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryAggregates", ctx, query)
+	ret0, _ := ret[0].(*aggregators.AggregatePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryAggregates indicates an expected call of QueryAggregates.
+func (mr *MockAggregateQueryServiceMockRecorder) QueryAggregates(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryAggregates", reflect.TypeOf((*MockAggregateQueryService)(nil).QueryAggregates), ctx, query)
+}
+
+// QueryBreakdown mocks base method.
+func (m *MockAggregateQueryService) QueryBreakdown(ctx context.Context, query aggregators.BreakdownQuery) (*aggregators.BreakdownReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBreakdown", ctx, query)
+	ret0, _ := ret[0].(*aggregators.BreakdownReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBreakdown indicates an expected call of QueryBreakdown.
+func (mr *MockAggregateQueryServiceMockRecorder) QueryBreakdown(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBreakdown", reflect.TypeOf((*MockAggregateQueryService)(nil).QueryBreakdown), ctx, query)
+}
+
+// QueryLatency mocks base method.
+func (m *MockAggregateQueryService) QueryLatency(ctx context.Context, query aggregators.LatencyQuery) (*aggregators.LatencyReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryLatency", ctx, query)
+	ret0, _ := ret[0].(*aggregators.LatencyReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryLatency indicates an expected call of QueryLatency.
+func (mr *MockAggregateQueryServiceMockRecorder) QueryLatency(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLatency", reflect.TypeOf((*MockAggregateQueryService)(nil).QueryLatency), ctx, query)
+}
@@ -41,6 +41,20 @@ func (m *MockWindowAggregateRolluper) EXPECT() *MockWindowAggregateRolluperMockR
 	return m.recorder
 }
 
+// Merge mocks base method.
+func (m *MockWindowAggregateRolluper) Merge(agg, source *models.WindowAggregateResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", agg, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockWindowAggregateRolluperMockRecorder) Merge(agg, source any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockWindowAggregateRolluper)(nil).Merge), agg, source)
+}
+
 // Rollup mocks base method.
 func (m *MockWindowAggregateRolluper) Rollup(agg *models.WindowAggregateResult, partial *events.PartialInsightEvent) error {
 	m.ctrl.T.Helper()
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	events "log-analytics/internal/events"
+	svcerrors "log-analytics/internal/shared/svcerrors"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -42,10 +43,10 @@ func (m *MockAggregationService) EXPECT() *MockAggregationServiceMockRecorder {
 }
 
 // Aggregate mocks base method.
-func (m *MockAggregationService) Aggregate(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error {
+func (m *MockAggregationService) Aggregate(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) *svcerrors.ServiceError {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Aggregate", ctx, partialInsightEvent)
-	ret0, _ := ret[0].(error)
+	ret0, _ := ret[0].(*svcerrors.ServiceError)
 	return ret0
 }
 
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: late_insight_reconciler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=late_insight_reconciler.go -destination=./mocks/late_insight_reconciler_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLateInsightReconciler is a mock of LateInsightReconciler interface.
+type MockLateInsightReconciler struct {
+	ctrl     *gomock.Controller
+	recorder *MockLateInsightReconcilerMockRecorder
+	isgomock struct{}
+}
+
+// MockLateInsightReconcilerMockRecorder is the mock recorder for MockLateInsightReconciler.
+type MockLateInsightReconcilerMockRecorder struct {
+	mock *MockLateInsightReconciler
+}
+
+// NewMockLateInsightReconciler creates a new mock instance.
+func NewMockLateInsightReconciler(ctrl *gomock.Controller) *MockLateInsightReconciler {
+	mock := &MockLateInsightReconciler{ctrl: ctrl}
+	mock.recorder = &MockLateInsightReconcilerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLateInsightReconciler) EXPECT() *MockLateInsightReconcilerMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method.
+func (m *MockLateInsightReconciler) Reconcile(ctx context.Context) ([]*models.WindowCorrection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].([]*models.WindowCorrection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockLateInsightReconcilerMockRecorder) Reconcile(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockLateInsightReconciler)(nil).Reconcile), ctx)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rollup_job.go
+//
+// Generated by this command:
+//
+//	mockgen -source=rollup_job.go -destination=./mocks/rollup_job_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRollupJob is a mock of RollupJob interface.
+type MockRollupJob struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollupJobMockRecorder
+	isgomock struct{}
+}
+
+// MockRollupJobMockRecorder is the mock recorder for MockRollupJob.
+type MockRollupJobMockRecorder struct {
+	mock *MockRollupJob
+}
+
+// NewMockRollupJob creates a new mock instance.
+func NewMockRollupJob(ctrl *gomock.Controller) *MockRollupJob {
+	mock := &MockRollupJob{ctrl: ctrl}
+	mock.recorder = &MockRollupJobMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRollupJob) EXPECT() *MockRollupJobMockRecorder {
+	return m.recorder
+}
+
+// RollupFinalized mocks base method.
+func (m *MockRollupJob) RollupFinalized(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollupFinalized", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollupFinalized indicates an expected call of RollupFinalized.
+func (mr *MockRollupJobMockRecorder) RollupFinalized(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollupFinalized", reflect.TypeOf((*MockRollupJob)(nil).RollupFinalized), ctx)
+}
+
+// Start mocks base method.
+func (m *MockRollupJob) Start(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start", ctx)
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockRollupJobMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockRollupJob)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockRollupJob) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockRollupJobMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockRollupJob)(nil).Stop))
+}
+
+// WindowFinalized mocks base method.
+func (m *MockRollupJob) WindowFinalized(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WindowFinalized", ctx, aggregateResult)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WindowFinalized indicates an expected call of WindowFinalized.
+func (mr *MockRollupJobMockRecorder) WindowFinalized(ctx, aggregateResult any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WindowFinalized", reflect.TypeOf((*MockRollupJob)(nil).WindowFinalized), ctx, aggregateResult)
+}
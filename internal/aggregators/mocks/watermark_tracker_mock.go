@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: watermark_tracker.go
+//
+// Generated by this command:
+//
+//	mockgen -source=watermark_tracker.go -destination=./mocks/watermark_tracker_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWatermarkTracker is a mock of WatermarkTracker interface.
+type MockWatermarkTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatermarkTrackerMockRecorder
+	isgomock struct{}
+}
+
+// MockWatermarkTrackerMockRecorder is the mock recorder for MockWatermarkTracker.
+type MockWatermarkTrackerMockRecorder struct {
+	mock *MockWatermarkTracker
+}
+
+// NewMockWatermarkTracker creates a new mock instance.
+func NewMockWatermarkTracker(ctrl *gomock.Controller) *MockWatermarkTracker {
+	mock := &MockWatermarkTracker{ctrl: ctrl}
+	mock.recorder = &MockWatermarkTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatermarkTracker) EXPECT() *MockWatermarkTrackerMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockWatermarkTracker) Get(ctx context.Context, customerID string) (*models.Watermark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, customerID)
+	ret0, _ := ret[0].(*models.Watermark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWatermarkTrackerMockRecorder) Get(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWatermarkTracker)(nil).Get), ctx, customerID)
+}
+
+// MarkFinalized mocks base method.
+func (m *MockWatermarkTracker) MarkFinalized(ctx context.Context, customerID string, windowSize models.WindowSize, upTo time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFinalized", ctx, customerID, windowSize, upTo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFinalized indicates an expected call of MarkFinalized.
+func (mr *MockWatermarkTrackerMockRecorder) MarkFinalized(ctx, customerID, windowSize, upTo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFinalized", reflect.TypeOf((*MockWatermarkTracker)(nil).MarkFinalized), ctx, customerID, windowSize, upTo)
+}
+
+// Observe mocks base method.
+func (m *MockWatermarkTracker) Observe(ctx context.Context, customerID string, receivedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Observe", ctx, customerID, receivedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Observe indicates an expected call of Observe.
+func (mr *MockWatermarkTrackerMockRecorder) Observe(ctx, customerID, receivedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Observe", reflect.TypeOf((*MockWatermarkTracker)(nil).Observe), ctx, customerID, receivedAt)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: window_finalizer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=window_finalizer.go -destination=./mocks/window_finalizer_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWindowFinalizer is a mock of WindowFinalizer interface.
+type MockWindowFinalizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockWindowFinalizerMockRecorder
+	isgomock struct{}
+}
+
+// MockWindowFinalizerMockRecorder is the mock recorder for MockWindowFinalizer.
+type MockWindowFinalizerMockRecorder struct {
+	mock *MockWindowFinalizer
+}
+
+// NewMockWindowFinalizer creates a new mock instance.
+func NewMockWindowFinalizer(ctrl *gomock.Controller) *MockWindowFinalizer {
+	mock := &MockWindowFinalizer{ctrl: ctrl}
+	mock.recorder = &MockWindowFinalizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWindowFinalizer) EXPECT() *MockWindowFinalizerMockRecorder {
+	return m.recorder
+}
+
+// FinalizeWindows mocks base method.
+func (m *MockWindowFinalizer) FinalizeWindows(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinalizeWindows", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinalizeWindows indicates an expected call of FinalizeWindows.
+func (mr *MockWindowFinalizerMockRecorder) FinalizeWindows(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinalizeWindows", reflect.TypeOf((*MockWindowFinalizer)(nil).FinalizeWindows), ctx)
+}
+
+// Start mocks base method.
+func (m *MockWindowFinalizer) Start(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start", ctx)
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockWindowFinalizerMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockWindowFinalizer)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockWindowFinalizer) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockWindowFinalizerMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockWindowFinalizer)(nil).Stop))
+}
@@ -0,0 +1,263 @@
+package aggregators
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/shared/ulid"
+	"log-analytics/internal/stores"
+)
+
+// RollupJob periodically rolls the aggregate results of the source window size up into coarser window
+// sizes, e.g. minute results into hour and day results, so long ranges are answered from a few results.
+//
+// A target window is rolled up once all of its source windows are finalized, i.e. end at or before the
+// FinalizedUpTo of the source window size in the customer's watermark: it is rebuilt from its sources, stored, and the checkpoint
+// of the customer and target size is advanced past it, so a restarted job resumes where it stopped. Partial
+// insights for a finalized source take the late-data path instead of changing it, so a rolled up window only
+// goes stale through a late insight correction, which requeues it in the RollupCheckpointStore.
+//
+// Registered as a WindowFinalizedHook, the job runs a pass as soon as source windows are finalized instead of
+// waiting for the next tick. Hooks run before the finalizer records its progress in the watermark, so the pass
+// also treats every source window up to the end of the latest one reported to the hook as finalized.
+//
+//go:generate mockgen -source=rollup_job.go -destination=./mocks/rollup_job_mock.go -package=mocks
+type RollupJob interface {
+	Start(ctx context.Context)
+	Stop()
+	// RollupFinalized runs a single rollup pass over every customer and target window size.
+	RollupFinalized(ctx context.Context) error
+	// WindowFinalized is a WindowFinalizedHook triggering a rollup pass once a source window is finalized.
+	WindowFinalized(ctx context.Context, aggregateResult *models.WindowAggregateResult) error
+}
+
+type rollupJob struct {
+	aggregateRolluper     WindowAggregateRolluper
+	aggregateResultStore  stores.AggregateResultStore
+	rollupCheckpointStore stores.RollupCheckpointStore
+	watermarkTracker      WatermarkTracker
+	sourceWindowSize      models.WindowSize
+	targetWindowSizes     []models.WindowSize
+	timeZones             map[string]*time.Location
+	interval              time.Duration
+
+	mu            sync.Mutex
+	finalizedUpTo map[string]time.Time // end of the latest source window reported finalized, by customer
+	triggerCh     chan struct{}
+
+	wg sync.WaitGroup
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+
+	logger loggers.Logger
+}
+
+// NewRollupJob creates a RollupJob. Every target window size must be a multiple of sourceWindowSize; timeZones
+// holds the time zone day and week target windows of a customer are aligned to.
+func NewRollupJob(aggregateRolluper WindowAggregateRolluper, aggregateResultStore stores.AggregateResultStore, rollupCheckpointStore stores.RollupCheckpointStore, watermarkTracker WatermarkTracker, sourceWindowSize models.WindowSize, targetWindowSizes []models.WindowSize, timeZones map[string]*time.Location, interval time.Duration, logger loggers.Logger) RollupJob {
+	return &rollupJob{
+		aggregateRolluper:     aggregateRolluper,
+		aggregateResultStore:  aggregateResultStore,
+		rollupCheckpointStore: rollupCheckpointStore,
+		watermarkTracker:      watermarkTracker,
+		sourceWindowSize:      sourceWindowSize,
+		targetWindowSizes:     targetWindowSizes,
+		timeZones:             timeZones,
+		interval:              interval,
+		finalizedUpTo:         make(map[string]time.Time),
+		triggerCh:             make(chan struct{}, 1),
+		stopCh:                make(chan struct{}),
+		logger:                logger,
+	}
+}
+
+// Start spawns the rollup goroutine, which runs a rollup pass on every tick of the interval and whenever a
+// source window is finalized.
+func (job *rollupJob) Start(ctx context.Context) {
+	job.wg.Add(1)
+	go func() {
+		defer job.wg.Done()
+
+		ticker := time.NewTicker(job.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-job.stopCh:
+				return
+			case <-ticker.C:
+				job.runPass(ctx)
+			case <-job.triggerCh:
+				job.runPass(ctx)
+			}
+		}
+	}()
+}
+
+func (job *rollupJob) runPass(ctx context.Context) {
+	requestLogger := job.logger.With().
+		Str(loggers.FieldRequestID, ulid.NewULID()).
+		Logger()
+	if err := job.RollupFinalized(requestLogger.WithContext(ctx)); err != nil {
+		requestLogger.Error().Err(err).Msg("rollup pass failed")
+	}
+}
+
+// Stop waits for the rollup goroutine to stop (best called during app shutdown).
+func (job *rollupJob) Stop() {
+	job.stopOnce.Do(func() { close(job.stopCh) })
+	job.wg.Wait()
+}
+
+func (job *rollupJob) RollupFinalized(ctx context.Context) error {
+	if len(job.targetWindowSizes) == 0 {
+		return nil
+	}
+
+	customerIDs, err := job.aggregateResultStore.ListCustomerIDs(ctx)
+	if err != nil {
+		return errInternalAggregateResultStoreFailed(err)
+	}
+
+	var errs []error
+	for _, customerID := range customerIDs {
+		watermark, err := job.watermarkTracker.Get(ctx, customerID)
+		if err != nil {
+			errs = append(errs, errInternalWatermarkStoreFailed(err))
+			continue
+		}
+		finalizedUpTo := watermark.FinalizedUpTo[job.sourceWindowSize]
+		job.mu.Lock()
+		if reported := job.finalizedUpTo[customerID]; reported.After(finalizedUpTo) {
+			finalizedUpTo = reported
+		}
+		job.mu.Unlock()
+		for _, targetWindowSize := range job.targetWindowSizes {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := job.rollup(ctx, customerID, targetWindowSize, finalizedUpTo); err != nil {
+				loggers.Ctx(ctx).Error().Err(err).
+					Msgf("failed to roll up %s windows for customer %s", targetWindowSize, customerID)
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (job *rollupJob) WindowFinalized(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+	if aggregateResult.WindowSize != job.sourceWindowSize || len(job.targetWindowSizes) == 0 {
+		return nil
+	}
+	windowEnd := job.sourceWindowSize.WindowEnd(aggregateResult.WindowStart, job.timeZones[aggregateResult.CustomerID])
+	job.mu.Lock()
+	if windowEnd.After(job.finalizedUpTo[aggregateResult.CustomerID]) {
+		job.finalizedUpTo[aggregateResult.CustomerID] = windowEnd
+	}
+	job.mu.Unlock()
+
+	// A pending trigger already covers this window
+	select {
+	case job.triggerCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// rollup rolls the requeued target windows of targetWindowSize up again, then rolls the source results between
+// the checkpoint and finalizedUpTo up, one target window at a time. Only the sources of one target window are loaded at
+// once, and the checkpoint advances past every target window as soon as it is stored.
+func (job *rollupJob) rollup(ctx context.Context, customerID string, targetWindowSize models.WindowSize, finalizedUpTo time.Time) error {
+	checkpoint, err := job.rollupCheckpointStore.Get(ctx, customerID, targetWindowSize)
+	if err != nil {
+		return errInternalRollupCheckpointStoreFailed(err)
+	}
+
+	loc := job.timeZones[customerID]
+	// A day or week target can be an hour longer across a daylight saving time change
+	maxSources := int((targetWindowSize.Duration()+time.Hour)/job.sourceWindowSize.Duration()) + 1
+	if err := job.rollupRequeued(ctx, checkpoint, loc, maxSources); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		// The earliest source after the checkpoint starts the next target window
+		next, err := job.aggregateResultStore.ListRange(ctx, customerID, job.sourceWindowSize, checkpoint.RolledUpTo, finalizedUpTo, 1)
+		if err != nil {
+			return errInternalAggregateResultStoreFailed(err)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		windowStart := targetWindowSize.Truncate(next[0].WindowStart, loc)
+		windowEnd := targetWindowSize.WindowEnd(windowStart, loc)
+		if windowEnd.After(finalizedUpTo) {
+			// Sources are ordered, so no remaining target window is finalized yet
+			return nil
+		}
+
+		// Store the window before the checkpoint; a crash in between rebuilds the same window on the next pass
+		if err := job.rollupWindow(ctx, customerID, targetWindowSize, windowStart, next[0].WindowStart, windowEnd, maxSources); err != nil {
+			return err
+		}
+		checkpoint.RolledUpTo = windowEnd
+		checkpoint.UpdatedAt = time.Now().UTC()
+		if err := job.rollupCheckpointStore.Put(ctx, checkpoint); err != nil {
+			return errInternalRollupCheckpointStoreFailed(err)
+		}
+		metricWindowRolledUpTotal.WithLabelValues(string(targetWindowSize)).Inc()
+	}
+	return ctx.Err()
+}
+
+// rollupRequeued rolls the requeued target windows before the checkpoint up again and deletes their requeues.
+// Requeued windows after the checkpoint are rolled up by the regular pass, so their requeues are only deleted.
+func (job *rollupJob) rollupRequeued(ctx context.Context, checkpoint *models.RollupCheckpoint, loc *time.Location, maxSources int) error {
+	requeues, err := job.rollupCheckpointStore.ListRequeued(ctx, checkpoint.CustomerID, checkpoint.WindowSize)
+	if err != nil {
+		return errInternalRollupCheckpointStoreFailed(err)
+	}
+
+	var rolledUp time.Time
+	for _, requeue := range requeues {
+		// Requeues are ordered by window start, so a window requeued several times is rolled up once
+		if requeue.WindowStart.Before(checkpoint.RolledUpTo) && !requeue.WindowStart.Equal(rolledUp) {
+			windowEnd := checkpoint.WindowSize.WindowEnd(requeue.WindowStart, loc)
+			if err := job.rollupWindow(ctx, checkpoint.CustomerID, checkpoint.WindowSize, requeue.WindowStart, requeue.WindowStart, windowEnd, maxSources); err != nil {
+				return err
+			}
+			rolledUp = requeue.WindowStart
+			metricWindowRolledUpTotal.WithLabelValues(string(checkpoint.WindowSize)).Inc()
+		}
+		if err := job.rollupCheckpointStore.DeleteRequeued(ctx, requeue); err != nil {
+			return errInternalRollupCheckpointStoreFailed(err)
+		}
+	}
+	return nil
+}
+
+// rollupWindow rebuilds the target window starting at windowStart from its sources between from and windowEnd
+// and stores it.
+func (job *rollupJob) rollupWindow(ctx context.Context, customerID string, targetWindowSize models.WindowSize, windowStart time.Time, from time.Time, windowEnd time.Time, maxSources int) error {
+	sources, err := job.aggregateResultStore.ListRange(ctx, customerID, job.sourceWindowSize, from, windowEnd, maxSources)
+	if err != nil {
+		return errInternalAggregateResultStoreFailed(err)
+	}
+	aggregateResult := models.NewEmptyWindowAggregateResult(customerID, windowStart, targetWindowSize)
+	for _, source := range sources {
+		if err := job.aggregateRolluper.Merge(aggregateResult, source); err != nil {
+			return errInternalAggregateRollupFailed(err)
+		}
+	}
+	if err := job.aggregateResultStore.Upsert(ctx, aggregateResult); err != nil {
+		return errInternalAggregateResultStoreFailed(err)
+	}
+	return nil
+}
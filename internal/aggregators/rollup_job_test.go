@@ -0,0 +1,399 @@
+package aggregators_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/stores"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func minuteResult(customerID string, windowStart time.Time, requests int64) *models.WindowAggregateResult {
+	result := models.NewEmptyWindowAggregateResult(customerID, windowStart, models.WindowMinute)
+	result.Counts(models.DimensionPath)["GET /"] = requests
+	result.Counts(models.DimensionUserAgent)["Chrome"] = requests
+	return result
+}
+
+// finalizedWatermark returns a watermark of the customer with every minute window before finalizedUpTo finalized.
+func finalizedWatermark(customerID string, finalizedUpTo time.Time) *models.Watermark {
+	watermark := models.NewEmptyWatermark(customerID)
+	watermark.FinalizedUpTo[models.WindowMinute] = finalizedUpTo
+	return watermark
+}
+
+// listRangeOf serves AggregateResultStore.ListRange from results, ordered by window start.
+func listRangeOf(results []*models.WindowAggregateResult) func(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+	return func(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+		matches := make([]*models.WindowAggregateResult, 0)
+		for _, result := range results {
+			if len(matches) < limit && !result.WindowStart.Before(from) && result.WindowStart.Before(to) {
+				matches = append(matches, result)
+			}
+		}
+		return matches, nil
+	}
+}
+
+func TestRollupFinalized_RollsUpFinalizedWindows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil, time.Minute, zerolog.Nop())
+
+	hour18 := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	hour19 := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	hour20 := time.Date(2025, 12, 28, 20, 0, 0, 0, time.UTC)
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", hour20.Add(2*time.Minute)), nil)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowHour).
+		Return(&models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowHour}, nil)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-axon", models.WindowHour).Return([]*models.RollupRequeue{}, nil)
+	// One lookup of the next source and one load of the sources per target window, at most an hour and a
+	// daylight saving time change of minutes at a time
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+			assert.LessOrEqual(t, limit, 121)
+			return listRangeOf([]*models.WindowAggregateResult{
+				minuteResult("cus-axon", hour18.Add(3*time.Minute), 2),
+				minuteResult("cus-axon", hour18.Add(40*time.Minute), 3),
+				minuteResult("cus-axon", hour19.Add(10*time.Minute), 4),
+				// the hour of 20:00 is not finalized yet
+				minuteResult("cus-axon", hour20.Add(time.Minute), 5),
+			})(ctx, customerID, windowSize, from, to, limit)
+		}).
+		Times(5)
+
+	var rolledUp []*models.WindowAggregateResult
+	var rolledUpTo []time.Time
+	aggregateResultStore.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, result *models.WindowAggregateResult) { rolledUp = append(rolledUp, result) }).
+		Return(nil).
+		Times(2)
+	rollupCheckpointStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, checkpoint *models.RollupCheckpoint) {
+			rolledUpTo = append(rolledUpTo, checkpoint.RolledUpTo)
+		}).
+		Return(nil).
+		Times(2)
+
+	err := job.RollupFinalized(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, rolledUp, 2)
+	assert.Equal(t, models.WindowHour, rolledUp[0].WindowSize)
+	assert.True(t, hour18.Equal(rolledUp[0].WindowStart))
+	assert.Equal(t, int64(5), rolledUp[0].Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(5), rolledUp[0].Dimensions[models.DimensionUserAgent]["Chrome"])
+	assert.True(t, hour19.Equal(rolledUp[1].WindowStart))
+	assert.Equal(t, int64(4), rolledUp[1].Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, []time.Time{hour19, hour20}, rolledUpTo)
+}
+
+func TestRollupFinalized_ResumesFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowDay}, map[string]*time.Location{"cus-axon": tokyo}, time.Minute, zerolog.Nop())
+
+	// Days in Tokyo start at 15:00 UTC
+	rolledUpTo := time.Date(2025, 12, 27, 15, 0, 0, 0, time.UTC)
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", rolledUpTo.AddDate(0, 0, 1)), nil)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowDay).
+		Return(&models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowDay, RolledUpTo: rolledUpTo}, nil)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-axon", models.WindowDay).Return([]*models.RollupRequeue{}, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(listRangeOf([]*models.WindowAggregateResult{
+			// before the checkpoint, already rolled up
+			minuteResult("cus-axon", time.Date(2025, 12, 27, 10, 0, 0, 0, time.UTC), 1),
+			minuteResult("cus-axon", time.Date(2025, 12, 27, 20, 0, 0, 0, time.UTC), 1),
+			minuteResult("cus-axon", time.Date(2025, 12, 28, 14, 59, 0, 0, time.UTC), 1),
+		})).
+		Times(3)
+	aggregateResultStore.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, result *models.WindowAggregateResult) {
+			assert.True(t, rolledUpTo.Equal(result.WindowStart))
+			assert.Equal(t, int64(2), result.Dimensions[models.DimensionPath]["GET /"])
+		}).
+		Return(nil)
+	rollupCheckpointStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, checkpoint *models.RollupCheckpoint) {
+			assert.True(t, rolledUpTo.AddDate(0, 0, 1).Equal(checkpoint.RolledUpTo))
+		}).
+		Return(nil)
+
+	err = job.RollupFinalized(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRollupFinalized_RollsUpRequeuedWindowsAgain(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil, time.Minute, zerolog.Nop())
+
+	hour18 := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	hour19 := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	requeue := func(id string, windowStart time.Time) *models.RollupRequeue {
+		return &models.RollupRequeue{ID: id, CustomerID: "cus-axon", WindowSize: models.WindowHour, WindowStart: windowStart}
+	}
+	// hour18 was requeued by two corrections, hour19 is not rolled up yet
+	requeues := []*models.RollupRequeue{requeue("requeue-1", hour18), requeue("requeue-2", hour18), requeue("requeue-3", hour19)}
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", hour19), nil)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowHour).
+		Return(&models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowHour, RolledUpTo: hour19}, nil)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-axon", models.WindowHour).Return(requeues, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(listRangeOf([]*models.WindowAggregateResult{
+			minuteResult("cus-axon", hour18.Add(3*time.Minute), 2),
+			minuteResult("cus-axon", hour18.Add(40*time.Minute), 3),
+		})).
+		Times(2)
+	aggregateResultStore.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, result *models.WindowAggregateResult) {
+			assert.True(t, hour18.Equal(result.WindowStart))
+			assert.Equal(t, int64(5), result.Dimensions[models.DimensionPath]["GET /"])
+		}).
+		Return(nil)
+	for _, requeue := range requeues {
+		rollupCheckpointStore.EXPECT().DeleteRequeued(gomock.Any(), requeue).Return(nil)
+	}
+
+	err := job.RollupFinalized(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRollupFinalized_ContinuesAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil, time.Minute, zerolog.Nop())
+
+	finalizedUpTo := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon", "cus-bolt", "cus-cask"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", finalizedUpTo), nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-bolt").Return(nil, errors.New("storage error"))
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-cask").Return(finalizedWatermark("cus-cask", finalizedUpTo), nil)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowHour).Return(nil, errors.New("storage error"))
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-cask", models.WindowHour).
+		Return(&models.RollupCheckpoint{CustomerID: "cus-cask", WindowSize: models.WindowHour}, nil)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-cask", models.WindowHour).Return([]*models.RollupRequeue{}, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-cask", models.WindowMinute, time.Time{}, finalizedUpTo, 1).
+		Return([]*models.WindowAggregateResult{}, nil)
+
+	err := job.RollupFinalized(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "AGG_9002")
+	assert.Contains(t, err.Error(), "AGG_9003")
+}
+
+func TestRollupFinalized_NoTargetWindowSizes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, nil, nil, time.Minute, zerolog.Nop())
+
+	err := job.RollupFinalized(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRollupFinalized_WaitsForFinalizedSources(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil, time.Minute, zerolog.Nop())
+
+	// The hour ended long ago by the wall clock, but its minutes after 18:30 are not finalized yet
+	hour18 := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	sources := []*models.WindowAggregateResult{minuteResult("cus-axon", hour18.Add(3*time.Minute), 2)}
+	checkpoint := &models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowHour}
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil).Times(2)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowHour).Return(checkpoint, nil).Times(2)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-axon", models.WindowHour).Return([]*models.RollupRequeue{}, nil).Times(2)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+			return listRangeOf(sources)(ctx, customerID, windowSize, from, to, limit)
+		}).
+		AnyTimes()
+
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", hour18.Add(30*time.Minute)), nil)
+	err := job.RollupFinalized(context.Background())
+	require.NoError(t, err)
+
+	// A redelivered partial insight still lands in an open minute, which the hour waits for
+	sources = append(sources, minuteResult("cus-axon", hour18.Add(40*time.Minute), 3))
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", hour18.Add(time.Hour)), nil)
+	aggregateResultStore.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, result *models.WindowAggregateResult) {
+			assert.True(t, hour18.Equal(result.WindowStart))
+			assert.Equal(t, int64(5), result.Dimensions[models.DimensionPath]["GET /"])
+		}).
+		Return(nil)
+	rollupCheckpointStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil)
+
+	err = job.RollupFinalized(context.Background())
+	require.NoError(t, err)
+}
+
+func TestWindowFinalized_TriggersRollupOfReportedWindows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	rollupCheckpointStore := storemocks.NewMockRollupCheckpointStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	// The interval is too long to tick during the test, so only the hook triggers the pass
+	job := aggregators.NewRollupJob(aggregators.NewAggregateRolluper(models.CardinalityLimits{}), aggregateResultStore, rollupCheckpointStore,
+		watermarkTracker, models.WindowMinute, []models.WindowSize{models.WindowHour}, nil, time.Hour, zerolog.Nop())
+
+	// The finalizer recorded every minute before 18:59 and reports 18:59 to the hook before recording it
+	hour18 := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	lastMinute := minuteResult("cus-axon", hour18.Add(59*time.Minute), 3)
+	sources := []*models.WindowAggregateResult{minuteResult("cus-axon", hour18.Add(3*time.Minute), 2), lastMinute}
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(finalizedWatermark("cus-axon", lastMinute.WindowStart), nil)
+	rollupCheckpointStore.EXPECT().Get(gomock.Any(), "cus-axon", models.WindowHour).
+		Return(&models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowHour}, nil)
+	rollupCheckpointStore.EXPECT().ListRequeued(gomock.Any(), "cus-axon", models.WindowHour).Return([]*models.RollupRequeue{}, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(listRangeOf(sources)).
+		Times(3)
+	aggregateResultStore.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, result *models.WindowAggregateResult) {
+			assert.True(t, hour18.Equal(result.WindowStart))
+			assert.Equal(t, int64(5), result.Dimensions[models.DimensionPath]["GET /"])
+		}).
+		Return(nil)
+	rolledUp := make(chan struct{})
+	rollupCheckpointStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, checkpoint *models.RollupCheckpoint) { close(rolledUp) }).
+		Return(nil)
+
+	job.Start(context.Background())
+	defer job.Stop()
+
+	// Windows of other sizes do not trigger a pass
+	require.NoError(t, job.WindowFinalized(context.Background(), models.NewEmptyWindowAggregateResult("cus-axon", hour18, models.WindowHour)))
+	require.NoError(t, job.WindowFinalized(context.Background(), lastMinute))
+	select {
+	case <-rolledUp:
+	case <-time.After(5 * time.Second):
+		t.Fatal("finalized window did not trigger a rollup pass")
+	}
+}
+
+func TestRollupFinalized_RollsUpSourceChangedAfterRollup(t *testing.T) {
+	t.Parallel()
+
+	fileStorage, err := filestorages.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	aggregateRolluper := aggregators.NewAggregateRolluper(models.CardinalityLimits{})
+	aggregateResultStore := stores.NewAggregateResultStore(fileStorage)
+	lateInsightStore := stores.NewLateInsightStore(fileStorage)
+	rollupCheckpointStore := stores.NewRollupCheckpointStore(fileStorage)
+	watermarkTracker := aggregators.NewWatermarkTracker(stores.NewWatermarkStore(fileStorage))
+	windowSizes := []models.WindowSize{models.WindowMinute}
+	rollupWindowSizes := []models.WindowSize{models.WindowHour}
+
+	aggregationService := aggregators.NewAggregationService(aggregateRolluper, aggregateResultStore, watermarkTracker, lateInsightStore, nil)
+	finalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, windowSizes, nil, 5*time.Minute, time.Minute, zerolog.Nop())
+	job := aggregators.NewRollupJob(aggregateRolluper, aggregateResultStore, rollupCheckpointStore, watermarkTracker, models.WindowMinute,
+		rollupWindowSizes, nil, time.Minute, zerolog.Nop())
+	reconciler := aggregators.NewLateInsightReconciler(aggregateRolluper, aggregateResultStore, lateInsightStore, stores.NewWindowCorrectionStore(fileStorage),
+		rollupCheckpointStore, models.WindowMinute, rollupWindowSizes, nil)
+
+	ctx := context.Background()
+	hour18 := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	aggregate := func(batchID string, windowStart time.Time, requests int64) {
+		svcErr := aggregationService.Aggregate(ctx, &events.PartialInsightEvent{
+			CustomerID:    "cus-axon",
+			BatchID:       batchID,
+			WindowStart:   windowStart,
+			WindowSize:    models.WindowMinute,
+			MaxReceivedAt: windowStart.Add(30 * time.Second),
+			WindowAggregates: models.WindowAggregates{
+				Dimensions: models.Dimensions{models.DimensionPath: {"GET /": requests}},
+			},
+		})
+		require.Nil(t, svcErr)
+	}
+	rolledUpRequests := func() int64 {
+		result, err := aggregateResultStore.Get(ctx, "cus-axon", hour18, models.WindowHour)
+		require.NoError(t, err)
+		return result.Dimensions[models.DimensionPath]["GET /"]
+	}
+
+	// The 19:10 batch moves the watermark past the hour of 18:00, which is finalized and rolled up
+	aggregate("batch-1", hour18.Add(3*time.Minute), 2)
+	aggregate("batch-2", hour18.Add(70*time.Minute), 1)
+	require.NoError(t, finalizer.FinalizeWindows(ctx))
+	require.NoError(t, job.RollupFinalized(ctx))
+	assert.Equal(t, int64(2), rolledUpRequests())
+
+	// A late partial insight for 18:03 is kept instead of changing the finalized minute, and its correction
+	// requeues the hour, so the next pass rolls it up again
+	aggregate("batch-3", hour18.Add(3*time.Minute), 3)
+	corrections, err := reconciler.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Len(t, corrections, 1)
+	require.NoError(t, job.RollupFinalized(ctx))
+	assert.Equal(t, int64(5), rolledUpRequests())
+}
@@ -0,0 +1,112 @@
+package aggregators
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/stores"
+)
+
+// WatermarkTracker owns the event-time watermarks of all customers. The consumer partitions and the window
+// finalizer update the watermark of a customer concurrently, so every update of one customer is serialized
+// here, and watermarks are cached so reading them on every partial insight does not hit the store.
+//
+//go:generate mockgen -source=watermark_tracker.go -destination=./mocks/watermark_tracker_mock.go -package=mocks
+type WatermarkTracker interface {
+	// Get returns a copy of the customer's watermark.
+	Get(ctx context.Context, customerID string) (*models.Watermark, error)
+	// Observe raises the max receivedAt seen for the customer; an older receivedAt is ignored. A receivedAt
+	// ahead of the server clock only raises it up to now, so one future-dated entry cannot finalize open windows.
+	Observe(ctx context.Context, customerID string, receivedAt time.Time) error
+	// MarkFinalized records that every window of windowSize ending at or before upTo was finalized.
+	MarkFinalized(ctx context.Context, customerID string, windowSize models.WindowSize, upTo time.Time) error
+}
+
+type customerWatermark struct {
+	mu        sync.Mutex
+	watermark *models.Watermark // nil until loaded from the store
+}
+
+type watermarkTracker struct {
+	watermarkStore stores.WatermarkStore
+
+	mu         sync.Mutex
+	watermarks map[string]*customerWatermark
+}
+
+func NewWatermarkTracker(watermarkStore stores.WatermarkStore) WatermarkTracker {
+	return &watermarkTracker{
+		watermarkStore: watermarkStore,
+		watermarks:     make(map[string]*customerWatermark),
+	}
+}
+
+func (t *watermarkTracker) Get(ctx context.Context, customerID string) (*models.Watermark, error) {
+	var watermark *models.Watermark
+	err := t.update(ctx, customerID, func(current *models.Watermark) *models.Watermark {
+		watermark = current.Clone()
+		return nil
+	})
+	return watermark, err
+}
+
+func (t *watermarkTracker) Observe(ctx context.Context, customerID string, receivedAt time.Time) error {
+	if now := time.Now().UTC(); receivedAt.After(now) {
+		receivedAt = now
+	}
+	return t.update(ctx, customerID, func(current *models.Watermark) *models.Watermark {
+		if !receivedAt.After(current.MaxReceivedAt) {
+			return nil
+		}
+		updated := current.Clone()
+		updated.MaxReceivedAt = receivedAt.UTC()
+		return updated
+	})
+}
+
+func (t *watermarkTracker) MarkFinalized(ctx context.Context, customerID string, windowSize models.WindowSize, upTo time.Time) error {
+	return t.update(ctx, customerID, func(current *models.Watermark) *models.Watermark {
+		if !upTo.After(current.FinalizedUpTo[windowSize]) {
+			return nil
+		}
+		updated := current.Clone()
+		updated.FinalizedUpTo[windowSize] = upTo.UTC()
+		return updated
+	})
+}
+
+// update runs fn on the current watermark of the customer while holding its lock, loading it from the store
+// on first use. When fn returns a new watermark, it is stored before it replaces the cached one.
+func (t *watermarkTracker) update(ctx context.Context, customerID string, fn func(current *models.Watermark) *models.Watermark) error {
+	t.mu.Lock()
+	entry, ok := t.watermarks[customerID]
+	if !ok {
+		entry = &customerWatermark{}
+		t.watermarks[customerID] = entry
+	}
+	t.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.watermark == nil {
+		watermark, err := t.watermarkStore.Get(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		entry.watermark = watermark
+	}
+
+	updated := fn(entry.watermark)
+	if updated == nil {
+		return nil
+	}
+	updated.UpdatedAt = time.Now().UTC()
+	if err := t.watermarkStore.Put(ctx, updated); err != nil {
+		return err
+	}
+	entry.watermark = updated
+	return nil
+}
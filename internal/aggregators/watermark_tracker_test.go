@@ -0,0 +1,122 @@
+package aggregators_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/models"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWatermarkTracker_Observe_OnlyAdvances(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watermarkStore := storemocks.NewMockWatermarkStore(ctrl)
+	tracker := aggregators.NewWatermarkTracker(watermarkStore)
+
+	ctx := context.Background()
+	receivedAt := time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC)
+
+	// Loaded once, then served from the cache
+	watermarkStore.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil).Times(1)
+	watermarkStore.EXPECT().Put(ctx, gomock.Any()).
+		Do(func(ctx context.Context, watermark *models.Watermark) {
+			assert.True(t, receivedAt.Equal(watermark.MaxReceivedAt))
+			assert.False(t, watermark.UpdatedAt.IsZero())
+		}).
+		Return(nil).
+		Times(1)
+
+	require.NoError(t, tracker.Observe(ctx, "cus-axon", receivedAt))
+	// An older receivedAt does not move the watermark back
+	require.NoError(t, tracker.Observe(ctx, "cus-axon", receivedAt.Add(-time.Minute)))
+
+	watermark, err := tracker.Get(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.True(t, receivedAt.Equal(watermark.MaxReceivedAt))
+	assert.True(t, receivedAt.Add(-5*time.Minute).Equal(watermark.Watermark(5*time.Minute)))
+}
+
+func TestWatermarkTracker_Observe_ClampsFutureReceivedAt(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watermarkStore := storemocks.NewMockWatermarkStore(ctrl)
+	tracker := aggregators.NewWatermarkTracker(watermarkStore)
+
+	ctx := context.Background()
+	before := time.Now().UTC()
+	watermarkStore.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	watermarkStore.EXPECT().Put(ctx, gomock.Any()).Return(nil)
+
+	require.NoError(t, tracker.Observe(ctx, "cus-axon", before.AddDate(1, 0, 0)))
+
+	watermark, err := tracker.Get(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.False(t, watermark.MaxReceivedAt.Before(before))
+	assert.False(t, watermark.MaxReceivedAt.After(time.Now().UTC()), "a future receivedAt only advances to the server clock")
+}
+
+func TestWatermarkTracker_MarkFinalized(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watermarkStore := storemocks.NewMockWatermarkStore(ctrl)
+	tracker := aggregators.NewWatermarkTracker(watermarkStore)
+
+	ctx := context.Background()
+	upTo := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+
+	watermarkStore.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	watermarkStore.EXPECT().Put(ctx, gomock.Any()).Return(nil).Times(1)
+
+	require.NoError(t, tracker.MarkFinalized(ctx, "cus-axon", models.WindowMinute, upTo))
+	require.NoError(t, tracker.MarkFinalized(ctx, "cus-axon", models.WindowMinute, upTo.Add(-time.Hour)))
+
+	watermark, err := tracker.Get(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.True(t, watermark.IsWindowFinalized(models.WindowMinute, upTo.Add(-time.Minute)))
+	assert.False(t, watermark.IsWindowFinalized(models.WindowMinute, upTo))
+	assert.False(t, watermark.IsWindowFinalized(models.WindowHour, upTo.Add(-time.Hour)))
+
+	// Get returns a copy
+	watermark.FinalizedUpTo[models.WindowMinute] = time.Time{}
+	again, err := tracker.Get(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.True(t, upTo.Equal(again.FinalizedUpTo[models.WindowMinute]))
+}
+
+func TestWatermarkTracker_PutFailureKeepsPreviousWatermark(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watermarkStore := storemocks.NewMockWatermarkStore(ctrl)
+	tracker := aggregators.NewWatermarkTracker(watermarkStore)
+
+	ctx := context.Background()
+	watermarkStore.EXPECT().Get(ctx, "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	watermarkStore.EXPECT().Put(ctx, gomock.Any()).Return(errors.New("storage error"))
+
+	err := tracker.Observe(ctx, "cus-axon", time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC))
+	assert.Error(t, err)
+
+	watermark, err := tracker.Get(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.True(t, watermark.MaxReceivedAt.IsZero())
+}
@@ -0,0 +1,183 @@
+package aggregators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/shared/ulid"
+	"log-analytics/internal/stores"
+)
+
+// WindowFinalizedHook is called for every window the finalizer finalizes, e.g. to export it downstream or to
+// trigger the rollup job. Every earlier window of the same size is already recorded finalized in the watermark
+// when the hook runs. A failing hook stops the pass before the window itself is recorded, so hooks run at
+// least once per window and must be idempotent.
+type WindowFinalizedHook func(ctx context.Context, aggregateResult *models.WindowAggregateResult) error
+
+// WindowFinalizer periodically finalizes the windows of every customer that end before the customer's
+// watermark, the latest receivedAt aggregated minus the allowed lateness. Partial insights arriving for a
+// finalized window are routed to the late-data path by the aggregation service instead of updating it.
+//
+//go:generate mockgen -source=window_finalizer.go -destination=./mocks/window_finalizer_mock.go -package=mocks
+type WindowFinalizer interface {
+	Start(ctx context.Context)
+	Stop()
+	// FinalizeWindows runs a single finalization pass over every customer and window size.
+	FinalizeWindows(ctx context.Context) error
+}
+
+type windowFinalizer struct {
+	aggregateResultStore stores.AggregateResultStore
+	watermarkTracker     WatermarkTracker
+	windowSizes          []models.WindowSize
+	timeZones            map[string]*time.Location
+	allowedLateness      time.Duration
+	interval             time.Duration
+	hooks                []WindowFinalizedHook
+
+	wg sync.WaitGroup
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+
+	logger loggers.Logger
+}
+
+// NewWindowFinalizer creates a WindowFinalizer for the windows of windowSizes. timeZones holds the time zone
+// day and week windows of a customer are aligned to.
+func NewWindowFinalizer(aggregateResultStore stores.AggregateResultStore, watermarkTracker WatermarkTracker, windowSizes []models.WindowSize, timeZones map[string]*time.Location, allowedLateness time.Duration, interval time.Duration, logger loggers.Logger, hooks ...WindowFinalizedHook) WindowFinalizer {
+	return &windowFinalizer{
+		aggregateResultStore: aggregateResultStore,
+		watermarkTracker:     watermarkTracker,
+		windowSizes:          windowSizes,
+		timeZones:            timeZones,
+		allowedLateness:      allowedLateness,
+		interval:             interval,
+		hooks:                hooks,
+		stopCh:               make(chan struct{}),
+		logger:               logger,
+	}
+}
+
+// Start spawns the finalizer goroutine, which runs a finalization pass on every tick of the interval.
+func (f *windowFinalizer) Start(ctx context.Context) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.stopCh:
+				return
+			case <-ticker.C:
+				requestLogger := f.logger.With().
+					Str(loggers.FieldRequestID, ulid.NewULID()).
+					Logger()
+				if err := f.FinalizeWindows(requestLogger.WithContext(ctx)); err != nil {
+					requestLogger.Error().Err(err).Msg("window finalization pass failed")
+				}
+			}
+		}
+	}()
+}
+
+// Stop waits for the finalizer goroutine to stop (best called during app shutdown).
+func (f *windowFinalizer) Stop() {
+	f.stopOnce.Do(func() { close(f.stopCh) })
+	f.wg.Wait()
+}
+
+func (f *windowFinalizer) FinalizeWindows(ctx context.Context) error {
+	customerIDs, err := f.aggregateResultStore.ListCustomerIDs(ctx)
+	if err != nil {
+		return errInternalAggregateResultStoreFailed(err)
+	}
+
+	var errs []error
+	for _, customerID := range customerIDs {
+		watermark, err := f.watermarkTracker.Get(ctx, customerID)
+		if err != nil {
+			errs = append(errs, errInternalWatermarkStoreFailed(err))
+			continue
+		}
+		for _, windowSize := range f.windowSizes {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := f.finalize(ctx, watermark, windowSize); err != nil {
+				loggers.Ctx(ctx).Error().Err(err).
+					Msgf("failed to finalize %s windows for customer %s", windowSize, customerID)
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finalize finalizes the windows of windowSize between the last finalized window and the watermark.
+func (f *windowFinalizer) finalize(ctx context.Context, watermark *models.Watermark, windowSize models.WindowSize) error {
+	eventTime := watermark.Watermark(f.allowedLateness)
+	if eventTime.IsZero() {
+		return nil
+	}
+	// Every window starting before the window that contains the watermark has ended
+	from := watermark.FinalizedUpTo[windowSize]
+	upTo := windowSize.Truncate(eventTime, f.timeZones[watermark.CustomerID])
+	if !upTo.After(from) {
+		return nil
+	}
+
+	aggregateResults, err := f.aggregateResultStore.ListRange(ctx, watermark.CustomerID, windowSize, from, upTo, math.MaxInt)
+	if err != nil {
+		return errInternalAggregateResultStoreFailed(err)
+	}
+	for _, listed := range aggregateResults {
+		// Windows are listed in order, so every window before this one is finalized and its hooks ran
+		if listed.WindowStart.After(from) {
+			if err := f.watermarkTracker.MarkFinalized(ctx, watermark.CustomerID, windowSize, listed.WindowStart); err != nil {
+				return errInternalWatermarkStoreFailed(err)
+			}
+			from = listed.WindowStart
+		}
+
+		// Finalized under the window's lock so a concurrent Aggregate either lands before or is routed late
+		finalized := false
+		aggregateResult, err := f.aggregateResultStore.Update(ctx, listed.CustomerID, listed.WindowStart, listed.WindowSize,
+			func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+				if aggregateResult.Finalized {
+					return false, nil
+				}
+				aggregateResult.Finalized = true
+				aggregateResult.FinalizedAt = time.Now().UTC()
+				finalized = true
+				return true, nil
+			})
+		if err != nil {
+			return errInternalAggregateResultStoreFailed(err)
+		}
+		if finalized {
+			metricWindowFinalizedTotal.WithLabelValues(string(windowSize)).Inc()
+		}
+		// Hooks see the window as stored, including partial insights aggregated after it was listed
+		for _, hook := range f.hooks {
+			if err := hook(ctx, aggregateResult); err != nil {
+				return errInternalWindowFinalizedHookFailed(fmt.Errorf("window %s: %w", aggregateResult.WindowStart.Format(time.RFC3339), err))
+			}
+		}
+	}
+
+	if err := f.watermarkTracker.MarkFinalized(ctx, watermark.CustomerID, windowSize, upTo); err != nil {
+		return errInternalWatermarkStoreFailed(err)
+	}
+	return nil
+}
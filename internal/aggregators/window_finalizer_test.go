@@ -0,0 +1,162 @@
+package aggregators_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/models"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFinalizeWindows_FinalizesWindowsBeforeWatermark(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+
+	var hooked []time.Time
+	hook := func(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+		hooked = append(hooked, aggregateResult.WindowStart)
+		return nil
+	}
+	finalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, []models.WindowSize{models.WindowMinute},
+		nil, 5*time.Minute, time.Minute, zerolog.Nop(), hook)
+
+	// Watermark 18:05:30 - 5m = 18:00:30, so windows before 18:00 end before it
+	finalizedUpTo := time.Date(2025, 12, 28, 17, 58, 0, 0, time.UTC)
+	upTo := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	watermark := models.NewEmptyWatermark("cus-axon")
+	watermark.MaxReceivedAt = time.Date(2025, 12, 28, 18, 5, 30, 0, time.UTC)
+	watermark.FinalizedUpTo[models.WindowMinute] = finalizedUpTo
+
+	alreadyFinalized := models.NewEmptyWindowAggregateResult("cus-axon", finalizedUpTo, models.WindowMinute)
+	alreadyFinalized.Finalized = true
+	open := models.NewEmptyWindowAggregateResult("cus-axon", finalizedUpTo.Add(time.Minute), models.WindowMinute)
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(watermark, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, finalizedUpTo, upTo, math.MaxInt).
+		Return([]*models.WindowAggregateResult{alreadyFinalized, open}, nil)
+	alreadyFinalizedWritten, openWritten := false, false
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", alreadyFinalized.WindowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(alreadyFinalized, &alreadyFinalizedWritten))
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", open.WindowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(open, &openWritten))
+	// Progress is recorded before the hooks of every later window, then up to the watermark
+	gomock.InOrder(
+		watermarkTracker.EXPECT().MarkFinalized(gomock.Any(), "cus-axon", models.WindowMinute, open.WindowStart).Return(nil),
+		watermarkTracker.EXPECT().MarkFinalized(gomock.Any(), "cus-axon", models.WindowMinute, upTo).Return(nil),
+	)
+
+	err := finalizer.FinalizeWindows(context.Background())
+	require.NoError(t, err)
+	assert.False(t, alreadyFinalizedWritten)
+	assert.True(t, openWritten)
+	assert.True(t, open.Finalized)
+	assert.False(t, open.FinalizedAt.IsZero())
+	// Hooks run again for a window finalized by an interrupted pass
+	assert.Equal(t, []time.Time{alreadyFinalized.WindowStart, open.WindowStart}, hooked)
+}
+
+func TestFinalizeWindows_SkipsCustomersWithoutProgress(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	finalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, []models.WindowSize{models.WindowHour},
+		nil, 5*time.Minute, time.Minute, zerolog.Nop())
+
+	// The watermark of cus-bolt is still inside the window finalized last
+	bolt := models.NewEmptyWatermark("cus-bolt")
+	bolt.MaxReceivedAt = time.Date(2025, 12, 28, 18, 30, 0, 0, time.UTC)
+	bolt.FinalizedUpTo[models.WindowHour] = time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon", "cus-bolt"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(models.NewEmptyWatermark("cus-axon"), nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-bolt").Return(bolt, nil)
+
+	err := finalizer.FinalizeWindows(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestFinalizeWindows_HookFailureKeepsProgress(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	hook := func(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+		return errors.New("export failed")
+	}
+	finalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, []models.WindowSize{models.WindowMinute},
+		nil, 0, time.Minute, zerolog.Nop(), hook)
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	watermark := models.NewEmptyWatermark("cus-axon")
+	watermark.MaxReceivedAt = windowStart.Add(2 * time.Minute)
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(watermark, nil)
+	stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, time.Time{}, windowStart.Add(2*time.Minute), math.MaxInt).
+		Return([]*models.WindowAggregateResult{stored}, nil)
+	// Only the windows before the one whose hook failed are recorded
+	watermarkTracker.EXPECT().MarkFinalized(gomock.Any(), "cus-axon", models.WindowMinute, windowStart).Return(nil)
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		DoAndReturn(updateOf(stored, nil))
+
+	err := finalizer.FinalizeWindows(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AGG_9004")
+}
+
+func TestFinalizeWindows_StoreFailureSkipsHooks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	watermarkTracker := aggregatormocks.NewMockWatermarkTracker(ctrl)
+	hooked := 0
+	hook := func(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+		hooked++
+		return nil
+	}
+	finalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, []models.WindowSize{models.WindowMinute},
+		nil, 0, time.Minute, zerolog.Nop(), hook)
+
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	watermark := models.NewEmptyWatermark("cus-axon")
+	watermark.MaxReceivedAt = windowStart.Add(2 * time.Minute)
+
+	aggregateResultStore.EXPECT().ListCustomerIDs(gomock.Any()).Return([]string{"cus-axon"}, nil)
+	watermarkTracker.EXPECT().Get(gomock.Any(), "cus-axon").Return(watermark, nil)
+	aggregateResultStore.EXPECT().ListRange(gomock.Any(), "cus-axon", models.WindowMinute, time.Time{}, windowStart.Add(2*time.Minute), math.MaxInt).
+		Return([]*models.WindowAggregateResult{models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)}, nil)
+	watermarkTracker.EXPECT().MarkFinalized(gomock.Any(), "cus-axon", models.WindowMinute, windowStart).Return(nil)
+	aggregateResultStore.EXPECT().Update(gomock.Any(), "cus-axon", windowStart, models.WindowMinute, gomock.Any()).
+		Return(nil, errors.New("storage error"))
+
+	err := finalizer.FinalizeWindows(context.Background())
+	require.Error(t, err)
+	assert.Zero(t, hooked)
+}
@@ -2,20 +2,24 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"log-analytics/internal/aggregators"
 	"log-analytics/internal/events"
 	internalhttp "log-analytics/internal/http"
 	"log-analytics/internal/ingestors"
+	"log-analytics/internal/models"
+	"log-analytics/internal/replays"
 	"log-analytics/internal/shared/configs"
 	"log-analytics/internal/shared/filestorages"
 	"log-analytics/internal/shared/loggers"
 	"log-analytics/internal/stores"
 	"log-analytics/internal/streams"
-	"log-analytics/internal/models"
 )
 
 // App holds all application dependencies and manages lifecycle.
@@ -24,13 +28,17 @@ type App struct {
 	appLogger loggers.Logger
 	server    *http.Server
 
+	partialInsightQueue    streams.PartitionedQueue[events.PartialInsightEvent]
 	partialInsightConsumer streams.PartialInsightConsumer
+	outboxRelay            ingestors.OutboxRelay
+	rollupJob              aggregators.RollupJob
+	windowFinalizer        aggregators.WindowFinalizer
 	backgroundCtx          context.Context
 	backgroundCancel       context.CancelFunc
 }
 
 // New creates and initializes a new App instance.
-func New(config *configs.Config) (*App, error) {
+func New(config *configs.Config) (_ *App, err error) {
 	appLogger, err := loggers.New(config.Log.Level)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
@@ -47,28 +55,85 @@ func New(config *configs.Config) (*App, error) {
 	}
 
 	// Initialize stream queue
-	partialInsightQueue := streams.NewPartitionedQueue[events.PartialInsightEvent]()
+	queueLogger := appLogger.With().Str(loggers.FieldComponent, "queue").Logger()
+	partialInsightQueue, err := newPartialInsightQueue(config, queueLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize partial insight queue: %w", err)
+	}
+	// The queue is the only component holding resources before Start, a durable one its segment files and
+	// delivery and fsync goroutines, so it is closed when initialization fails past this point
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, partialInsightQueue.Close())
+		}
+	}()
 
 	// Initialize aggregation service
-	windowSize, err := models.NewWindowSizeFromString(config.Aggregation.WindowSize)
+	windowSizes, err := newWindowSizes(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize window sizes: %w", err)
+	}
+	timeZones, err := newCustomerTimeZones(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize window size: %w", err)
+		return nil, fmt.Errorf("failed to initialize customer time zones: %w", err)
+	}
+	rollupWindowSizes, err := newRollupWindowSizes(config, windowSizes, timeZones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rollup window sizes: %w", err)
 	}
 	aggregateResultStore := stores.NewAggregateResultStore(fileStorage)
-	aggregateRolluper := aggregators.NewAggregateRolluper()
-	aggregationService := aggregators.NewAggregationService(aggregateRolluper, aggregateResultStore)
+	migrated, err := aggregateResultStore.MigrateLegacyKeys(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate aggregate results: %w", err)
+	}
+	if migrated > 0 {
+		appLogger.Info().Msgf("migrated %d aggregate results to the window size layout", migrated)
+	}
+	aggregateRolluper := aggregators.NewAggregateRolluper(newCardinalityLimits(config))
+	watermarkTracker := aggregators.NewWatermarkTracker(stores.NewWatermarkStore(fileStorage))
+	lateInsightStore := stores.NewLateInsightStore(fileStorage)
+	aggregationService := aggregators.NewAggregationService(aggregateRolluper, aggregateResultStore, watermarkTracker, lateInsightStore, timeZones)
+	aggregateQueryService := aggregators.NewAggregateQueryService(aggregateResultStore, slices.Concat(windowSizes, rollupWindowSizes), newPathUserAgentBreakdown(config))
 	consumerLogger := appLogger.With().Str(loggers.FieldComponent, "consumer").Logger()
-	partialInsightConsumer := streams.NewPartialInsightConsumer(partialInsightQueue, aggregationService, consumerLogger)
+	partialInsightConsumer := streams.NewPartialInsightConsumer(partialInsightQueue, aggregationService, stores.NewDeadLetterStore(fileStorage), consumerLogger)
+	rollupCheckpointStore := stores.NewRollupCheckpointStore(fileStorage)
+	rollupLogger := appLogger.With().Str(loggers.FieldComponent, "rollup").Logger()
+	rollupJob := aggregators.NewRollupJob(aggregateRolluper, aggregateResultStore, rollupCheckpointStore, watermarkTracker, windowSizes[0], rollupWindowSizes,
+		timeZones, time.Duration(config.Aggregation.Rollup.Interval)*time.Second, rollupLogger)
+	finalizerLogger := appLogger.With().Str(loggers.FieldComponent, "window-finalizer").Logger()
+	windowFinalizer := aggregators.NewWindowFinalizer(aggregateResultStore, watermarkTracker, windowSizes, timeZones,
+		time.Duration(config.Aggregation.AllowedLateness)*time.Second, time.Duration(config.Aggregation.FinalizeInterval)*time.Second, finalizerLogger,
+		rollupJob.WindowFinalized)
 
 	// Initialize ingestionService
 	batchStore := stores.NewLogBatchStore(fileStorage)
-	batchSummarizer := ingestors.NewBatchSummarizer(windowSize)
-	partialInsightProducer := streams.NewPartialInsightProducer(partialInsightQueue)
-	ingestionService := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	outboxStore := stores.NewOutboxStore(fileStorage)
+	rejectedEntryStore := stores.NewRejectedEntryStore(fileStorage)
+	pathNormalizer, err := newPathNormalizer(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize path normalizer: %w", err)
+	}
+	dimensionExtractors, err := newDimensionExtractors(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize dimension extractors: %w", err)
+	}
+	customerDimensionExtractors, err := newCustomerDimensionExtractors(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize customer dimension extractors: %w", err)
+	}
+	batchSummarizer := ingestors.NewBatchSummarizer(windowSizes, timeZones, pathNormalizer, newCardinalityLimits(config), models.VisitorIdentity(config.Aggregation.UniqueVisitorIdentity), newPathUserAgentBreakdown(config), dimensionExtractors, customerDimensionExtractors)
+	partialInsightProducer := streams.NewPartialInsightProducer(partialInsightQueue, timeZones)
+	pendingTimeout := time.Duration(config.Outbox.PendingTimeout) * time.Second
+	ingestionLimits, customerIngestionLimits := newIngestionLimits(config)
+	ingestionService := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, pendingTimeout, ingestionLimits, customerIngestionLimits)
+	batchStatusService := ingestors.NewBatchStatusService(batchStore, outboxStore, aggregateResultStore, windowSizes, timeZones)
+	relayLogger := appLogger.With().Str(loggers.FieldComponent, "outbox-relay").Logger()
+	outboxRelay := ingestors.NewOutboxRelay(batchSummarizer, batchStore, outboxStore, partialInsightProducer,
+		time.Duration(config.Outbox.RelayInterval)*time.Second, pendingTimeout, relayLogger)
 
 	// Initialize http qrouter
 	httpLogger := appLogger.With().Str(loggers.FieldComponent, "http").Logger()
-	router := internalhttp.NewRouter(ingestionService, httpLogger)
+	router := internalhttp.NewRouter(ingestionService, batchStatusService, aggregateQueryService, httpLogger)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -84,7 +149,11 @@ func New(config *configs.Config) (*App, error) {
 		config:                 config,
 		appLogger:              appLogger,
 		server:                 server,
+		partialInsightQueue:    partialInsightQueue,
 		partialInsightConsumer: partialInsightConsumer,
+		outboxRelay:            outboxRelay,
+		rollupJob:              rollupJob,
+		windowFinalizer:        windowFinalizer,
 	}, nil
 }
 
@@ -99,6 +168,9 @@ func (app *App) Start() error {
 	// start background consumers
 	app.backgroundCtx, app.backgroundCancel = context.WithCancel(context.Background())
 	app.partialInsightConsumer.Start(app.backgroundCtx)
+	app.outboxRelay.Start(app.backgroundCtx)
+	app.rollupJob.Start(app.backgroundCtx)
+	app.windowFinalizer.Start(app.backgroundCtx)
 
 	return app.server.ListenAndServe()
 }
@@ -117,9 +189,282 @@ func (app *App) Shutdown(ctx context.Context) error {
 		app.appLogger.Info().Msg("Background consumers cancelled")
 	}
 
-	// 3) Wait for background consumers to finish
+	// 3) Wait for background consumers to finish; the relay produces into the queue, so stop it first
+	app.outboxRelay.Stop()
 	app.partialInsightConsumer.Stop()
+	app.rollupJob.Stop()
+	app.windowFinalizer.Stop()
 	app.appLogger.Info().Msg("Background consumers stopped")
 
+	// 4) Close the queue, flushing the durable log to disk
+	if err := app.partialInsightQueue.Close(); err != nil {
+		return fmt.Errorf("queue close failed: %w", err)
+	}
+	app.appLogger.Info().Msg("Queue closed")
+
 	return nil
 }
+
+// NewLateInsightReconciler creates the LateInsightReconciler applying late partial insights to the live
+// aggregate results and requeueing the rollups built from them.
+func NewLateInsightReconciler(config *configs.Config) (aggregators.LateInsightReconciler, error) {
+	fileStorage, err := filestorages.NewFileStorage(config.FileStorage.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	windowSizes, err := newWindowSizes(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize window sizes: %w", err)
+	}
+	timeZones, err := newCustomerTimeZones(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize customer time zones: %w", err)
+	}
+	rollupWindowSizes, err := newRollupWindowSizes(config, windowSizes, timeZones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rollup window sizes: %w", err)
+	}
+	return aggregators.NewLateInsightReconciler(aggregators.NewAggregateRolluper(newCardinalityLimits(config)), stores.NewAggregateResultStore(fileStorage),
+		stores.NewLateInsightStore(fileStorage), stores.NewWindowCorrectionStore(fileStorage), stores.NewRollupCheckpointStore(fileStorage),
+		windowSizes[0], rollupWindowSizes, timeZones), nil
+}
+
+// NewBatchReplayer creates the BatchReplayer of replayID for the configured window sizes and customer time
+// zones. It rebuilds results under replays/{replayID}/aggregate-results, next to the live ones.
+func NewBatchReplayer(config *configs.Config, replayID string, concurrency int) (replays.BatchReplayer, error) {
+	if err := replays.ValidateReplayID(replayID); err != nil {
+		return nil, err
+	}
+	logger, err := loggers.New(config.Log.Level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	logger = logger.With().
+		Str(loggers.FieldApp, "log-analytics").
+		Str(loggers.FieldComponent, "replay").
+		Logger()
+
+	fileStorage, err := filestorages.NewFileStorage(config.FileStorage.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	windowSizes, err := newWindowSizes(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize window sizes: %w", err)
+	}
+	timeZones, err := newCustomerTimeZones(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize customer time zones: %w", err)
+	}
+
+	pathNormalizer, err := newPathNormalizer(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize path normalizer: %w", err)
+	}
+	dimensionExtractors, err := newDimensionExtractors(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize dimension extractors: %w", err)
+	}
+	customerDimensionExtractors, err := newCustomerDimensionExtractors(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize customer dimension extractors: %w", err)
+	}
+
+	outputStore := stores.NewAggregateResultStoreWithDir(fileStorage, fmt.Sprintf("replays/%s/aggregate-results", replayID))
+	return replays.NewBatchReplayer(replayID, stores.NewLogBatchStore(fileStorage), ingestors.NewBatchSummarizer(windowSizes, timeZones, pathNormalizer, newCardinalityLimits(config), models.VisitorIdentity(config.Aggregation.UniqueVisitorIdentity), newPathUserAgentBreakdown(config), dimensionExtractors, customerDimensionExtractors),
+		aggregators.NewAggregateRolluper(newCardinalityLimits(config)), outputStore, stores.NewReplayCheckpointStore(fileStorage), concurrency, logger), nil
+}
+
+// newIngestionLimits returns the global ingestion limits and the limits of every customer with overrides.
+func newIngestionLimits(config *configs.Config) (ingestors.IngestionLimits, map[string]ingestors.IngestionLimits) {
+	limits := ingestors.IngestionLimits{
+		MaxBatchBytes:      config.Ingestion.MaxBatchBytes,
+		MaxEntries:         config.Ingestion.MaxEntries,
+		MaxPathLength:      config.Ingestion.MaxPathLength,
+		MaxUserAgentLength: config.Ingestion.MaxUserAgentLength,
+		MaxReceivedAtSkew:  time.Duration(config.Ingestion.MaxReceivedAtSkew) * time.Second,
+		PartialAccept:      config.Ingestion.PartialAccept,
+	}
+
+	customerLimits := make(map[string]ingestors.IngestionLimits)
+	for _, customer := range config.Customers {
+		if customer.Ingestion == nil {
+			continue
+		}
+		override := limits
+		if customer.Ingestion.MaxBatchBytes != nil {
+			override.MaxBatchBytes = *customer.Ingestion.MaxBatchBytes
+		}
+		if customer.Ingestion.MaxEntries != nil {
+			override.MaxEntries = *customer.Ingestion.MaxEntries
+		}
+		if customer.Ingestion.MaxPathLength != nil {
+			override.MaxPathLength = *customer.Ingestion.MaxPathLength
+		}
+		if customer.Ingestion.MaxUserAgentLength != nil {
+			override.MaxUserAgentLength = *customer.Ingestion.MaxUserAgentLength
+		}
+		if customer.Ingestion.MaxReceivedAtSkew != nil {
+			override.MaxReceivedAtSkew = time.Duration(*customer.Ingestion.MaxReceivedAtSkew) * time.Second
+		}
+		if customer.Ingestion.PartialAccept != nil {
+			override.PartialAccept = *customer.Ingestion.PartialAccept
+		}
+		customerLimits[customer.ID] = override
+	}
+	return limits, customerLimits
+}
+
+// newWindowSizes returns the configured window sizes, the default window_size first, without duplicates.
+func newWindowSizes(config *configs.Config) ([]models.WindowSize, error) {
+	windowSizes := make([]models.WindowSize, 0, 1+len(config.Aggregation.WindowSizes))
+	for _, value := range append([]string{config.Aggregation.WindowSize}, config.Aggregation.WindowSizes...) {
+		windowSize, err := models.NewWindowSizeFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(windowSizes, windowSize) {
+			windowSizes = append(windowSizes, windowSize)
+		}
+	}
+	return windowSizes, nil
+}
+
+// newRollupWindowSizes returns the window sizes the rollup job builds from the default window size. Each one
+// must be a multiple of the default window size and must not be summarized directly as well, as both would
+// write the same results. Day and week windows start at local midnight, so the default window size must also
+// divide the UTC offset of every customer time zone, or a source window would straddle two local days.
+func newRollupWindowSizes(config *configs.Config, windowSizes []models.WindowSize, timeZones map[string]*time.Location) ([]models.WindowSize, error) {
+	source := windowSizes[0]
+	rollupWindowSizes := make([]models.WindowSize, 0, len(config.Aggregation.Rollup.WindowSizes))
+	for _, value := range config.Aggregation.Rollup.WindowSizes {
+		windowSize, err := models.NewWindowSizeFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(windowSizes, windowSize) {
+			return nil, fmt.Errorf("window size %s is both summarized and rolled up", windowSize)
+		}
+		if source.IsCalendar() || windowSize.Duration() <= source.Duration() || windowSize.Duration()%source.Duration() != 0 {
+			return nil, fmt.Errorf("window size %s is not a multiple of %s", windowSize, source)
+		}
+		if windowSize.IsCalendar() {
+			for customerID, loc := range timeZones {
+				if offset, ok := misalignedOffset(loc, source.Duration()); ok {
+					return nil, fmt.Errorf("window size %s cannot be rolled up from %s for customer %s: UTC offset %s of %s is not a multiple of %s",
+						windowSize, source, customerID, offset, loc, source)
+				}
+			}
+		}
+		if !slices.Contains(rollupWindowSizes, windowSize) {
+			rollupWindowSizes = append(rollupWindowSizes, windowSize)
+		}
+	}
+	return rollupWindowSizes, nil
+}
+
+// misalignedOffset returns a UTC offset loc observes that is not a multiple of d. Offsets are sampled in
+// January and July, covering standard and daylight saving time on either hemisphere.
+func misalignedOffset(loc *time.Location, d time.Duration) (time.Duration, bool) {
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		_, seconds := time.Date(year, month, 1, 0, 0, 0, 0, loc).Zone()
+		if offset := time.Duration(seconds) * time.Second; offset%d != 0 {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// newCustomerTimeZones returns the time zone of every customer that configures one.
+func newCustomerTimeZones(config *configs.Config) (map[string]*time.Location, error) {
+	timeZones := make(map[string]*time.Location)
+	for _, customer := range config.Customers {
+		if customer.TimeZone == "" {
+			continue
+		}
+		loc, err := time.LoadLocation(customer.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		timeZones[customer.ID] = loc
+	}
+	return timeZones, nil
+}
+
+// newCardinalityLimits returns the per-window key limits of summaries and window aggregates.
+func newCardinalityLimits(config *configs.Config) models.CardinalityLimits {
+	return models.CardinalityLimits{
+		MaxPaths:                config.Aggregation.MaxPathsPerWindow,
+		MaxUserAgents:           config.Aggregation.MaxUserAgentsPerWindow,
+		MaxBrowserMajorVersions: config.Aggregation.MaxBrowserMajorVersionsPerWindow,
+		MaxUniqueVisitorPaths:   config.Aggregation.MaxUniqueVisitorPathsPerWindow,
+		MaxBreakdownUserAgents:  config.Aggregation.MaxBreakdownUserAgentsPerPath,
+		MaxDimensionKeys:        config.Aggregation.MaxDimensionKeysPerWindow,
+	}
+}
+
+// newDimensionExtractors creates the extractors of the custom dimensions.
+func newDimensionExtractors(config *configs.Config) ([]ingestors.DimensionExtractor, error) {
+	extractors := make([]ingestors.DimensionExtractor, 0, len(config.Aggregation.Dimensions))
+	for _, dimension := range config.Aggregation.Dimensions {
+		extractor, err := ingestors.NewFieldDimensionExtractor(dimension.Name, dimension.Field)
+		if err != nil {
+			return nil, err
+		}
+		extractors = append(extractors, extractor)
+	}
+	return extractors, nil
+}
+
+// newPathUserAgentBreakdown returns the customers that enable the path by user agent breakdown.
+func newPathUserAgentBreakdown(config *configs.Config) map[string]bool {
+	customers := make(map[string]bool)
+	for _, customer := range config.Customers {
+		if customer.PathUserAgentBreakdown {
+			customers[customer.ID] = true
+		}
+	}
+	return customers
+}
+
+// newCustomerDimensionExtractors creates the extractors of the attributes every customer groups by.
+func newCustomerDimensionExtractors(config *configs.Config) (map[string][]ingestors.DimensionExtractor, error) {
+	customerExtractors := make(map[string][]ingestors.DimensionExtractor)
+	for _, customer := range config.Customers {
+		for _, attribute := range customer.GroupByAttributes {
+			extractor, err := ingestors.NewAttributeDimensionExtractor(attribute)
+			if err != nil {
+				return nil, err
+			}
+			customerExtractors[customer.ID] = append(customerExtractors[customer.ID], extractor)
+		}
+	}
+	return customerExtractors, nil
+}
+
+// newPathNormalizer creates the PathNormalizer with the route templates of every customer that defines some.
+func newPathNormalizer(config *configs.Config) (ingestors.PathNormalizer, error) {
+	customerTemplates := make(map[string][]string)
+	for _, customer := range config.Customers {
+		if len(customer.PathTemplates) > 0 {
+			customerTemplates[customer.ID] = customer.PathTemplates
+		}
+	}
+	return ingestors.NewPathNormalizer(customerTemplates)
+}
+
+// newPartialInsightQueue creates the partial insight queue selected by stream.queue_type. A durable queue
+// reports the records it dead-letters to logger.
+func newPartialInsightQueue(config *configs.Config, logger loggers.Logger) (streams.PartitionedQueue[events.PartialInsightEvent], error) {
+	if config.Stream.QueueType != "durable" {
+		return streams.NewPartitionedQueue[events.PartialInsightEvent](), nil
+	}
+	return streams.NewDurablePartitionedQueue[events.PartialInsightEvent](streams.DurableQueueOptions{
+		Dir:             filepath.Join(config.FileStorage.RootDir, "streams", "partial-insight"),
+		SegmentMaxBytes: config.Stream.SegmentMaxBytes,
+		FsyncPolicy:     streams.FsyncPolicy(config.Stream.FsyncPolicy),
+		FsyncInterval:   time.Duration(config.Stream.FsyncInterval) * time.Second,
+		Logger:          logger,
+	})
+}
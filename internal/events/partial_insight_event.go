@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"time"
 
 	"log-analytics/internal/models"
@@ -21,13 +22,14 @@ import (
 //	  "batchId": "01ARZ3NDEKTSV4RRFFQ69G5FAV",
 //	  "windowStart": "2025-12-28T18:03:00Z",
 //	  "windowSize": "minute",
-//	  "requestsByPath": {
-//	    "GET /": 150,
-//	    "GET /about": 50
-//	  },
-//	  "requestsByUserAgent": {
-//	    "Chrome": 120,
-//	    "Firefox": 80
+//	  "maxReceivedAt": "2025-12-28T18:04:59Z",
+//	  "dimensions": {
+//	    "path": {"GET /": 150, "GET /about": 50},
+//	    "user_agent": {"Chrome": 120, "Firefox": 80},
+//	    "os": {"Windows": 140, "macOS": 60},
+//	    "device_type": {"desktop": 200},
+//	    "browser_major_version": {"Chrome 120": 120, "Firefox 123": 80},
+//	    "bot_or_human": {"human": 200}
 //	  }
 //	}
 //
@@ -39,10 +41,40 @@ import (
 //   - This partial insight will be merged with other partial insights for the same window
 //     to create the final aggregate result for the 18:03 minute window
 type PartialInsightEvent struct {
-	CustomerID          string            `json:"customerId"`
-	BatchID             string            `json:"batchId"`
-	WindowStart         time.Time         `json:"windowStart"`
-	WindowSize          models.WindowSize `json:"windowSize"`
-	RequestsByPath      map[string]int64  `json:"requestsByPath"`
-	RequestsByUserAgent map[string]int64  `json:"requestsByUserAgent"`
+	CustomerID    string            `json:"customerId"`
+	BatchID       string            `json:"batchId"`
+	WindowStart   time.Time         `json:"windowStart"`
+	WindowSize    models.WindowSize `json:"windowSize"`
+	MaxReceivedAt time.Time         `json:"maxReceivedAt"` // latest entry of the whole batch, advances the watermark
+
+	// Counts of the window in the batch, see models.WindowAggregates
+	models.WindowAggregates
+}
+
+// NewPartialInsightEvent creates the partial insight of batchSummary for its window starting at windowStart.
+// The event shares the maps and sketches of windowAggregates.
+func NewPartialInsightEvent(batchSummary *models.BatchSummary, windowStart time.Time, windowAggregates models.WindowAggregates) *PartialInsightEvent {
+	return &PartialInsightEvent{
+		CustomerID:       batchSummary.CustomerID,
+		BatchID:          batchSummary.BatchID,
+		WindowStart:      windowStart,
+		WindowSize:       batchSummary.WindowSize,
+		MaxReceivedAt:    batchSummary.MaxReceivedAt,
+		WindowAggregates: windowAggregates,
+	}
+}
+
+// UnmarshalJSON also reads the built-in dimensions of events queued or stored before they moved into
+// Dimensions, see models.LegacyDimensions.
+func (e *PartialInsightEvent) UnmarshalJSON(data []byte) error {
+	type plain PartialInsightEvent
+	wire := struct {
+		*plain
+		models.LegacyDimensions
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	e.Dimensions = wire.LegacyDimensions.AddTo(e.Dimensions)
+	return nil
 }
@@ -70,3 +70,10 @@ func writeErrorResponse(w http.ResponseWriter, r *http.Request, svcErr *svcerror
 
 	_ = json.NewEncoder(w).Encode(errorResponse)
 }
+
+func writeJSONResponse(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
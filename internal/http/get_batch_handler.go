@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"log-analytics/internal/ingestors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BatchStatusResponse represents the status of a stored log batch.
+type BatchStatusResponse struct {
+	BatchID    string               `json:"batchId"`
+	CustomerID string               `json:"customerId"`
+	IngestedAt time.Time            `json:"ingestedAt"`
+	EntryCount int                  `json:"entryCount"`
+	State      ingestors.BatchState `json:"state"`
+	Entries    []LogEntryResponse   `json:"entries,omitempty"`
+}
+
+// LogEntryResponse represents a raw log entry, in the same shape it was ingested.
+type LogEntryResponse struct {
+	ReceivedAt time.Time `json:"receivedAt"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	UserAgent  string    `json:"userAgent"`
+
+	Status        int               `json:"status,omitempty"`
+	DurationMs    *float64          `json:"durationMs,omitempty"`
+	ResponseBytes int64             `json:"responseBytes,omitempty"`
+	ClientIP      string            `json:"clientIp,omitempty"`
+	Host          string            `json:"host,omitempty"`
+	UserID        string            `json:"userId,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+}
+
+type getBatchHandler struct {
+	batchStatusService ingestors.BatchStatusService
+}
+
+func NewGetBatchHandler(batchStatusService ingestors.BatchStatusService) AppHttpHandler {
+	return &getBatchHandler{
+		batchStatusService: batchStatusService,
+	}
+}
+
+// Handle processes GET /batches/{id} requests. Raw entries are returned with ?includeEntries=true.
+func (h *getBatchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	status, err := h.batchStatusService.GetBatchStatus(r.Context(), ingestors.BatchStatusQuery{
+		CustomerID:     customerID(r),
+		BatchID:        chi.URLParam(r, "id"),
+		IncludeEntries: r.URL.Query().Get("includeEntries"),
+	})
+	if err != nil {
+		return err
+	}
+
+	var entries []LogEntryResponse
+	if status.Entries != nil {
+		entries = make([]LogEntryResponse, 0, len(status.Entries))
+		for _, entry := range status.Entries {
+			entries = append(entries, LogEntryResponse{
+				ReceivedAt: entry.ReceivedAt,
+				Method:     entry.Method,
+				Path:       entry.Path,
+				UserAgent:  entry.UserAgent,
+
+				Status:        entry.Status,
+				DurationMs:    entry.DurationMs,
+				ResponseBytes: entry.ResponseBytes,
+				ClientIP:      entry.ClientIP,
+				Host:          entry.Host,
+				UserID:        entry.UserID,
+				Attributes:    entry.Attributes,
+			})
+		}
+	}
+
+	writeJSONResponse(w, http.StatusOK, BatchStatusResponse{
+		BatchID:    status.BatchID,
+		CustomerID: status.CustomerID,
+		IngestedAt: status.IngestedAt,
+		EntryCount: status.EntryCount,
+		State:      status.State,
+		Entries:    entries,
+	})
+	return nil
+}
+
+// batchLocation is the URL of the status endpoint of a batch.
+func batchLocation(batchID string) string {
+	return "/batches/" + url.PathEscape(batchID)
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"log-analytics/internal/ingestors"
+	ingestormocks "log-analytics/internal/ingestors/mocks"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/svcerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetBatchHandler_Handle_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBatchStatusService := ingestormocks.NewMockBatchStatusService(ctrl)
+	handler := NewGetBatchHandler(mockBatchStatusService)
+
+	ingestedAt := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	receivedAt := time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC)
+	mockBatchStatusService.EXPECT().
+		GetBatchStatus(gomock.Any(), ingestors.BatchStatusQuery{
+			CustomerID:     "cus-axon",
+			BatchID:        "batch-123",
+			IncludeEntries: "true",
+		}).
+		Return(&ingestors.BatchStatus{
+			BatchID:    "batch-123",
+			CustomerID: "cus-axon",
+			IngestedAt: ingestedAt,
+			EntryCount: 1,
+			State:      ingestors.BatchStateAggregated,
+			Entries: []*models.LogEntry{
+				{ReceivedAt: receivedAt, Method: "GET", Path: "/", UserAgent: "Chrome"},
+			},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/batches/batch-123?includeEntries=true", nil)
+	req.Header.Set(headerCustomerID, "cus-axon")
+	req = withURLParam(req, "id", "batch-123")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response BatchStatusResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "batch-123", response.BatchID)
+	assert.Equal(t, "cus-axon", response.CustomerID)
+	assert.True(t, ingestedAt.Equal(response.IngestedAt))
+	assert.Equal(t, 1, response.EntryCount)
+	assert.Equal(t, ingestors.BatchStateAggregated, response.State)
+	require.Len(t, response.Entries, 1)
+	assert.True(t, receivedAt.Equal(response.Entries[0].ReceivedAt))
+	assert.Equal(t, "GET", response.Entries[0].Method)
+	assert.Equal(t, "/", response.Entries[0].Path)
+	assert.Equal(t, "Chrome", response.Entries[0].UserAgent)
+}
+
+func TestGetBatchHandler_Handle_Error(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBatchStatusService := ingestormocks.NewMockBatchStatusService(ctrl)
+	handler := NewGetBatchHandler(mockBatchStatusService)
+
+	expectedErr := svcerrors.NewNotFoundError("TEST_1007", "batch not found", nil)
+	mockBatchStatusService.EXPECT().
+		GetBatchStatus(gomock.Any(), gomock.Any()).
+		Return(nil, expectedErr)
+
+	req := httptest.NewRequest(http.MethodGet, "/batches/batch-123", nil)
+	req.Header.Set(headerCustomerID, "cus-axon")
+	req = withURLParam(req, "id", "batch-123")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.Error(t, err)
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok)
+	assert.Equal(t, "TEST_1007", svcErr.Code)
+}
@@ -6,10 +6,13 @@ import (
 )
 
 const (
-	headerRequestID      = "x-request-id"
-	headerContentType    = "content-type"
-	headerIdempotencyKey = "idempotency-key"
-	headerCustomerID     = "x-customer-id"
+	headerRequestID       = "x-request-id"
+	headerContentType     = "content-type"
+	headerContentEncoding = "content-encoding"
+	headerIdempotencyKey  = "idempotency-key"
+	headerCustomerID      = "x-customer-id"
+	headerLocation        = "location"
+	headerIngestionMode   = "x-ingestion-mode"
 )
 
 func requestID(r *http.Request) string {
@@ -24,6 +27,10 @@ func contentType(r *http.Request) string {
 	return strings.TrimSpace(r.Header.Get(headerContentType))
 }
 
+func contentEncoding(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(headerContentEncoding))
+}
+
 func idempotencyKey(r *http.Request) string {
 	return strings.TrimSpace(r.Header.Get(headerIdempotencyKey))
 }
@@ -31,3 +38,7 @@ func idempotencyKey(r *http.Request) string {
 func customerID(r *http.Request) string {
 	return strings.TrimSpace(r.Header.Get(headerCustomerID))
 }
+
+func ingestionMode(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(headerIngestionMode))
+}
@@ -2,13 +2,33 @@ package http
 
 import (
 	"log-analytics/internal/ingestors"
+	"log-analytics/internal/models"
 	"net/http"
+	"time"
 )
 
 type AppHttpHandler interface {
 	Handle(w http.ResponseWriter, r *http.Request) error
 }
 
+// IngestLogResponse represents an accepted log batch.
+type IngestLogResponse struct {
+	BatchID       string            `json:"batchId"`
+	AcceptedCount int               `json:"acceptedCount"`
+	WindowSize    models.WindowSize `json:"windowSize"`
+	Windows       []time.Time       `json:"windows"`
+	RejectedCount int               `json:"rejectedCount"`
+	Rejected      []RejectedEntry   `json:"rejected,omitempty"`
+}
+
+// RejectedEntry reports a log entry dropped from a partially accepted batch.
+type RejectedEntry struct {
+	Index   int    `json:"index"`
+	Reason  string `json:"reason"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type ingestLogHandler struct {
 	ingestionService ingestors.IngestionService
 }
@@ -21,11 +41,33 @@ func NewIngestLogHandler(ingestionService ingestors.IngestionService) AppHttpHan
 
 // Handle HandleLogs processes POST /logs requests.
 func (h *ingestLogHandler) Handle(w http.ResponseWriter, r *http.Request) error {
-	_, err := h.ingestionService.IngestBatch(r.Context(), customerID(r), idempotencyKey(r), contentType(r), r.Body)
+	result, err := h.ingestionService.IngestBatch(r.Context(), customerID(r), idempotencyKey(r), contentType(r), contentEncoding(r), ingestionMode(r), r.Body)
 	if err != nil {
 		return err
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	windows := result.Windows
+	if windows == nil {
+		windows = []time.Time{}
+	}
+	rejected := make([]RejectedEntry, 0, len(result.Rejected))
+	for _, rejectedEntry := range result.Rejected {
+		rejected = append(rejected, RejectedEntry{
+			Index:   rejectedEntry.Index,
+			Reason:  rejectedEntry.Reason,
+			Code:    rejectedEntry.Code,
+			Message: rejectedEntry.Message,
+		})
+	}
+
+	w.Header().Set(headerLocation, batchLocation(result.BatchID))
+	writeJSONResponse(w, http.StatusAccepted, IngestLogResponse{
+		BatchID:       result.BatchID,
+		AcceptedCount: result.StoredCount,
+		WindowSize:    result.WindowSize,
+		Windows:       windows,
+		RejectedCount: len(rejected),
+		Rejected:      rejected,
+	})
 	return nil
 }
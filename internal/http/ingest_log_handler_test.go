@@ -2,13 +2,18 @@ package http
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"log-analytics/internal/ingestors"
 	ingestormocks "log-analytics/internal/ingestors/mocks"
+	"log-analytics/internal/models"
 	"log-analytics/internal/shared/svcerrors"
+	storemocks "log-analytics/internal/stores/mocks"
+	streammocks "log-analytics/internal/streams/mocks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,22 +33,47 @@ func TestIngestLogHandler_Handle_Success(t *testing.T) {
 	req.Header.Set(headerCustomerID, "customer123")
 	req.Header.Set(headerIdempotencyKey, "key123")
 	req.Header.Set(headerContentType, "application/json")
+	req.Header.Set(headerIngestionMode, "partial")
 	rr := httptest.NewRecorder()
 
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
 	mockIngestionService.EXPECT().
 		IngestBatch(
 			gomock.Any(),
 			"customer123",
 			"key123",
 			"application/json",
+			"",
+			"partial",
 			gomock.Any(),
 		).
-		Return(&ingestors.IngestResult{}, nil)
+		Return(&ingestors.IngestResult{
+			BatchID:     "key123",
+			StoredCount: 2,
+			WindowSize:  models.WindowMinute,
+			Windows:     []time.Time{windowStart},
+			Rejected: []*models.RejectedEntry{
+				{Index: 1, Reason: "invalid_entry", Code: "ING_1000", Message: "item at index 1: missing path", Raw: `{}`},
+			},
+		}, nil)
 
 	err := handler.Handle(rr, req)
 
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, "/batches/key123", rr.Header().Get(headerLocation))
+
+	var response IngestLogResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "key123", response.BatchID)
+	assert.Equal(t, 2, response.AcceptedCount)
+	assert.Equal(t, models.WindowMinute, response.WindowSize)
+	require.Len(t, response.Windows, 1)
+	assert.True(t, windowStart.Equal(response.Windows[0]))
+	assert.Equal(t, 1, response.RejectedCount)
+	assert.Equal(t, []RejectedEntry{
+		{Index: 1, Reason: "invalid_entry", Code: "ING_1000", Message: "item at index 1: missing path"},
+	}, response.Rejected)
 }
 
 func TestIngestLogHandler_Handle_Error(t *testing.T) {
@@ -68,6 +98,8 @@ func TestIngestLogHandler_Handle_Error(t *testing.T) {
 			"customer123",
 			"key123",
 			"application/json",
+			"",
+			"",
 			gomock.Any(),
 		).
 		Return(nil, expectedErr)
@@ -81,3 +113,31 @@ func TestIngestLogHandler_Handle_Error(t *testing.T) {
 	// Status should not be set when error occurs
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
+
+func TestIngestLogHandler_Handle_InvalidIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The key would become a batch ID outside the customer's keys, so nothing may be stored
+	ingestionService := ingestors.NewIngestionService(ingestormocks.NewMockBatchSummarizer(ctrl), storemocks.NewMockLogBatchStore(ctrl),
+		storemocks.NewMockOutboxStore(ctrl), storemocks.NewMockRejectedEntryStore(ctrl), streammocks.NewMockPartialInsightProducer(ctrl),
+		time.Minute, ingestors.DefaultIngestionLimits(), nil)
+	handler := errorHandlingAdapter(NewIngestLogHandler(ingestionService))
+
+	body := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
+	req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader([]byte(body)))
+	req.Header.Set(headerCustomerID, "customer123")
+	req.Header.Set(headerIdempotencyKey, "../customer456/key123")
+	req.Header.Set(headerContentType, "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Empty(t, rr.Header().Get(headerLocation))
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "ING_1000", response.ErrorCode)
+}
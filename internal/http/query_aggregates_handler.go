@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/sketches"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// QueryAggregatesResponse represents a page of window aggregate results.
+type QueryAggregatesResponse struct {
+	CustomerID string                     `json:"customerId"`
+	WindowSize models.WindowSize          `json:"windowSize"`
+	From       time.Time                  `json:"from"`
+	To         time.Time                  `json:"to"`
+	Items      []*WindowAggregateResponse `json:"items"`
+	NextCursor string                     `json:"nextCursor,omitempty"`
+}
+
+// WindowAggregateResponse represents one window aggregate result. Built-in dimensions keep their own fields,
+// e.g. requestsByPath, attribute dimensions are rendered per attribute in requestsByAttribute, and dimensions
+// holds the custom ones only. countErrors holds the errors of the capped counts of every dimension by name,
+// e.g. countErrors.path, see models.CapCounts. The batches a window applied are deduplication state and are
+// not exposed.
+type WindowAggregateResponse struct {
+	CustomerID  string            `json:"customerId"`
+	WindowStart time.Time         `json:"windowStart"`
+	WindowSize  models.WindowSize `json:"windowSize"`
+	models.LegacyDimensions
+	BytesSum                   int64                            `json:"bytesSum"`
+	LatencyByPath              map[string]*models.LatencyStats  `json:"latencyByPath"`
+	UniqueVisitors             *sketches.HyperLogLog            `json:"uniqueVisitors,omitempty"`
+	UniqueVisitorsByPath       map[string]*sketches.HyperLogLog `json:"uniqueVisitorsByPath,omitempty"`
+	RequestsByPathAndUserAgent map[string]map[string]int64      `json:"requestsByPathAndUserAgent,omitempty"`
+	Dimensions                 map[string]map[string]int64      `json:"dimensions,omitempty"`
+	CountErrors                map[string]map[string]int64      `json:"countErrors,omitempty"`
+	Finalized                  bool                             `json:"finalized,omitempty"`
+	FinalizedAt                time.Time                        `json:"finalizedAt,omitzero"`
+	Revision                   int                              `json:"revision,omitempty"`
+}
+
+func newWindowAggregateResponse(item *models.WindowAggregateResult) *WindowAggregateResponse {
+	var dimensions map[string]map[string]int64
+	for name, counts := range item.Dimensions {
+		if models.IsBuiltinDimension(name) || models.IsAttributeDimension(name) {
+			continue
+		}
+		if dimensions == nil {
+			dimensions = make(map[string]map[string]int64)
+		}
+		dimensions[name] = counts
+	}
+	return &WindowAggregateResponse{
+		CustomerID:                 item.CustomerID,
+		WindowStart:                item.WindowStart,
+		WindowSize:                 item.WindowSize,
+		LegacyDimensions:           models.NewLegacyDimensions(item.Dimensions),
+		BytesSum:                   item.BytesSum,
+		LatencyByPath:              item.LatencyByPath,
+		UniqueVisitors:             item.UniqueVisitors,
+		UniqueVisitorsByPath:       item.UniqueVisitorsByPath,
+		RequestsByPathAndUserAgent: item.RequestsByPathAndUserAgent,
+		Dimensions:                 dimensions,
+		CountErrors:                item.CountErrors,
+		Finalized:                  item.Finalized,
+		FinalizedAt:                item.FinalizedAt,
+		Revision:                   item.Revision,
+	}
+}
+
+type queryAggregatesHandler struct {
+	aggregateQueryService aggregators.AggregateQueryService
+}
+
+func NewQueryAggregatesHandler(aggregateQueryService aggregators.AggregateQueryService) AppHttpHandler {
+	return &queryAggregatesHandler{
+		aggregateQueryService: aggregateQueryService,
+	}
+}
+
+// Handle processes GET /customers/{id}/aggregates requests.
+func (h *queryAggregatesHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+	page, err := h.aggregateQueryService.QueryAggregates(r.Context(), aggregators.AggregateQuery{
+		CustomerID: chi.URLParam(r, "id"),
+		WindowSize: params.Get("windowSize"),
+		From:       params.Get("from"),
+		To:         params.Get("to"),
+		Cursor:     params.Get("cursor"),
+		Limit:      params.Get("limit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	items := make([]*WindowAggregateResponse, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, newWindowAggregateResponse(item))
+	}
+	writeJSONResponse(w, http.StatusOK, QueryAggregatesResponse{
+		CustomerID: page.CustomerID,
+		WindowSize: page.WindowSize,
+		From:       page.From,
+		To:         page.To,
+		Items:      items,
+		NextCursor: page.NextCursor,
+	})
+	return nil
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/svcerrors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQueryAggregatesHandler_Handle_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQueryService := aggregatormocks.NewMockAggregateQueryService(ctrl)
+	handler := NewQueryAggregatesHandler(mockQueryService)
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	item := models.NewEmptyWindowAggregateResult("cus-axon", from.Add(3*time.Minute), models.WindowMinute)
+	item.Counts(models.DimensionPath)["GET /"] = 10
+	item.Counts("host")["api.example.com"] = 10
+	item.Counts("attribute.region")["eu-west-1"] = 10
+	item.MarkBatchApplied("batch-1")
+
+	mockQueryService.EXPECT().
+		QueryAggregates(gomock.Any(), aggregators.AggregateQuery{
+			CustomerID: "cus-axon",
+			WindowSize: "minute",
+			From:       "2025-12-28T18:00:00Z",
+			To:         "2025-12-28T19:00:00Z",
+			Cursor:     "",
+			Limit:      "1",
+		}).
+		Return(&aggregators.AggregatePage{
+			CustomerID: "cus-axon",
+			WindowSize: models.WindowMinute,
+			From:       from,
+			To:         to,
+			Items:      []*models.WindowAggregateResult{item},
+			NextCursor: "2025-12-28T18:04:00Z",
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/customers/cus-axon/aggregates?windowSize=minute&from=2025-12-28T18:00:00Z&to=2025-12-28T19:00:00Z&limit=1", nil)
+	req = withURLParam(req, "id", "cus-axon")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	// Applied batches stay internal
+	assert.NotContains(t, rr.Body.String(), "appliedBatch")
+
+	var response QueryAggregatesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "cus-axon", response.CustomerID)
+	assert.Equal(t, models.WindowMinute, response.WindowSize)
+	assert.Equal(t, "2025-12-28T18:04:00Z", response.NextCursor)
+	require.Len(t, response.Items, 1)
+	// Built-in and attribute dimensions keep their own fields, dimensions holds the custom ones
+	assert.Equal(t, map[string]int64{"GET /": 10}, response.Items[0].RequestsByPath)
+	assert.Equal(t, map[string]map[string]int64{"region": {"eu-west-1": 10}}, response.Items[0].RequestsByAttribute)
+	assert.Equal(t, map[string]map[string]int64{"host": {"api.example.com": 10}}, response.Items[0].Dimensions)
+}
+
+func TestQueryAggregatesHandler_Handle_Error(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQueryService := aggregatormocks.NewMockAggregateQueryService(ctrl)
+	handler := NewQueryAggregatesHandler(mockQueryService)
+
+	expectedErr := svcerrors.NewInvalidArgumentError("TEST_1001", "from must be before to", nil)
+	mockQueryService.EXPECT().
+		QueryAggregates(gomock.Any(), gomock.Any()).
+		Return(nil, expectedErr)
+
+	req := httptest.NewRequest(http.MethodGet, "/customers/cus-axon/aggregates", nil)
+	req = withURLParam(req, "id", "cus-axon")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.Error(t, err)
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok)
+	assert.Equal(t, "TEST_1001", svcErr.Code)
+}
+
+// withURLParam attaches a chi route parameter to the request, as the router would.
+func withURLParam(r *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// QueryBreakdownResponse represents one slice of the path by user agent breakdown over a time range. Counts
+// is keyed by user agent when path is set and by path when userAgent is set.
+type QueryBreakdownResponse struct {
+	CustomerID string            `json:"customerId"`
+	WindowSize models.WindowSize `json:"windowSize"`
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	Windows    int               `json:"windows"`
+	Path       string            `json:"path,omitempty"`
+	UserAgent  string            `json:"userAgent,omitempty"`
+	Counts     map[string]int64  `json:"counts"`
+}
+
+type queryBreakdownHandler struct {
+	aggregateQueryService aggregators.AggregateQueryService
+}
+
+func NewQueryBreakdownHandler(aggregateQueryService aggregators.AggregateQueryService) AppHttpHandler {
+	return &queryBreakdownHandler{
+		aggregateQueryService: aggregateQueryService,
+	}
+}
+
+// Handle processes GET /customers/{id}/breakdown requests.
+func (h *queryBreakdownHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+	report, err := h.aggregateQueryService.QueryBreakdown(r.Context(), aggregators.BreakdownQuery{
+		CustomerID: chi.URLParam(r, "id"),
+		WindowSize: params.Get("windowSize"),
+		From:       params.Get("from"),
+		To:         params.Get("to"),
+		Path:       params.Get("path"),
+		UserAgent:  params.Get("userAgent"),
+	})
+	if err != nil {
+		return err
+	}
+
+	writeJSONResponse(w, http.StatusOK, QueryBreakdownResponse{
+		CustomerID: report.CustomerID,
+		WindowSize: report.WindowSize,
+		From:       report.From,
+		To:         report.To,
+		Windows:    report.Windows,
+		Path:       report.Path,
+		UserAgent:  report.UserAgent,
+		Counts:     report.Counts,
+	})
+	return nil
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQueryBreakdownHandler_Handle_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQueryService := aggregatormocks.NewMockAggregateQueryService(ctrl)
+	handler := NewQueryBreakdownHandler(mockQueryService)
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	mockQueryService.EXPECT().
+		QueryBreakdown(gomock.Any(), aggregators.BreakdownQuery{
+			CustomerID: "cus-axon",
+			From:       "2025-12-28T18:00:00Z",
+			To:         "2025-12-28T19:00:00Z",
+			Path:       "POST /checkout",
+		}).
+		Return(&aggregators.BreakdownReport{
+			CustomerID: "cus-axon",
+			WindowSize: models.WindowMinute,
+			From:       from,
+			To:         from.Add(time.Hour),
+			Windows:    2,
+			Path:       "POST /checkout",
+			Counts:     map[string]int64{"Chrome": 5, "Firefox": 4},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/customers/cus-axon/breakdown?from=2025-12-28T18:00:00Z&to=2025-12-28T19:00:00Z&path=POST+/checkout", nil)
+	req = withURLParam(req, "id", "cus-axon")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response QueryBreakdownResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Windows)
+	assert.Equal(t, "POST /checkout", response.Path)
+	assert.Empty(t, response.UserAgent)
+	assert.Equal(t, map[string]int64{"Chrome": 5, "Firefox": 4}, response.Counts)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// QueryLatencyResponse represents the latency of every path over a time range.
+type QueryLatencyResponse struct {
+	CustomerID string                          `json:"customerId"`
+	WindowSize models.WindowSize               `json:"windowSize"`
+	From       time.Time                       `json:"from"`
+	To         time.Time                       `json:"to"`
+	Windows    int                             `json:"windows"`
+	Paths      map[string]*PathLatencyResponse `json:"paths"`
+}
+
+// PathLatencyResponse represents the latency of one path, quantiles are keyed by name, e.g. "p95".
+type PathLatencyResponse struct {
+	Count     int64              `json:"count"`
+	AvgMs     float64            `json:"avgMs"`
+	MinMs     float64            `json:"minMs"`
+	MaxMs     float64            `json:"maxMs"`
+	Quantiles map[string]float64 `json:"quantiles"`
+}
+
+type queryLatencyHandler struct {
+	aggregateQueryService aggregators.AggregateQueryService
+}
+
+func NewQueryLatencyHandler(aggregateQueryService aggregators.AggregateQueryService) AppHttpHandler {
+	return &queryLatencyHandler{
+		aggregateQueryService: aggregateQueryService,
+	}
+}
+
+// Handle processes GET /customers/{id}/latency requests.
+func (h *queryLatencyHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+	report, err := h.aggregateQueryService.QueryLatency(r.Context(), aggregators.LatencyQuery{
+		CustomerID: chi.URLParam(r, "id"),
+		WindowSize: params.Get("windowSize"),
+		From:       params.Get("from"),
+		To:         params.Get("to"),
+		Path:       params.Get("path"),
+		Quantiles:  params.Get("quantiles"),
+	})
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]*PathLatencyResponse, len(report.Paths))
+	for path, latency := range report.Paths {
+		paths[path] = &PathLatencyResponse{
+			Count:     latency.Count,
+			AvgMs:     latency.AvgMs,
+			MinMs:     latency.MinMs,
+			MaxMs:     latency.MaxMs,
+			Quantiles: latency.Quantiles,
+		}
+	}
+	writeJSONResponse(w, http.StatusOK, QueryLatencyResponse{
+		CustomerID: report.CustomerID,
+		WindowSize: report.WindowSize,
+		From:       report.From,
+		To:         report.To,
+		Windows:    report.Windows,
+		Paths:      paths,
+	})
+	return nil
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQueryLatencyHandler_Handle_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQueryService := aggregatormocks.NewMockAggregateQueryService(ctrl)
+	handler := NewQueryLatencyHandler(mockQueryService)
+
+	from := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	mockQueryService.EXPECT().
+		QueryLatency(gomock.Any(), aggregators.LatencyQuery{
+			CustomerID: "cus-axon",
+			From:       "2025-12-28T18:00:00Z",
+			To:         "2025-12-28T19:00:00Z",
+			Path:       "GET /",
+			Quantiles:  "0.99",
+		}).
+		Return(&aggregators.LatencyReport{
+			CustomerID: "cus-axon",
+			WindowSize: models.WindowMinute,
+			From:       from,
+			To:         from.Add(time.Hour),
+			Windows:    2,
+			Paths: map[string]*aggregators.PathLatency{
+				"GET /": {Count: 10, AvgMs: 12, MinMs: 1, MaxMs: 40, Quantiles: map[string]float64{"p99": 39.8}},
+			},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/customers/cus-axon/latency?from=2025-12-28T18:00:00Z&to=2025-12-28T19:00:00Z&path=GET+/&quantiles=0.99", nil)
+	req = withURLParam(req, "id", "cus-axon")
+	rr := httptest.NewRecorder()
+
+	err := handler.Handle(rr, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response QueryLatencyResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Windows)
+	require.Contains(t, response.Paths, "GET /")
+	assert.Equal(t, int64(10), response.Paths["GET /"].Count)
+	assert.Equal(t, 39.8, response.Paths["GET /"].Quantiles["p99"])
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 
+	"log-analytics/internal/aggregators"
 	"log-analytics/internal/ingestors"
 	"log-analytics/internal/shared/loggers"
 	"log-analytics/internal/shared/metrics"
@@ -11,15 +12,23 @@ import (
 )
 
 // NewRouter creates and configures the HTTP router.
-func NewRouter(ingestionService ingestors.IngestionService, httpLogger loggers.Logger) http.Handler {
+func NewRouter(ingestionService ingestors.IngestionService, batchStatusService ingestors.BatchStatusService, aggregateQueryService aggregators.AggregateQueryService, httpLogger loggers.Logger) http.Handler {
 	router := chi.NewRouter()
 	setupMiddleware(router, httpLogger)
 
 	// Initialize handlers
 	ingestLogHandler := NewIngestLogHandler(ingestionService)
+	getBatchHandler := NewGetBatchHandler(batchStatusService)
+	queryAggregatesHandler := NewQueryAggregatesHandler(aggregateQueryService)
+	queryLatencyHandler := NewQueryLatencyHandler(aggregateQueryService)
+	queryBreakdownHandler := NewQueryBreakdownHandler(aggregateQueryService)
 
 	// Routes
 	router.Post("/logs", errorHandlingAdapter(ingestLogHandler))
+	router.Get("/batches/{id}", errorHandlingAdapter(getBatchHandler))
+	router.Get("/customers/{id}/aggregates", errorHandlingAdapter(queryAggregatesHandler))
+	router.Get("/customers/{id}/latency", errorHandlingAdapter(queryLatencyHandler))
+	router.Get("/customers/{id}/breakdown", errorHandlingAdapter(queryBreakdownHandler))
 	router.Get("/metrics", metrics.PromHTTP.Handler().ServeHTTP)
 
 	return router
@@ -0,0 +1,37 @@
+package ingestors
+
+import (
+	"context"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/stores"
+	"log-analytics/internal/streams"
+)
+
+// batchPublisher summarizes a stored log batch into every configured window size, produces the partial
+// insights of each and acknowledges them in the outbox. It is shared by the ingestion request path and the outbox relay so both publish a
+// batch exactly the same way.
+type batchPublisher struct {
+	batchSummarizer        BatchSummarizer
+	outboxStore            stores.OutboxStore
+	partialInsightProducer streams.PartialInsightProducer
+}
+
+func (p *batchPublisher) publish(ctx context.Context, logBatch *models.LogBatch) ([]*models.BatchSummary, error) {
+	batchSummaries := p.batchSummarizer.Summarize(logBatch)
+
+	// The batch is only acknowledged once every resolution is produced; a retry reproduces all of them
+	// and the aggregators skip the windows that already applied the batch.
+	for _, batchSummary := range batchSummaries {
+		err := p.partialInsightProducer.Produce(ctx, batchSummary)
+		if err != nil {
+			return nil, errInternalPartialInsightPublisherFailed(err)
+		}
+	}
+
+	err := p.outboxStore.MarkPublished(ctx, logBatch.CustomerID, logBatch.BatchID)
+	if err != nil {
+		return nil, errInternalOutboxStoreFailed(err)
+	}
+	return batchSummaries, nil
+}
@@ -0,0 +1,160 @@
+package ingestors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/stores"
+)
+
+// BatchState is the processing state of a stored log batch.
+type BatchState string
+
+const (
+	// BatchStateStored means the raw batch is stored but its partial insights are not acknowledged yet.
+	BatchStateStored BatchState = "stored"
+	// BatchStatePublished means the partial insights are produced but not applied to every window yet.
+	BatchStatePublished BatchState = "published"
+	// BatchStateAggregated means the batch is applied to the aggregate results of every window it touches.
+	BatchStateAggregated BatchState = "aggregated"
+)
+
+// BatchStatusQuery holds the raw parameters of a batch status read, as received from the client.
+type BatchStatusQuery struct {
+	CustomerID     string
+	BatchID        string
+	IncludeEntries string // optional, "true" to return the raw log entries
+}
+
+// BatchStatus describes a stored log batch.
+type BatchStatus struct {
+	BatchID    string
+	CustomerID string
+	IngestedAt time.Time
+	EntryCount int
+	State      BatchState
+	Entries    []*models.LogEntry // only set when requested
+}
+
+//go:generate mockgen -source=batch_status_service.go -destination=./mocks/batch_status_service_mock.go -package=mocks
+type BatchStatusService interface {
+	// GetBatchStatus returns the receipt and processing state of a log batch ingested by the customer.
+	GetBatchStatus(ctx context.Context, query BatchStatusQuery) (*BatchStatus, error)
+}
+
+type batchStatusService struct {
+	batchStore           stores.LogBatchStore
+	outboxStore          stores.OutboxStore
+	aggregateResultStore stores.AggregateResultStore
+	windowSizes          []models.WindowSize
+	timeZones            map[string]*time.Location
+}
+
+func NewBatchStatusService(batchStore stores.LogBatchStore, outboxStore stores.OutboxStore, aggregateResultStore stores.AggregateResultStore, windowSizes []models.WindowSize, timeZones map[string]*time.Location) BatchStatusService {
+	return &batchStatusService{
+		batchStore:           batchStore,
+		outboxStore:          outboxStore,
+		aggregateResultStore: aggregateResultStore,
+		windowSizes:          windowSizes,
+		timeZones:            timeZones,
+	}
+}
+
+func (s *batchStatusService) GetBatchStatus(ctx context.Context, query BatchStatusQuery) (*BatchStatus, error) {
+	customerID := query.CustomerID
+	if customerID == "" {
+		return nil, errValidationFailed("customerID is required", nil)
+	}
+	batchID := strings.TrimSpace(query.BatchID)
+	if !models.IsKeySegmentID(batchID) {
+		return nil, errValidationFailed("batchID is invalid", nil)
+	}
+	includeEntries := false
+	if query.IncludeEntries != "" {
+		parsed, err := strconv.ParseBool(query.IncludeEntries)
+		if err != nil {
+			return nil, errValidationFailed("includeEntries must be a boolean", err)
+		}
+		includeEntries = parsed
+	}
+
+	// Unknown batch IDs are answered without reading and decoding a raw batch
+	exists, err := s.batchStore.Exists(ctx, customerID, batchID)
+	if err != nil {
+		return nil, errInternalLogBatchStoreFailed(err)
+	}
+	if !exists {
+		return nil, errLogBatchNotFound(stores.ErrLogBatchNotFound)
+	}
+
+	logBatch, err := s.batchStore.Get(ctx, customerID, batchID)
+	if err != nil {
+		if errors.Is(err, stores.ErrLogBatchNotFound) {
+			return nil, errLogBatchNotFound(err)
+		}
+		return nil, errInternalLogBatchStoreFailed(err)
+	}
+
+	state, err := s.getBatchState(ctx, logBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BatchStatus{
+		BatchID:    logBatch.BatchID,
+		CustomerID: logBatch.CustomerID,
+		IngestedAt: logBatch.IngestedAt,
+		EntryCount: len(logBatch.Entries),
+		State:      state,
+	}
+	if includeEntries {
+		status.Entries = logBatch.Entries
+	}
+	return status, nil
+}
+
+func (s *batchStatusService) getBatchState(ctx context.Context, logBatch *models.LogBatch) (BatchState, error) {
+	published, err := s.outboxStore.IsPublished(ctx, logBatch.CustomerID, logBatch.BatchID)
+	if err != nil {
+		return "", errInternalOutboxStoreFailed(err)
+	}
+	if !published {
+		return BatchStateStored, nil
+	}
+
+	// A window records every batch applied to it, so the batch is aggregated once all of its windows
+	// list it, in every configured window size
+	for _, windowSize := range s.windowSizes {
+		for _, windowStart := range s.windowStarts(logBatch, windowSize) {
+			aggregateResult, err := s.aggregateResultStore.Get(ctx, logBatch.CustomerID, windowStart, windowSize)
+			if err != nil {
+				return "", errInternalAggregateResultStoreFailed(err)
+			}
+			if !aggregateResult.HasAppliedBatch(logBatch.BatchID) {
+				return BatchStatePublished, nil
+			}
+		}
+	}
+	return BatchStateAggregated, nil
+}
+
+// windowStarts returns the distinct windows of windowSize touched by the batch, the same way the batch summarizer groups entries.
+func (s *batchStatusService) windowStarts(logBatch *models.LogBatch, windowSize models.WindowSize) []time.Time {
+	seen := make(map[time.Time]struct{})
+	windowStarts := make([]time.Time, 0)
+	for _, entry := range logBatch.Entries {
+		windowStart := windowSize.Truncate(entry.ReceivedAt, s.timeZones[logBatch.CustomerID])
+		if _, ok := seen[windowStart]; ok {
+			continue
+		}
+		seen[windowStart] = struct{}{}
+		windowStarts = append(windowStarts, windowStart)
+	}
+	sort.Slice(windowStarts, func(i, j int) bool { return windowStarts[i].Before(windowStarts[j]) })
+	return windowStarts
+}
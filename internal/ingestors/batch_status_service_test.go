@@ -0,0 +1,289 @@
+package ingestors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"log-analytics/internal/ingestors"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/svcerrors"
+	"log-analytics/internal/stores"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetBatchStatus_States(t *testing.T) {
+	t.Parallel()
+
+	window1 := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	window2 := time.Date(2025, 12, 28, 18, 4, 0, 0, time.UTC)
+	appliedTo := func(windowStart time.Time, batchIDs ...string) *models.WindowAggregateResult {
+		result := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+		for _, batchID := range batchIDs {
+			result.MarkBatchApplied(batchID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		published     bool
+		window1Result *models.WindowAggregateResult
+		window2Result *models.WindowAggregateResult
+		expected      ingestors.BatchState
+	}{
+		{
+			name:     "stored",
+			expected: ingestors.BatchStateStored,
+		},
+		{
+			name:          "published, not applied to every window",
+			published:     true,
+			window1Result: appliedTo(window1, "batch-123"),
+			window2Result: appliedTo(window2, "batch-other"),
+			expected:      ingestors.BatchStatePublished,
+		},
+		{
+			name:          "aggregated",
+			published:     true,
+			window1Result: appliedTo(window1, "batch-123"),
+			window2Result: appliedTo(window2, "batch-other", "batch-123"),
+			expected:      ingestors.BatchStateAggregated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			service := ingestors.NewBatchStatusService(batchStore, outboxStore, aggregateResultStore, []models.WindowSize{models.WindowMinute}, nil)
+
+			ingestedAt := time.Date(2025, 12, 28, 18, 5, 0, 0, time.UTC)
+			batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+			batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(&models.LogBatch{
+				BatchID:    "batch-123",
+				CustomerID: "cus-axon",
+				IngestedAt: ingestedAt,
+				Entries: []*models.LogEntry{
+					{ReceivedAt: window2.Add(10 * time.Second)},
+					{ReceivedAt: window1.Add(15 * time.Second)},
+					{ReceivedAt: window1.Add(20 * time.Second)},
+				},
+			}, nil)
+			outboxStore.EXPECT().IsPublished(gomock.Any(), "cus-axon", "batch-123").Return(tt.published, nil)
+			if tt.window1Result != nil {
+				aggregateResultStore.EXPECT().Get(gomock.Any(), "cus-axon", window1, models.WindowMinute).Return(tt.window1Result, nil)
+			}
+			if tt.window2Result != nil {
+				aggregateResultStore.EXPECT().Get(gomock.Any(), "cus-axon", window2, models.WindowMinute).Return(tt.window2Result, nil)
+			}
+
+			status, err := service.GetBatchStatus(context.Background(), ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"})
+			require.NoError(t, err)
+			assert.Equal(t, "batch-123", status.BatchID)
+			assert.Equal(t, "cus-axon", status.CustomerID)
+			assert.True(t, ingestedAt.Equal(status.IngestedAt))
+			assert.Equal(t, 3, status.EntryCount)
+			assert.Equal(t, tt.expected, status.State)
+			assert.Nil(t, status.Entries)
+		})
+	}
+}
+
+func TestGetBatchStatus_ChecksEveryWindowSize(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := ingestors.NewBatchStatusService(batchStore, outboxStore, aggregateResultStore, []models.WindowSize{models.WindowMinute, models.WindowHour}, nil)
+
+	minuteWindow := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	hourWindow := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	minuteResult := models.NewEmptyWindowAggregateResult("cus-axon", minuteWindow, models.WindowMinute)
+	minuteResult.MarkBatchApplied("batch-123")
+
+	batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+	batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(&models.LogBatch{
+		BatchID:    "batch-123",
+		CustomerID: "cus-axon",
+		Entries:    []*models.LogEntry{{ReceivedAt: minuteWindow.Add(15 * time.Second)}},
+	}, nil)
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+	aggregateResultStore.EXPECT().Get(gomock.Any(), "cus-axon", minuteWindow, models.WindowMinute).Return(minuteResult, nil)
+	// The hour window has not applied the batch yet
+	aggregateResultStore.EXPECT().Get(gomock.Any(), "cus-axon", hourWindow, models.WindowHour).
+		Return(models.NewEmptyWindowAggregateResult("cus-axon", hourWindow, models.WindowHour), nil)
+
+	status, err := service.GetBatchStatus(context.Background(), ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"})
+	require.NoError(t, err)
+	assert.Equal(t, ingestors.BatchStatePublished, status.State)
+}
+
+func TestGetBatchStatus_IncludeEntries(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+	service := ingestors.NewBatchStatusService(batchStore, outboxStore, aggregateResultStore, []models.WindowSize{models.WindowMinute}, nil)
+
+	entries := []*models.LogEntry{
+		{ReceivedAt: time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC), Method: "GET", Path: "/", UserAgent: "Chrome"},
+	}
+	batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+	batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(&models.LogBatch{
+		BatchID:    "batch-123",
+		CustomerID: "cus-axon",
+		Entries:    entries,
+	}, nil)
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "cus-axon", "batch-123").Return(false, nil)
+
+	status, err := service.GetBatchStatus(context.Background(), ingestors.BatchStatusQuery{
+		CustomerID:     "cus-axon",
+		BatchID:        "batch-123",
+		IncludeEntries: "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entries, status.Entries)
+}
+
+func TestGetBatchStatus_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            ingestors.BatchStatusQuery
+		setupMocks       func(batchStore *storemocks.MockLogBatchStore, outboxStore *storemocks.MockOutboxStore, aggregateResultStore *storemocks.MockAggregateResultStore)
+		expectedCode     string
+		expectedCategory string
+	}{
+		{
+			name:             "missing customer",
+			query:            ingestors.BatchStatusQuery{BatchID: "batch-123"},
+			expectedCode:     "ING_1000",
+			expectedCategory: "invalid_argument",
+		},
+		{
+			name:             "batch id with path separator",
+			query:            ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "../batch-123"},
+			expectedCode:     "ING_1000",
+			expectedCategory: "invalid_argument",
+		},
+		{
+			name:             "invalid includeEntries",
+			query:            ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123", IncludeEntries: "maybe"},
+			expectedCode:     "ING_1000",
+			expectedCategory: "invalid_argument",
+		},
+		{
+			name:  "not found",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, _ *storemocks.MockOutboxStore, _ *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(false, nil)
+			},
+			expectedCode:     "ING_1007",
+			expectedCategory: "not_found",
+		},
+		{
+			name:  "removed after the existence check",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, _ *storemocks.MockOutboxStore, _ *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+				batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(nil, stores.ErrLogBatchNotFound)
+			},
+			expectedCode:     "ING_1007",
+			expectedCategory: "not_found",
+		},
+		{
+			name:  "batch store exists error",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, _ *storemocks.MockOutboxStore, _ *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(false, errors.New("storage error"))
+			},
+			expectedCode:     "ING_9000",
+			expectedCategory: "internal",
+		},
+		{
+			name:  "batch store get error",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, _ *storemocks.MockOutboxStore, _ *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+				batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(nil, errors.New("storage error"))
+			},
+			expectedCode:     "ING_9000",
+			expectedCategory: "internal",
+		},
+		{
+			name:  "outbox store error",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, outboxStore *storemocks.MockOutboxStore, _ *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+				batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(&models.LogBatch{BatchID: "batch-123", CustomerID: "cus-axon"}, nil)
+				outboxStore.EXPECT().IsPublished(gomock.Any(), "cus-axon", "batch-123").Return(false, errors.New("storage error"))
+			},
+			expectedCode:     "ING_9002",
+			expectedCategory: "internal",
+		},
+		{
+			name:  "aggregate result store error",
+			query: ingestors.BatchStatusQuery{CustomerID: "cus-axon", BatchID: "batch-123"},
+			setupMocks: func(batchStore *storemocks.MockLogBatchStore, outboxStore *storemocks.MockOutboxStore, aggregateResultStore *storemocks.MockAggregateResultStore) {
+				batchStore.EXPECT().Exists(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+				batchStore.EXPECT().Get(gomock.Any(), "cus-axon", "batch-123").Return(&models.LogBatch{
+					BatchID:    "batch-123",
+					CustomerID: "cus-axon",
+					Entries:    []*models.LogEntry{{ReceivedAt: time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC)}},
+				}, nil)
+				outboxStore.EXPECT().IsPublished(gomock.Any(), "cus-axon", "batch-123").Return(true, nil)
+				aggregateResultStore.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("storage error"))
+			},
+			expectedCode:     "ING_9003",
+			expectedCategory: "internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			aggregateResultStore := storemocks.NewMockAggregateResultStore(ctrl)
+			service := ingestors.NewBatchStatusService(batchStore, outboxStore, aggregateResultStore, []models.WindowSize{models.WindowMinute}, nil)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(batchStore, outboxStore, aggregateResultStore)
+			}
+
+			status, err := service.GetBatchStatus(context.Background(), tt.query)
+			require.Error(t, err)
+			assert.Nil(t, status)
+
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, tt.expectedCategory, svcErr.Category)
+		})
+	}
+}
@@ -1,96 +1,165 @@
 package ingestors
 
 import (
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"log-analytics/internal/models"
-
-	"github.com/mileusna/useragent"
+	"log-analytics/internal/shared/sketches"
 )
 
 //go:generate mockgen -source=batch_summarizer.go -destination=./mocks/batch_summarizer_mock.go -package=mocks
 type BatchSummarizer interface {
-	Summarize(batch *models.LogBatch) *models.BatchSummary
+	// Summarize returns one summary per configured window size, in configuration order.
+	Summarize(batch *models.LogBatch) []*models.BatchSummary
 }
 
 type batchSummarizer struct {
-	windowSize models.WindowSize
+	windowSizes     []models.WindowSize
+	timeZones       map[string]*time.Location
+	pathNormalizer  PathNormalizer
+	limits          models.CardinalityLimits
+	visitorIdentity models.VisitorIdentity
+	// Customers whose windows break path counts down by user agent
+	pathUserAgentBreakdown map[string]bool
+	// Built-in extractors first, then the custom ones
+	extractors []DimensionExtractor
+	// Extractors counted for a single customer only, after extractors
+	customerExtractors map[string][]DimensionExtractor
 }
 
-func NewBatchSummarizer(windowSize models.WindowSize) BatchSummarizer {
+// NewBatchSummarizer creates a BatchSummarizer that rolls every batch into each of windowSizes. timeZones
+// holds the time zone day and week windows of a customer are aligned to; customers without one use UTC.
+// Paths are normalized by pathNormalizer before they are counted, and every window is capped to limits.
+// Unique visitors are told apart by visitorIdentity; entries lacking its fields are not counted as visitors.
+// Customers set in pathUserAgentBreakdown also get their requests counted per path and user agent.
+// Every entry is counted in the built-in dimensions, in the custom dimensions of extractors and in those of
+// its customer in customerExtractors, e.g. its attribute group-bys.
+func NewBatchSummarizer(windowSizes []models.WindowSize, timeZones map[string]*time.Location, pathNormalizer PathNormalizer, limits models.CardinalityLimits,
+	visitorIdentity models.VisitorIdentity, pathUserAgentBreakdown map[string]bool, extractors []DimensionExtractor, customerExtractors map[string][]DimensionExtractor) BatchSummarizer {
 	return &batchSummarizer{
-		windowSize: windowSize,
+		windowSizes:            windowSizes,
+		timeZones:              timeZones,
+		pathNormalizer:         pathNormalizer,
+		limits:                 limits,
+		visitorIdentity:        visitorIdentity,
+		pathUserAgentBreakdown: pathUserAgentBreakdown,
+		extractors:             append(builtinDimensionExtractors(), extractors...),
+		customerExtractors:     customerExtractors,
 	}
 }
 
-func (s *batchSummarizer) Summarize(batch *models.LogBatch) *models.BatchSummary {
-	// Map: windowStart (string) -> maps for counting
-	type windowCounts struct {
-		pathCounts map[string]int64
-		uaCounts   map[string]int64
+func (s *batchSummarizer) Summarize(batch *models.LogBatch) []*models.BatchSummary {
+	summaries := make([]*models.BatchSummary, 0, len(s.windowSizes))
+	for _, windowSize := range s.windowSizes {
+		summaries = append(summaries, &models.BatchSummary{
+			BatchID:       batch.BatchID,
+			CustomerID:    batch.CustomerID,
+			WindowSize:    windowSize,
+			ByWindowStart: make(map[string]models.WindowAggregates),
+		})
 	}
-	byWindowStart := make(map[string]*windowCounts)
 
+	loc := s.timeZones[batch.CustomerID]
+	breakdown := s.pathUserAgentBreakdown[batch.CustomerID]
+	extractors := s.extractors
+	if customerExtractors := s.customerExtractors[batch.CustomerID]; len(customerExtractors) > 0 {
+		extractors = append(slices.Clip(extractors), customerExtractors...)
+	}
+	keys := make([]string, len(extractors))
+	var maxReceivedAt time.Time
 	for _, entry := range batch.Entries {
-		// Group by window duration
-		windowStart := entry.ReceivedAt.UTC().Truncate(s.windowSize.Duration())
-		windowKey := windowStart.Format(time.RFC3339)
+		if entry.ReceivedAt.After(maxReceivedAt) {
+			maxReceivedAt = entry.ReceivedAt
+		}
 
-		// Get or create window counts for this window
-		window, exists := byWindowStart[windowKey]
-		if !exists {
-			window = &windowCounts{
-				pathCounts: make(map[string]int64),
-				uaCounts:   make(map[string]int64),
-			}
-			byWindowStart[windowKey] = window
+		// Normalize path: METHOD + " " + templated path, and parse the user agent into its dimensions
+		dimensionEntry := &DimensionEntry{
+			LogEntry:        entry,
+			NormalizedPath:  strings.ToUpper(entry.Method) + " " + s.pathNormalizer.Normalize(batch.CustomerID, entry.Path),
+			ParsedUserAgent: parseUserAgent(entry.UserAgent),
+		}
+		normalizedPath := dimensionEntry.NormalizedPath
+		for i, extractor := range extractors {
+			keys[i] = extractor.Extract(dimensionEntry)
 		}
+		visitor := s.visitorIdentity.Of(entry)
 
-		// Normalize path: METHOD + " " + path
-		normalizedPath := strings.ToUpper(entry.Method) + " " + entry.Path
-		window.pathCounts[normalizedPath]++
+		// Normalize once, then count the entry in its window of every resolution
+		for _, summary := range summaries {
+			windowKey := summary.WindowSize.Truncate(entry.ReceivedAt, loc).Format(time.RFC3339)
 
-		// Normalize user agent: parse family or use original
-		normalizedUA := s.normalizeUserAgent(entry.UserAgent)
-		window.uaCounts[normalizedUA]++
+			window, exists := summary.ByWindowStart[windowKey]
+			if !exists {
+				window = models.NewEmptyWindowAggregates()
+			}
+			for i, extractor := range extractors {
+				if keys[i] != "" {
+					window.Counts(extractor.Name())[keys[i]]++
+				}
+			}
+			window.BytesSum += entry.ResponseBytes
+			if entry.DurationMs != nil {
+				latency, ok := window.LatencyByPath[normalizedPath]
+				if !ok {
+					latency = &models.LatencyStats{}
+					window.LatencyByPath[normalizedPath] = latency
+				}
+				latency.Observe(*entry.DurationMs)
+			}
+			if visitor != "" {
+				s.addVisitor(&window, normalizedPath, visitor)
+			}
+			if breakdown {
+				addBreakdown(&window, normalizedPath, dimensionEntry.ParsedUserAgent.Family)
+			}
+			summary.ByWindowStart[windowKey] = window
+		}
 	}
 
-	// Convert to result structure
-	result := &models.BatchSummary{
-		BatchID:       batch.BatchID,
-		CustomerID:    batch.CustomerID,
-		WindowSize:    s.windowSize,
-		ByWindowStart: make(map[string]models.WindowAggregates),
+	for _, summary := range summaries {
+		summary.MaxReceivedAt = maxReceivedAt.UTC()
+		// Cap once the whole batch is counted, so the heavy hitters of the batch are exact
+		for _, window := range summary.ByWindowStart {
+			for _, name := range window.Cap(s.limits) {
+				metricSummaryKeysCappedTotal.WithLabelValues(name).Inc()
+			}
+		}
 	}
+	return summaries
+}
 
-	// Sort window keys for deterministic ordering
-	windowKeys := make([]string, 0, len(byWindowStart))
-	for k := range byWindowStart {
-		windowKeys = append(windowKeys, k)
+// addVisitor counts visitor among the unique visitors of the window and of path.
+func (s *batchSummarizer) addVisitor(window *models.WindowAggregates, path string, visitor string) {
+	if window.UniqueVisitors == nil {
+		window.UniqueVisitors = sketches.NewHyperLogLog()
+		window.UniqueVisitorsByPath = make(map[string]*sketches.HyperLogLog)
 	}
-	sort.Strings(windowKeys)
-
-	for _, windowKey := range windowKeys {
-		window := byWindowStart[windowKey]
-
-		result.ByWindowStart[windowKey] = models.WindowAggregates{
-			RequestsByPath:      window.pathCounts,
-			RequestsByUserAgent: window.uaCounts,
-		}
+	window.UniqueVisitors.Add(visitor)
+	pathVisitors, ok := window.UniqueVisitorsByPath[path]
+	if !ok {
+		pathVisitors = sketches.NewHyperLogLog()
+		window.UniqueVisitorsByPath[path] = pathVisitors
 	}
-
-	return result
+	pathVisitors.Add(visitor)
 }
 
-// normalizeUserAgent parses user agent to extract family, or returns original if parsing fails.
-func (s *batchSummarizer) normalizeUserAgent(ua string) string {
-	parsed := useragent.Parse(ua)
-	if parsed.Name != "" {
-		return parsed.Name
+// addBreakdown counts one request of path by userAgent in the path by user agent breakdown of the window.
+func addBreakdown(window *models.WindowAggregates, path string, userAgent string) {
+	if window.RequestsByPathAndUserAgent == nil {
+		window.RequestsByPathAndUserAgent = make(map[string]map[string]int64)
 	}
+	userAgents, ok := window.RequestsByPathAndUserAgent[path]
+	if !ok {
+		userAgents = make(map[string]int64)
+		window.RequestsByPathAndUserAgent[path] = userAgents
+	}
+	userAgents[userAgent]++
+}
 
-	// If parsing fails or family is empty, return original
-	return ua
+// statusClass returns the class of a validated HTTP status code, e.g. "4xx" for 404.
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
 }
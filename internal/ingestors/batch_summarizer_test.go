@@ -7,12 +7,13 @@ import (
 	"log-analytics/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBatchSummarizer_Summarize_MultipleMinutes(t *testing.T) {
 	t.Parallel()
 
-	summarizer := NewBatchSummarizer(models.WindowMinute)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
 
 	// Create entries spanning 2 minutes
 	minute1 := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
@@ -55,35 +56,73 @@ func TestBatchSummarizer_Summarize_MultipleMinutes(t *testing.T) {
 		},
 	}
 
-	summary := summarizer.Summarize(batch)
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
 
 	minute1Key := minute1.Format(time.RFC3339)
 	minute2Key := minute2.Format(time.RFC3339)
 
 	expectedSummary := &models.BatchSummary{
-		BatchID:    "batch123",
-		CustomerID: "customer123",
-		WindowSize: models.WindowMinute,
+		BatchID:       "batch123",
+		CustomerID:    "customer123",
+		WindowSize:    models.WindowMinute,
+		MaxReceivedAt: minute2.Add(15 * time.Second),
 		ByWindowStart: map[string]models.WindowAggregates{
 			minute1Key: {
-				RequestsByPath: map[string]int64{
-					"GET /":      1,
-					"POST /logs": 2,
-				},
-				RequestsByUserAgent: map[string]int64{
-					"Chrome":  1,
-					"Firefox": 2,
+				Dimensions: models.Dimensions{
+					models.DimensionPath: {
+						"GET /":      1,
+						"POST /logs": 2,
+					},
+					models.DimensionUserAgent: {
+						"Chrome":  1,
+						"Firefox": 2,
+					},
+					models.DimensionOS: {
+						"Windows":         1,
+						"macOS":           1,
+						models.UnknownKey: 1,
+					},
+					models.DimensionDeviceType: {
+						"desktop":         2,
+						models.UnknownKey: 1,
+					},
+					models.DimensionBrowserMajorVersion: {
+						"Chrome 90":   1,
+						"Firefox 123": 2,
+					},
+					models.DimensionBotOrHuman: {
+						"human": 3,
+					},
 				},
+				LatencyByPath: map[string]*models.LatencyStats{},
 			},
 			minute2Key: {
-				RequestsByPath: map[string]int64{
-					"GET /":      1,
-					"POST /logs": 1,
-				},
-				RequestsByUserAgent: map[string]int64{
-					"Chrome": 1,
-					"curl":   1,
+				Dimensions: models.Dimensions{
+					models.DimensionPath: {
+						"GET /":      1,
+						"POST /logs": 1,
+					},
+					models.DimensionUserAgent: {
+						"Chrome": 1,
+						"curl":   1,
+					},
+					models.DimensionOS: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionDeviceType: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionBrowserMajorVersion: {
+						"Chrome 90": 1,
+						"curl 7":    1,
+					},
+					models.DimensionBotOrHuman: {
+						"human": 2,
+					},
 				},
+				LatencyByPath: map[string]*models.LatencyStats{},
 			},
 		},
 	}
@@ -94,7 +133,7 @@ func TestBatchSummarizer_Summarize_MultipleMinutes(t *testing.T) {
 func TestBatchSummarizer_Summarize_UserAgentParseFails(t *testing.T) {
 	t.Parallel()
 
-	summarizer := NewBatchSummarizer(models.WindowMinute)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
 
 	unknownUA := "SomeUnknownUserAgent/1.0"
 	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
@@ -111,22 +150,40 @@ func TestBatchSummarizer_Summarize_UserAgentParseFails(t *testing.T) {
 		},
 	}
 
-	summary := summarizer.Summarize(batch)
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
 
 	minuteKey := minute.Format(time.RFC3339)
 
 	expectedSummary := &models.BatchSummary{
-		BatchID:    "batch123",
-		CustomerID: "customer123",
-		WindowSize: models.WindowMinute,
+		BatchID:       "batch123",
+		CustomerID:    "customer123",
+		WindowSize:    models.WindowMinute,
+		MaxReceivedAt: minute,
 		ByWindowStart: map[string]models.WindowAggregates{
 			minuteKey: {
-				RequestsByPath: map[string]int64{
-					"GET /": 1,
-				},
-				RequestsByUserAgent: map[string]int64{
-					"SomeUnknownUserAgent": 1,
+				Dimensions: models.Dimensions{
+					models.DimensionPath: {
+						"GET /": 1,
+					},
+					models.DimensionUserAgent: {
+						"SomeUnknownUserAgent": 1,
+					},
+					models.DimensionOS: {
+						models.UnknownKey: 1,
+					},
+					models.DimensionDeviceType: {
+						models.UnknownKey: 1,
+					},
+					models.DimensionBrowserMajorVersion: {
+						"SomeUnknownUserAgent 1": 1,
+					},
+					models.DimensionBotOrHuman: {
+						"human": 1,
+					},
 				},
+				LatencyByPath: map[string]*models.LatencyStats{},
 			},
 		},
 	}
@@ -137,7 +194,7 @@ func TestBatchSummarizer_Summarize_UserAgentParseFails(t *testing.T) {
 func TestBatchSummarizer_Summarize_MethodNormalization(t *testing.T) {
 	t.Parallel()
 
-	summarizer := NewBatchSummarizer(models.WindowMinute)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
 
 	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
 	batch := &models.LogBatch{
@@ -159,23 +216,41 @@ func TestBatchSummarizer_Summarize_MethodNormalization(t *testing.T) {
 		},
 	}
 
-	summary := summarizer.Summarize(batch)
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
 
 	minuteKey := minute.Format(time.RFC3339)
 
 	expectedSummary := &models.BatchSummary{
-		BatchID:    "batch123",
-		CustomerID: "customer123",
-		WindowSize: models.WindowMinute,
+		BatchID:       "batch123",
+		CustomerID:    "customer123",
+		WindowSize:    models.WindowMinute,
+		MaxReceivedAt: minute.Add(30 * time.Second),
 		ByWindowStart: map[string]models.WindowAggregates{
 			minuteKey: {
-				RequestsByPath: map[string]int64{
-					"GET /":      1,
-					"POST /logs": 1,
-				},
-				RequestsByUserAgent: map[string]int64{
-					"test": 2,
+				Dimensions: models.Dimensions{
+					models.DimensionPath: {
+						"GET /":      1,
+						"POST /logs": 1,
+					},
+					models.DimensionUserAgent: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionOS: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionDeviceType: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionBrowserMajorVersion: {
+						models.UnknownKey: 2,
+					},
+					models.DimensionBotOrHuman: {
+						"human": 2,
+					},
 				},
+				LatencyByPath: map[string]*models.LatencyStats{},
 			},
 		},
 	}
@@ -186,7 +261,7 @@ func TestBatchSummarizer_Summarize_MethodNormalization(t *testing.T) {
 func TestBatchSummarizer_Summarize_UTCTimezone(t *testing.T) {
 	t.Parallel()
 
-	summarizer := NewBatchSummarizer(models.WindowMinute)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
 
 	// Create entries with the same UTC time but different timezones
 	utcTime := time.Date(2025, 12, 21, 14, 21, 30, 0, time.UTC)
@@ -212,7 +287,9 @@ func TestBatchSummarizer_Summarize_UTCTimezone(t *testing.T) {
 		},
 	}
 
-	summary := summarizer.Summarize(batch)
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
 
 	// Both entries should be in the same minute window (UTC)
 	expectedMinuteKey := utcTime.UTC().Truncate(time.Minute).Format(time.RFC3339)
@@ -229,7 +306,386 @@ func TestBatchSummarizer_Summarize_UTCTimezone(t *testing.T) {
 
 	// Verify both entries are in the same window
 	window := summary.ByWindowStart[expectedMinuteKey]
-	assert.Equal(t, 2, len(window.RequestsByPath), "both entries should be in same window")
-	assert.Equal(t, int64(1), window.RequestsByPath["GET /"], "GET / should have count 1")
-	assert.Equal(t, int64(1), window.RequestsByPath["POST /logs"], "POST /logs should have count 1")
+	assert.Equal(t, 2, len(window.Dimensions[models.DimensionPath]), "both entries should be in same window")
+	assert.Equal(t, int64(1), window.Dimensions[models.DimensionPath]["GET /"], "GET / should have count 1")
+	assert.Equal(t, int64(1), window.Dimensions[models.DimensionPath]["POST /logs"], "POST /logs should have count 1")
+}
+
+func TestBatchSummarizer_Summarize_DayWindowInCustomerTimeZone(t *testing.T) {
+	t.Parallel()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowDay}, map[string]*time.Location{"customer-tokyo": tokyo}, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+
+	entries := []*models.LogEntry{
+		// 2025-12-21 23:30 in Tokyo
+		{ReceivedAt: time.Date(2025, 12, 21, 14, 30, 0, 0, time.UTC), Method: "GET", Path: "/", UserAgent: "test"},
+		// 2025-12-22 00:30 in Tokyo, same UTC day
+		{ReceivedAt: time.Date(2025, 12, 21, 15, 30, 0, 0, time.UTC), Method: "GET", Path: "/", UserAgent: "test"},
+	}
+
+	tokyoSummaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch1", CustomerID: "customer-tokyo", Entries: entries})
+	require.Len(t, tokyoSummaries, 1)
+	tokyoSummary := tokyoSummaries[0]
+	assert.Len(t, tokyoSummary.ByWindowStart, 2)
+	assert.Contains(t, tokyoSummary.ByWindowStart, "2025-12-20T15:00:00Z")
+	assert.Contains(t, tokyoSummary.ByWindowStart, "2025-12-21T15:00:00Z")
+
+	utcSummaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch2", CustomerID: "customer-utc", Entries: entries})
+	require.Len(t, utcSummaries, 1)
+	utcSummary := utcSummaries[0]
+	assert.Len(t, utcSummary.ByWindowStart, 1)
+	assert.Contains(t, utcSummary.ByWindowStart, "2025-12-21T00:00:00Z")
+}
+
+func TestBatchSummarizer_Summarize_MultipleWindowSizes(t *testing.T) {
+	t.Parallel()
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute, models.WindowHour, models.WindowDay}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+
+	batch := &models.LogBatch{
+		BatchID:    "batch123",
+		CustomerID: "customer123",
+		Entries: []*models.LogEntry{
+			{ReceivedAt: time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC), Method: "GET", Path: "/", UserAgent: "test"},
+			{ReceivedAt: time.Date(2025, 12, 21, 14, 22, 0, 0, time.UTC), Method: "GET", Path: "/", UserAgent: "test"},
+			{ReceivedAt: time.Date(2025, 12, 21, 15, 5, 0, 0, time.UTC), Method: "POST", Path: "/logs", UserAgent: "test"},
+		},
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 3)
+
+	tests := []struct {
+		windowSize models.WindowSize
+		expected   map[string]int64 // window start -> "GET /" count
+		windows    int
+	}{
+		{
+			windowSize: models.WindowMinute,
+			windows:    3,
+			expected:   map[string]int64{"2025-12-21T14:21:00Z": 1, "2025-12-21T14:22:00Z": 1, "2025-12-21T15:05:00Z": 0},
+		},
+		{
+			windowSize: models.WindowHour,
+			windows:    2,
+			expected:   map[string]int64{"2025-12-21T14:00:00Z": 2, "2025-12-21T15:00:00Z": 0},
+		},
+		{
+			windowSize: models.WindowDay,
+			windows:    1,
+			expected:   map[string]int64{"2025-12-21T00:00:00Z": 2},
+		},
+	}
+
+	for i, tt := range tests {
+		summary := summaries[i]
+		assert.Equal(t, "batch123", summary.BatchID)
+		assert.Equal(t, tt.windowSize, summary.WindowSize)
+		assert.Len(t, summary.ByWindowStart, tt.windows)
+		for windowKey, count := range tt.expected {
+			require.Contains(t, summary.ByWindowStart, windowKey, "window size %s", tt.windowSize)
+			assert.Equal(t, count, summary.ByWindowStart[windowKey].Dimensions[models.DimensionPath]["GET /"], "window size %s, window %s", tt.windowSize, windowKey)
+		}
+	}
+	assert.Equal(t, int64(1), summaries[2].ByWindowStart["2025-12-21T00:00:00Z"].Dimensions[models.DimensionPath]["POST /logs"])
+	assert.Equal(t, int64(3), summaries[2].ByWindowStart["2025-12-21T00:00:00Z"].Dimensions[models.DimensionUserAgent][models.UnknownKey])
+}
+
+func TestBatchSummarizer_Summarize_NormalizesPaths(t *testing.T) {
+	t.Parallel()
+
+	pathNormalizer, err := NewPathNormalizer(map[string][]string{"cus-axon": {"/orders/{orderId}/items"}})
+	require.NoError(t, err)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, pathNormalizer, models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	batch := &models.LogBatch{
+		BatchID:    "batch-1",
+		CustomerID: "cus-axon",
+		Entries: []*models.LogEntry{
+			{ReceivedAt: minute, Method: "get", Path: "/users/123", UserAgent: "test"},
+			{ReceivedAt: minute, Method: "GET", Path: "/Users/456/", UserAgent: "test"},
+			{ReceivedAt: minute, Method: "GET", Path: "/orders/7/items?page=2", UserAgent: "test"},
+		},
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	assert.Equal(t, map[string]int64{"GET /users/:id": 2, "GET /orders/{orderId}/items": 1}, window.Dimensions[models.DimensionPath])
+}
+
+func newTestPathNormalizer(t *testing.T) PathNormalizer {
+	pathNormalizer, err := NewPathNormalizer(nil)
+	require.NoError(t, err)
+	return pathNormalizer
+}
+
+func TestBatchSummarizer_Summarize_CapsKeysPerWindow(t *testing.T) {
+	t.Parallel()
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t),
+		models.CardinalityLimits{MaxPaths: 1, MaxUserAgents: 1, MaxBrowserMajorVersions: 1}, models.VisitorIdentityNone, nil, nil, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	batch := &models.LogBatch{
+		BatchID:    "batch-1",
+		CustomerID: "cus-axon",
+		Entries: []*models.LogEntry{
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0"},
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0"},
+			{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "Wget/1.21.4"},
+			{ReceivedAt: minute, Method: "GET", Path: "/contact", UserAgent: "python-requests/2.31.0"},
+		},
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	assert.Equal(t, map[string]int64{"GET /": 2, models.OtherKey: 2}, window.Dimensions[models.DimensionPath])
+	assert.Equal(t, map[string]int64{"curl": 2, models.OtherKey: 2}, window.Dimensions[models.DimensionUserAgent])
+	assert.Equal(t, map[string]int64{"curl 7": 2, models.OtherKey: 2}, window.Dimensions[models.DimensionBrowserMajorVersion])
+}
+
+func TestBatchSummarizer_Summarize_PathUserAgentBreakdown(t *testing.T) {
+	t.Parallel()
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t),
+		models.CardinalityLimits{MaxPaths: 2, MaxUserAgents: 2, MaxBreakdownUserAgents: 1}, models.VisitorIdentityNone, map[string]bool{"cus-axon": true}, nil, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	entries := []*models.LogEntry{
+		{ReceivedAt: minute, Method: "POST", Path: "/checkout", UserAgent: "curl/7.68.0"},
+		{ReceivedAt: minute, Method: "POST", Path: "/checkout", UserAgent: "curl/7.68.0"},
+		{ReceivedAt: minute, Method: "POST", Path: "/checkout", UserAgent: "Wget/1.21.4"},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "Wget/1.21.4"},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "Wget/1.21.4"},
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "curl/7.68.0"},
+	}
+
+	summaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon", Entries: entries})
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	// Rows follow the capped paths and every row is capped to MaxUserAgents
+	assert.Equal(t, map[string]map[string]int64{
+		"POST /checkout": {"curl": 2, models.OtherKey: 1},
+		"GET /":          {"Wget": 2},
+		models.OtherKey:  {"curl": 1},
+	}, window.RequestsByPathAndUserAgent)
+
+	// Customers without the breakdown do not pay for it
+	summaries = summarizer.Summarize(&models.LogBatch{BatchID: "batch-2", CustomerID: "cus-other", Entries: entries})
+	require.Len(t, summaries, 1)
+	assert.Nil(t, summaries[0].ByWindowStart[minute.Format(time.RFC3339)].RequestsByPathAndUserAgent)
+}
+
+func TestBatchSummarizer_Summarize_CustomDimensions(t *testing.T) {
+	t.Parallel()
+
+	hostExtractor, err := NewFieldDimensionExtractor("host", DimensionFieldHost)
+	require.NoError(t, err)
+	methodExtractor, err := NewFieldDimensionExtractor("method", DimensionFieldMethod)
+	require.NoError(t, err)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t),
+		models.CardinalityLimits{MaxDimensionKeys: 1}, models.VisitorIdentityNone, nil, []DimensionExtractor{hostExtractor, methodExtractor}, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	batch := &models.LogBatch{
+		BatchID:    "batch-1",
+		CustomerID: "cus-axon",
+		Entries: []*models.LogEntry{
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0", Host: "api.example.com"},
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0", Host: "api.example.com"},
+			{ReceivedAt: minute, Method: "POST", Path: "/", UserAgent: "curl/7.68.0", Host: "www.example.com"},
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0"},
+		},
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	// Entries without a host are left out, every custom dimension keeps MaxDimensionKeys keys
+	assert.Equal(t, map[string]int64{"api.example.com": 2, models.OtherKey: 1}, window.Dimensions["host"])
+	assert.Equal(t, map[string]int64{"GET": 3, models.OtherKey: 1}, window.Dimensions["method"])
+	// Counted next to the built-in dimensions, paths keep their own limit
+	assert.Equal(t, map[string]int64{"GET /": 3, "POST /": 1}, window.Dimensions[models.DimensionPath])
+}
+
+func TestBatchSummarizer_Summarize_GroupByAttributes(t *testing.T) {
+	t.Parallel()
+
+	region, err := NewAttributeDimensionExtractor("region")
+	require.NoError(t, err)
+	tenantPlan, err := NewAttributeDimensionExtractor("tenantPlan")
+	require.NoError(t, err)
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t),
+		models.CardinalityLimits{MaxDimensionKeys: 1}, models.VisitorIdentityNone, nil, nil,
+		map[string][]DimensionExtractor{"cus-axon": {region, tenantPlan}})
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	entries := []*models.LogEntry{
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0", Attributes: map[string]string{"region": "eu-west-1", "tenantPlan": "pro", "appVersion": "1.2.0"}},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0", Attributes: map[string]string{"region": "eu-west-1"}},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0", Attributes: map[string]string{"region": "us-east-1"}},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "curl/7.68.0"},
+	}
+
+	summaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon", Entries: entries})
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	// Only grouped attributes are counted, entries without one are left out and values are capped
+	assert.Equal(t, map[string]int64{"eu-west-1": 2, models.OtherKey: 1}, window.Dimensions["attribute.region"])
+	assert.Equal(t, map[string]int64{"pro": 1}, window.Dimensions["attribute.tenantPlan"])
+	assert.NotContains(t, window.Dimensions, "attribute.appVersion")
+
+	// Customers grouping by no attribute do not get any
+	summaries = summarizer.Summarize(&models.LogBatch{BatchID: "batch-2", CustomerID: "cus-other", Entries: entries})
+	require.Len(t, summaries, 1)
+	for name := range summaries[0].ByWindowStart[minute.Format(time.RFC3339)].Dimensions {
+		assert.False(t, models.IsAttributeDimension(name), name)
+	}
+}
+
+func TestBatchSummarizer_Summarize_UserAgentDimensions(t *testing.T) {
+	t.Parallel()
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	userAgents := []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"not a browser",
+		"",
+	}
+	batch := &models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon"}
+	for _, userAgent := range userAgents {
+		batch.Entries = append(batch.Entries, &models.LogEntry{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: userAgent})
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	assert.Equal(t, map[string]int64{"Chrome": 1, "Safari": 2, "Googlebot": 1, models.UnknownKey: 2}, window.Dimensions[models.DimensionUserAgent])
+	assert.Equal(t, map[string]int64{"Windows": 1, "iOS": 2, models.UnknownKey: 3}, window.Dimensions[models.DimensionOS])
+	assert.Equal(t, map[string]int64{"desktop": 1, "mobile": 1, "tablet": 1, "bot": 1, models.UnknownKey: 2}, window.Dimensions[models.DimensionDeviceType])
+	assert.Equal(t, map[string]int64{"Chrome 120": 1, "Safari 17": 2, "Googlebot 2": 1, models.UnknownKey: 2}, window.Dimensions[models.DimensionBrowserMajorVersion])
+	assert.Equal(t, map[string]int64{"bot": 1, "human": 5}, window.Dimensions[models.DimensionBotOrHuman])
+}
+
+func TestBatchSummarizer_Summarize_StatusBytesAndLatency(t *testing.T) {
+	t.Parallel()
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{MaxPaths: 1}, models.VisitorIdentityNone, nil, nil, nil)
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	duration := func(ms float64) *float64 { return &ms }
+	batch := &models.LogBatch{
+		BatchID:    "batch-1",
+		CustomerID: "cus-axon",
+		Entries: []*models.LogEntry{
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "test", Status: 200, DurationMs: duration(10), ResponseBytes: 100},
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "test", Status: 204, DurationMs: duration(30), ResponseBytes: 50},
+			{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "test", Status: 503},
+			{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "test", Status: 404, DurationMs: duration(5)},
+			{ReceivedAt: minute, Method: "GET", Path: "/contact", UserAgent: "test", DurationMs: duration(0)},
+		},
+	}
+
+	summaries := summarizer.Summarize(batch)
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	assert.Equal(t, map[string]int64{"2xx": 2, "4xx": 1, "5xx": 1}, window.Dimensions[models.DimensionStatusClass])
+	assert.Equal(t, int64(150), window.BytesSum)
+	// Latencies follow the capped paths
+	require.Len(t, window.LatencyByPath, 2)
+	root := window.LatencyByPath["GET /"]
+	require.NotNil(t, root)
+	assert.Equal(t, int64(2), root.Count)
+	assert.Equal(t, 40.0, root.SumMs)
+	assert.Equal(t, 10.0, root.MinMs)
+	assert.Equal(t, 30.0, root.MaxMs)
+	p100, ok := root.Quantile(1)
+	require.True(t, ok)
+	assert.InEpsilon(t, 30, p100, 0.01)
+	other := window.LatencyByPath[models.OtherKey]
+	require.NotNil(t, other)
+	assert.Equal(t, int64(2), other.Count)
+	assert.Equal(t, 5.0, other.MaxMs)
+}
+
+func TestBatchSummarizer_Summarize_UniqueVisitors(t *testing.T) {
+	t.Parallel()
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	entries := []*models.LogEntry{
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "agent-a", ClientIP: "10.0.0.1", UserID: "user-1"},
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "agent-b", ClientIP: "10.0.0.1", UserID: "user-2"},
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "agent-a", ClientIP: "10.0.0.1", UserID: "user-1"},
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "agent-a", ClientIP: "10.0.0.2"},
+		// Not counted by any identity
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "agent-c"},
+	}
+
+	tests := []struct {
+		identity       models.VisitorIdentity
+		expectedWindow int64
+		expectedByPath map[string]int64
+	}{
+		{identity: models.VisitorIdentityClientIP, expectedWindow: 2, expectedByPath: map[string]int64{"GET /": 1, "GET /about": 2}},
+		{identity: models.VisitorIdentityUserAgentIP, expectedWindow: 3, expectedByPath: map[string]int64{"GET /": 2, "GET /about": 2}},
+		{identity: models.VisitorIdentityUserID, expectedWindow: 2, expectedByPath: map[string]int64{"GET /": 2, "GET /about": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.identity), func(t *testing.T) {
+			t.Parallel()
+
+			summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, tt.identity, nil, nil, nil)
+			summaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon", Entries: entries})
+			require.Len(t, summaries, 1)
+			window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+
+			require.NotNil(t, window.UniqueVisitors)
+			assert.Equal(t, tt.expectedWindow, window.UniqueVisitors.Estimate())
+			byPath := make(map[string]int64)
+			for path, visitors := range window.UniqueVisitorsByPath {
+				byPath[path] = visitors.Estimate()
+			}
+			assert.Equal(t, tt.expectedByPath, byPath)
+		})
+	}
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t), models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+	summaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon", Entries: entries})
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+	assert.Nil(t, window.UniqueVisitors)
+	assert.Nil(t, window.UniqueVisitorsByPath)
+}
+
+func TestBatchSummarizer_Summarize_CapsUniqueVisitorPaths(t *testing.T) {
+	t.Parallel()
+
+	minute := time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)
+	entries := []*models.LogEntry{
+		{ReceivedAt: minute, Method: "GET", Path: "/", UserAgent: "agent-a", ClientIP: "10.0.0.1"},
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "agent-a", ClientIP: "10.0.0.2"},
+		{ReceivedAt: minute, Method: "GET", Path: "/about", UserAgent: "agent-a", ClientIP: "10.0.0.3"},
+	}
+
+	summarizer := NewBatchSummarizer([]models.WindowSize{models.WindowMinute}, nil, newTestPathNormalizer(t),
+		models.CardinalityLimits{MaxUniqueVisitorPaths: 1}, models.VisitorIdentityClientIP, nil, nil, nil)
+	summaries := summarizer.Summarize(&models.LogBatch{BatchID: "batch-1", CustomerID: "cus-axon", Entries: entries})
+	require.Len(t, summaries, 1)
+	window := summaries[0].ByWindowStart[minute.Format(time.RFC3339)]
+
+	// Paths are not capped, only their sketches
+	assert.Equal(t, map[string]int64{"GET /": 1, "GET /about": 2}, window.Dimensions[models.DimensionPath])
+	require.Len(t, window.UniqueVisitorsByPath, 2)
+	assert.Equal(t, int64(2), window.UniqueVisitorsByPath["GET /about"].Estimate())
+	assert.Equal(t, int64(1), window.UniqueVisitorsByPath[models.OtherKey].Estimate())
 }
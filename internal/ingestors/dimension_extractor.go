@@ -0,0 +1,120 @@
+package ingestors
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"log-analytics/internal/models"
+)
+
+// Log entry fields a custom dimension can be keyed by
+const (
+	DimensionFieldMethod   = "method"
+	DimensionFieldStatus   = "status"
+	DimensionFieldHost     = "host"
+	DimensionFieldClientIP = "client_ip"
+	DimensionFieldUserID   = "user_id"
+)
+
+// dimensionNamePattern keeps custom dimension names usable as JSON keys and metric labels
+var dimensionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// attributeNamePattern likewise keeps attribute names usable as JSON keys and dimension names, allowing
+// camel case, e.g. appVersion
+var attributeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// DimensionEntry is a log entry as seen by dimension extractors. The normalized path and the parsed user
+// agent are computed once per entry and shared by every extractor.
+type DimensionEntry struct {
+	*models.LogEntry
+	NormalizedPath  string // METHOD + " " + normalized path, the key of the path dimension
+	ParsedUserAgent ParsedUserAgent
+}
+
+// DimensionExtractor derives the key an entry is counted under in one dimension of a window.
+//
+//go:generate mockgen -source=dimension_extractor.go -destination=./mocks/dimension_extractor_mock.go -package=mocks
+type DimensionExtractor interface {
+	// Name names the dimension, its key in models.WindowAggregates.Dimensions.
+	Name() string
+	// Extract returns the key of entry, or "" to leave the entry out of the dimension.
+	Extract(entry *DimensionEntry) string
+}
+
+type dimensionExtractor struct {
+	name    string
+	extract func(entry *DimensionEntry) string
+}
+
+func (e *dimensionExtractor) Name() string {
+	return e.name
+}
+
+func (e *dimensionExtractor) Extract(entry *DimensionEntry) string {
+	return e.extract(entry)
+}
+
+// builtinDimensionExtractors returns the extractors of the built-in dimensions, counted for every customer.
+func builtinDimensionExtractors() []DimensionExtractor {
+	return []DimensionExtractor{
+		&dimensionExtractor{name: models.DimensionPath, extract: func(entry *DimensionEntry) string { return entry.NormalizedPath }},
+		&dimensionExtractor{name: models.DimensionUserAgent, extract: func(entry *DimensionEntry) string { return entry.ParsedUserAgent.Family }},
+		&dimensionExtractor{name: models.DimensionOS, extract: func(entry *DimensionEntry) string { return entry.ParsedUserAgent.OS }},
+		&dimensionExtractor{name: models.DimensionDeviceType, extract: func(entry *DimensionEntry) string { return entry.ParsedUserAgent.DeviceType }},
+		&dimensionExtractor{name: models.DimensionBrowserMajorVersion, extract: func(entry *DimensionEntry) string { return entry.ParsedUserAgent.BrowserMajorVersion }},
+		&dimensionExtractor{name: models.DimensionBotOrHuman, extract: func(entry *DimensionEntry) string { return entry.ParsedUserAgent.BotOrHuman }},
+		&dimensionExtractor{name: models.DimensionStatusClass, extract: func(entry *DimensionEntry) string {
+			if entry.Status == 0 {
+				return ""
+			}
+			return statusClass(entry.Status)
+		}},
+	}
+}
+
+// NewFieldDimensionExtractor creates a DimensionExtractor counting entries by one of their fields, e.g. a
+// "host" dimension keyed by DimensionFieldHost. Entries without the field are left out of the dimension.
+func NewFieldDimensionExtractor(name string, field string) (DimensionExtractor, error) {
+	if !dimensionNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("dimension name must be lower case letters, digits and underscores: %q", name)
+	}
+	if models.IsBuiltinDimension(name) {
+		return nil, fmt.Errorf("dimension name is reserved for a built-in dimension: %q", name)
+	}
+
+	var extract func(entry *DimensionEntry) string
+	switch field {
+	case DimensionFieldMethod:
+		extract = func(entry *DimensionEntry) string { return strings.ToUpper(entry.Method) }
+	case DimensionFieldStatus:
+		extract = func(entry *DimensionEntry) string {
+			if entry.Status == 0 {
+				return ""
+			}
+			return strconv.Itoa(entry.Status)
+		}
+	case DimensionFieldHost:
+		extract = func(entry *DimensionEntry) string { return entry.Host }
+	case DimensionFieldClientIP:
+		extract = func(entry *DimensionEntry) string { return entry.ClientIP }
+	case DimensionFieldUserID:
+		extract = func(entry *DimensionEntry) string { return entry.UserID }
+	default:
+		return nil, fmt.Errorf("unsupported field of dimension %s: %q", name, field)
+	}
+	return &dimensionExtractor{name: name, extract: extract}, nil
+}
+
+// NewAttributeDimensionExtractor creates a DimensionExtractor counting entries by the value of attribute, in
+// the dimension models.AttributeDimension(attribute). Entries without the attribute are left out.
+func NewAttributeDimensionExtractor(attribute string) (DimensionExtractor, error) {
+	if !attributeNamePattern.MatchString(attribute) {
+		return nil, fmt.Errorf("attribute name must be letters, digits and underscores, starting with a letter: %q", attribute)
+	}
+	return &dimensionExtractor{
+		name:    models.AttributeDimension(attribute),
+		extract: func(entry *DimensionEntry) string { return entry.Attributes[attribute] },
+	}, nil
+}
@@ -0,0 +1,83 @@
+package ingestors
+
+import (
+	"testing"
+
+	"log-analytics/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFieldDimensionExtractor(t *testing.T) {
+	t.Parallel()
+
+	entry := &DimensionEntry{LogEntry: &models.LogEntry{Method: "post", Status: 404, Host: "api.example.com", ClientIP: "10.0.0.1"}}
+
+	tests := []struct {
+		name     string
+		field    string
+		entry    *DimensionEntry
+		expected string
+	}{
+		{name: "method", field: DimensionFieldMethod, entry: entry, expected: "POST"},
+		{name: "status", field: DimensionFieldStatus, entry: entry, expected: "404"},
+		{name: "host", field: DimensionFieldHost, entry: entry, expected: "api.example.com"},
+		{name: "client_ip", field: DimensionFieldClientIP, entry: entry, expected: "10.0.0.1"},
+		{name: "missing user_id", field: DimensionFieldUserID, entry: entry, expected: ""},
+		{name: "missing status", field: DimensionFieldStatus, entry: &DimensionEntry{LogEntry: &models.LogEntry{}}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := NewFieldDimensionExtractor("dimension", tt.field)
+			require.NoError(t, err)
+			assert.Equal(t, "dimension", extractor.Name())
+			assert.Equal(t, tt.expected, extractor.Extract(tt.entry))
+		})
+	}
+}
+
+func TestNewFieldDimensionExtractor_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		dimensionName string
+		field         string
+		expectedError string
+	}{
+		{name: "built-in name", dimensionName: models.DimensionPath, field: DimensionFieldHost, expectedError: "reserved"},
+		{name: "invalid name", dimensionName: "Host Name", field: DimensionFieldHost, expectedError: "lower case"},
+		{name: "unknown field", dimensionName: "region", field: "region", expectedError: "unsupported field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := NewFieldDimensionExtractor(tt.dimensionName, tt.field)
+			assert.Nil(t, extractor)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestNewAttributeDimensionExtractor(t *testing.T) {
+	t.Parallel()
+
+	extractor, err := NewAttributeDimensionExtractor("appVersion")
+	require.NoError(t, err)
+	assert.Equal(t, "attribute.appVersion", extractor.Name())
+	assert.Equal(t, "1.2.0", extractor.Extract(&DimensionEntry{LogEntry: &models.LogEntry{Attributes: map[string]string{"appVersion": "1.2.0"}}}))
+	assert.Empty(t, extractor.Extract(&DimensionEntry{LogEntry: &models.LogEntry{}}))
+
+	for _, attribute := range []string{"", "app.version", "1region", "tenant plan"} {
+		extractor, err := NewAttributeDimensionExtractor(attribute)
+		assert.Nil(t, extractor, attribute)
+		assert.Error(t, err, attribute)
+	}
+}
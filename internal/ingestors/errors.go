@@ -9,9 +9,18 @@ import (
 const (
 	codeValidationFailed      = "ING_1000"
 	codeBatchAlreadyProcessed = "ING_1001"
+	codeBatchInProgress       = "ING_1002"
+	codeBatchTooLarge         = "ING_1003"
+	codeTooManyEntries        = "ING_1004"
+	codeFieldTooLong          = "ING_1005"
+	codeReceivedAtOutOfRange  = "ING_1006"
+	codeBatchNotFound         = "ING_1007"
 
 	codeInternalLogBatchStoreFailed           = "ING_9000"
 	codeInternalPartialInsightPublisherFailed = "ING_9001"
+	codeInternalOutboxStoreFailed             = "ING_9002"
+	codeInternalAggregateResultStoreFailed    = "ING_9003"
+	codeInternalRejectedEntryStoreFailed      = "ING_9004"
 )
 
 // ErrValidationFailed returns an error for validation failures.
@@ -19,11 +28,41 @@ func errValidationFailed(msg string, cause error) *svcerrors.ServiceError {
 	return svcerrors.NewInvalidArgumentError(codeValidationFailed, msg, cause)
 }
 
+// errBatchTooLarge returns an error when the decoded request body exceeds the batch size limit.
+func errBatchTooLarge(maxBatchBytes int) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeBatchTooLarge, fmt.Sprintf("batch too large: must be <= %s", formatBytes(maxBatchBytes)), nil)
+}
+
+// errTooManyEntries returns an error when a batch holds more log entries than allowed.
+func errTooManyEntries(maxEntries int) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeTooManyEntries, fmt.Sprintf("too many log entries: max %d per batch", maxEntries), nil)
+}
+
+// errFieldTooLong returns an error when a log entry field exceeds its length limit.
+func errFieldTooLong(msg string) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeFieldTooLong, msg, nil)
+}
+
+// errReceivedAtOutOfRange returns an error when a log entry's receivedAt is too far from the server clock.
+func errReceivedAtOutOfRange(msg string) *svcerrors.ServiceError {
+	return svcerrors.NewInvalidArgumentError(codeReceivedAtOutOfRange, msg, nil)
+}
+
 // errLogBatchAlreadyProcessed returns an error when a log batch has already been processed.
 func errLogBatchAlreadyProcessed(cause error) *svcerrors.ServiceError {
 	return svcerrors.NewResourceConflictError(codeBatchAlreadyProcessed, "log batch already processed", cause)
 }
 
+// errLogBatchInProgress returns an error when a log batch was stored recently and is still being published.
+func errLogBatchInProgress(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewResourceConflictError(codeBatchInProgress, "log batch is still being processed, retry later", cause)
+}
+
+// errLogBatchNotFound returns an error when no log batch exists for the customer and batch ID.
+func errLogBatchNotFound(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewNotFoundError(codeBatchNotFound, "log batch not found", cause)
+}
+
 // errInternalLogBatchStoreFailed returns an error when a log batch store operation fails.
 func errInternalLogBatchStoreFailed(cause error) *svcerrors.ServiceError {
 	return svcerrors.NewInternalError(codeInternalLogBatchStoreFailed, fmt.Errorf("logBatchStoreFailed: %w", cause))
@@ -33,3 +72,18 @@ func errInternalLogBatchStoreFailed(cause error) *svcerrors.ServiceError {
 func errInternalPartialInsightPublisherFailed(cause error) *svcerrors.ServiceError {
 	return svcerrors.NewInternalError(codeInternalPartialInsightPublisherFailed, fmt.Errorf("partialInsightPublisherFailed: %w", cause))
 }
+
+// errInternalOutboxStoreFailed returns an error when an outbox store operation fails.
+func errInternalOutboxStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalOutboxStoreFailed, fmt.Errorf("outboxStoreFailed: %w", cause))
+}
+
+// errInternalAggregateResultStoreFailed returns an error when an aggregate result store operation fails.
+func errInternalAggregateResultStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalAggregateResultStoreFailed, fmt.Errorf("aggregateResultStoreFailed: %w", cause))
+}
+
+// errInternalRejectedEntryStoreFailed returns an error when quarantining rejected entries fails.
+func errInternalRejectedEntryStoreFailed(cause error) *svcerrors.ServiceError {
+	return svcerrors.NewInternalError(codeInternalRejectedEntryStoreFailed, fmt.Errorf("rejectedEntryStoreFailed: %w", cause))
+}
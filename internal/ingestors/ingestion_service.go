@@ -1,68 +1,212 @@
 package ingestors
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/netip"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"log-analytics/internal/models"
 	"log-analytics/internal/shared/loggers"
 	"log-analytics/internal/shared/metrics"
+	"log-analytics/internal/shared/svcerrors"
 	"log-analytics/internal/shared/ulid"
 	"log-analytics/internal/stores"
 	"log-analytics/internal/streams"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// Ingestion modes. In strict mode a single invalid entry rejects the whole batch; in partial mode
+// invalid entries are dropped, reported and quarantined while the valid ones are ingested.
+const (
+	ModeStrict  = "strict"
+	ModePartial = "partial"
 )
 
+// Reasons an entry is rejected in partial mode.
 const (
-	maxBatchBytes   = 2 * 1024 * 1024
-	maxPathLen      = 2048
-	maxUserAgentLen = 1024
+	rejectReasonInvalidJSON          = "invalid_json"
+	rejectReasonInvalidEntry         = "invalid_entry"
+	rejectReasonFieldTooLong         = "field_too_long"
+	rejectReasonReceivedAtOutOfRange = "received_at_out_of_range"
 )
 
 const (
-	FormatJSON = "json"
+	EncodingIdentity = "identity"
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
 )
 
+// maxReportedLineErrors caps the number of per-entry errors reported when a batch is rejected.
+const maxReportedLineErrors = 10
+
+// Bounds of the optional log entry fields. A host name is at most 253 characters, plus room for a port.
+const (
+	minStatus     = 100
+	maxStatus     = 599
+	maxHostLength = 260
+
+	maxUserIDLength = 256
+
+	maxAttributes           = 32
+	maxAttributeKeyLength   = 64
+	maxAttributeValueLength = 256
+)
+
+var errBodyLimitExceeded = errors.New("body limit exceeded")
+
+// IngestionLimits bounds what a single batch may contain, and whether entries breaking them reject the batch.
+type IngestionLimits struct {
+	MaxBatchBytes      int           // decoded body size
+	MaxEntries         int           // log entries per batch
+	MaxPathLength      int           // characters
+	MaxUserAgentLength int           // characters
+	MaxReceivedAtSkew  time.Duration // allowed distance of receivedAt from the server clock, 0 disables the check
+	PartialAccept      bool          // ingest in partial mode unless the request asks for strict
+}
+
+// DefaultIngestionLimits returns the limits used when none are configured.
+func DefaultIngestionLimits() IngestionLimits {
+	return IngestionLimits{
+		MaxBatchBytes:      2 * 1024 * 1024,
+		MaxEntries:         10000,
+		MaxPathLength:      2048,
+		MaxUserAgentLength: 1024,
+	}
+}
+
+// logEntryPayload is the wire format of a log entry. Pointer fields tell a missing field from an empty one.
+type logEntryPayload struct {
+	ReceivedAt *string `json:"receivedAt"`
+	Method     *string `json:"method"`
+	Path       *string `json:"path"`
+	UserAgent  *string `json:"userAgent"`
+
+	// Optional
+	Status        *int     `json:"status"`
+	DurationMs    *float64 `json:"durationMs"`
+	ResponseBytes *int64   `json:"responseBytes"`
+	ClientIP      *string  `json:"clientIp"`
+	Host          *string  `json:"host"`
+	UserID        *string  `json:"userId"`
+
+	Attributes map[string]string `json:"attributes"`
+}
+
+// limitedReader fails with errBodyLimitExceeded as soon as more than remaining bytes are read.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, errBodyLimitExceeded
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, errBodyLimitExceeded
+	}
+	return n, err
+}
+
+// formatBytes renders a byte size for error messages, e.g. 2MB or 1500 bytes.
+func formatBytes(n int) string {
+	const mb = 1024 * 1024
+	if n%mb == 0 {
+		return fmt.Sprintf("%dMB", n/mb)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
 // IngestResult represents the result of a batch ingestion operation.
 type IngestResult struct {
 	BatchID     string
 	StoredCount int
+	WindowSize  models.WindowSize       // the default window size, first in the configured list
+	Windows     []time.Time             // starts of the default size windows the batch contributed to, ascending
+	Rejected    []*models.RejectedEntry // entries dropped in partial mode
 }
 
-//go:generate mockgen -source=service.go -destination=./mocks/ingestion_service_mock.go -package=mocks
+//go:generate mockgen -source=ingestion_service.go -destination=./mocks/ingestion_service_mock.go -package=mocks
 type IngestionService interface {
-	// IngestBatch processes a batch of log entries from JSON format.
-	IngestBatch(ctx context.Context, customerID string, idempotencyKey string, format string, r io.Reader) (*IngestResult, error)
+	// IngestBatch processes a batch of log entries in JSON array or NDJSON format.
+	// contentEncoding is empty, "identity", "gzip" or "zstd"; the size limit applies to the decoded body.
+	// mode is empty, "strict" or "partial"; empty uses the customer's configured mode.
+	IngestBatch(ctx context.Context, customerID string, idempotencyKey string, format string, contentEncoding string, mode string, r io.Reader) (*IngestResult, error)
 }
 
 type ingestionService struct {
-	batchSummarizer        BatchSummarizer
-	batchStore             stores.LogBatchStore
-	partialInsightProducer streams.PartialInsightProducer
+	batchStore         stores.LogBatchStore
+	outboxStore        stores.OutboxStore
+	rejectedEntryStore stores.RejectedEntryStore
+	batchPublisher     *batchPublisher
+	pendingTimeout     time.Duration
+	limits             IngestionLimits
+	customerLimits     map[string]IngestionLimits
 }
 
-func NewIngestionService(batchSummarizer BatchSummarizer, batchStore stores.LogBatchStore, partialInsightProducer streams.PartialInsightProducer) IngestionService {
+// NewIngestionService creates an IngestionService. pendingTimeout is how long a stored but unpublished batch
+// is assumed to still be in flight; a retry of its idempotency key after that resumes publishing.
+// customerLimits replaces limits for the customers it contains.
+func NewIngestionService(batchSummarizer BatchSummarizer, batchStore stores.LogBatchStore, outboxStore stores.OutboxStore, rejectedEntryStore stores.RejectedEntryStore, partialInsightProducer streams.PartialInsightProducer, pendingTimeout time.Duration, limits IngestionLimits, customerLimits map[string]IngestionLimits) IngestionService {
 	return &ingestionService{
-		batchSummarizer:        batchSummarizer,
-		batchStore:             batchStore,
-		partialInsightProducer: partialInsightProducer,
+		batchStore:         batchStore,
+		outboxStore:        outboxStore,
+		rejectedEntryStore: rejectedEntryStore,
+		batchPublisher: &batchPublisher{
+			batchSummarizer:        batchSummarizer,
+			outboxStore:            outboxStore,
+			partialInsightProducer: partialInsightProducer,
+		},
+		pendingTimeout: pendingTimeout,
+		limits:         limits,
+		customerLimits: customerLimits,
+	}
+}
+
+// limitsFor returns the ingestion limits that apply to customerID.
+func (s *ingestionService) limitsFor(customerID string) IngestionLimits {
+	if limits, ok := s.customerLimits[customerID]; ok {
+		return limits
 	}
+	return s.limits
 }
 
-func (s *ingestionService) IngestBatch(ctx context.Context, customerID string, idempotencyKey string, format string, r io.Reader) (*IngestResult, error) {
+func (s *ingestionService) IngestBatch(ctx context.Context, customerID string, idempotencyKey string, format string, contentEncoding string, mode string, r io.Reader) (*IngestResult, error) {
 	logger := loggers.Ctx(ctx)
-	logger.Debug().Msgf("started ingesting batch with customer ID: %s, idempotency key: %s, format: %s", customerID, idempotencyKey, format)
+	logger.Debug().Msgf("started ingesting batch with customer ID: %s, idempotency key: %s, format: %s, encoding: %s, mode: %s", customerID, idempotencyKey, format, contentEncoding, mode)
 
-	logEntries, err := s.validateLogBatch(customerID, format, r)
+	// The idempotency key becomes the batch ID, a segment of the batch, outbox and status keys
+	batchID := strings.TrimSpace(idempotencyKey)
+	if batchID != "" && !models.IsKeySegmentID(batchID) {
+		return nil, errValidationFailed("idempotency key must be 1 to 64 letters, digits, underscores and hyphens starting with a letter or digit", nil)
+	}
+
+	logEntries, rejected, err := s.validateLogBatch(customerID, format, contentEncoding, mode, r)
 	if err != nil {
 		return nil, err
 	}
 
-	batchID := strings.TrimSpace(idempotencyKey)
 	if batchID == "" {
 		batchID = ulid.NewULID()
 	}
@@ -70,161 +214,512 @@ func (s *ingestionService) IngestBatch(ctx context.Context, customerID string, i
 	logBatch := &models.LogBatch{
 		BatchID:    batchID,
 		CustomerID: customerID,
+		IngestedAt: time.Now().UTC(),
 		Entries:    logEntries,
 	}
 
+	// Quarantine rejected entries before storing the batch, so an accepted batch never loses them
+	if len(rejected) > 0 {
+		err = s.rejectedEntryStore.Put(ctx, &models.RejectedBatch{
+			BatchID:    batchID,
+			CustomerID: customerID,
+			RejectedAt: logBatch.IngestedAt,
+			Entries:    rejected,
+		})
+		if err != nil {
+			return nil, errInternalRejectedEntryStoreFailed(err)
+		}
+	}
+
+	// Mark the batch pending before storing it, so the outbox relay republishes a stored batch even if
+	// the process dies right after the store; a marker without a stored batch is dropped by the relay
+	err = s.outboxStore.MarkPending(ctx, customerID, batchID)
+	if err != nil {
+		return nil, errInternalOutboxStoreFailed(err)
+	}
+
 	// Store the log batch
 	err = s.batchStore.Put(ctx, logBatch)
 	if err != nil {
 		if errors.Is(err, stores.ErrLogBatchAlreadyExist) {
-			svcError := errLogBatchAlreadyProcessed(err)
-			metricBatchIngestedTotal.WithLabelValues(svcError.Code).Inc()
-			return nil, svcError
+			return s.resumeLogBatch(ctx, customerID, batchID, rejected, err)
+		}
+		if clearErr := s.outboxStore.ClearPending(ctx, customerID, batchID); clearErr != nil {
+			logger.Error().Err(clearErr).Msgf("failed to clear pending marker of unstored batch %s", batchID)
 		}
 		return nil, errInternalLogBatchStoreFailed(err)
 	}
+	for _, rejectedEntry := range rejected {
+		metricEntryRejectedTotal.WithLabelValues(rejectedEntry.Reason).Inc()
+	}
 
 	// create summary and publish
-	batchSummary := s.batchSummarizer.Summarize(logBatch)
+	batchSummaries, err := s.batchPublisher.publish(ctx, logBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	metricBatchIngestedTotal.WithLabelValues(metrics.ValueNoError).Inc()
+	return newIngestResult(logBatch, batchSummaries, rejected), nil
+}
 
-	// publish the partial insight
-	err = s.partialInsightProducer.Produce(ctx, batchSummary)
+// resumeLogBatch handles a retried idempotency key. A batch whose partial insights were acknowledged is a
+// true duplicate; otherwise publishing resumes from the stored batch, unless the batch was stored so recently
+// that the original request may still be publishing it.
+func (s *ingestionService) resumeLogBatch(ctx context.Context, customerID string, batchID string, rejected []*models.RejectedEntry, cause error) (*IngestResult, error) {
+	published, err := s.outboxStore.IsPublished(ctx, customerID, batchID)
 	if err != nil {
-		return nil, errInternalPartialInsightPublisherFailed(err)
+		return nil, errInternalOutboxStoreFailed(err)
+	}
+	if published {
+		// Drop the marker written before the duplicate store attempt
+		if err := s.outboxStore.ClearPending(ctx, customerID, batchID); err != nil {
+			loggers.Ctx(ctx).Error().Err(err).Msgf("failed to clear pending marker of published batch %s", batchID)
+		}
+		svcError := errLogBatchAlreadyProcessed(cause)
+		metricBatchIngestedTotal.WithLabelValues(svcError.Code).Inc()
+		return nil, svcError
 	}
 
+	storedBatch, err := s.batchStore.Get(ctx, customerID, batchID)
+	if err != nil {
+		return nil, errInternalLogBatchStoreFailed(err)
+	}
+	if time.Since(storedBatch.IngestedAt) < s.pendingTimeout {
+		svcError := errLogBatchInProgress(cause)
+		metricBatchIngestedTotal.WithLabelValues(svcError.Code).Inc()
+		return nil, svcError
+	}
+
+	batchSummaries, err := s.batchPublisher.publish(ctx, storedBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	loggers.Ctx(ctx).Info().Msgf("resumed publishing of batch %s for customer %s", batchID, customerID)
+	metricBatchPublishResumedTotal.WithLabelValues(resumeSourceIngestion).Inc()
 	metricBatchIngestedTotal.WithLabelValues(metrics.ValueNoError).Inc()
-	return &IngestResult{}, nil
+	return newIngestResult(storedBatch, batchSummaries, rejected), nil
+}
+
+// newIngestResult describes a published batch from the summary of its default window size.
+func newIngestResult(logBatch *models.LogBatch, batchSummaries []*models.BatchSummary, rejected []*models.RejectedEntry) *IngestResult {
+	result := &IngestResult{
+		BatchID:     logBatch.BatchID,
+		StoredCount: len(logBatch.Entries),
+		Rejected:    rejected,
+	}
+	if len(batchSummaries) == 0 {
+		return result
+	}
+
+	batchSummary := batchSummaries[0]
+	windows := make([]time.Time, 0, len(batchSummary.ByWindowStart))
+	for windowKey := range batchSummary.ByWindowStart {
+		windowStart, err := time.Parse(time.RFC3339, windowKey)
+		if err != nil {
+			continue
+		}
+		windows = append(windows, windowStart)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Before(windows[j]) })
+
+	result.WindowSize = batchSummary.WindowSize
+	result.Windows = windows
+	return result
 }
 
-func (s *ingestionService) validateLogBatch(customerID string, format string, r io.Reader) ([]*models.LogEntry, error) {
+// validateLogBatch decodes and validates the entries of a batch. In partial mode it also returns the rejected entries.
+func (s *ingestionService) validateLogBatch(customerID string, format string, contentEncoding string, mode string, r io.Reader) ([]*models.LogEntry, []*models.RejectedEntry, error) {
 	if customerID == "" {
-		return nil, errValidationFailed("customerID is required", nil)
+		return nil, nil, errValidationFailed("customerID is required", nil)
 	}
 
 	// Handle nil reader
 	if r == nil {
-		return nil, errValidationFailed("empty request body", nil)
+		return nil, nil, errValidationFailed("empty request body", nil)
+	}
+
+	// Normalize format to lowercase for comparison
+	formatLower := strings.ToLower(string(format))
+
+	// Pick the parser based on format (using contains for flexible matching).
+	// NDJSON is checked first because "application/x-ndjson" also contains "json".
+	var parse func(io.Reader, IngestionLimits, bool) ([]*models.LogEntry, []*models.RejectedEntry, error)
+	switch {
+	case strings.Contains(formatLower, FormatNDJSON):
+		parse = s.parseNDJSON
+	case strings.Contains(formatLower, FormatJSON):
+		parse = s.parseJSON
+	default:
+		return nil, nil, errValidationFailed(fmt.Sprintf("unsupported input format: %q", format), nil)
 	}
 
-	// Read with size limit
-	buf, err := s.readWithLimit(r, maxBatchBytes)
+	limits := s.limitsFor(customerID)
+	partial, err := s.isPartialMode(mode, limits)
 	if err != nil {
-		return nil, errValidationFailed("batch too large: must be <= 2MB", nil)
+		return nil, nil, err
 	}
 
-	// Normalize format to lowercase for comparison
-	formatLower := strings.ToLower(string(format))
+	// Decompress before reading, so the size limit applies to the decoded body
+	decoded, closeDecoder, err := s.decodeBody(contentEncoding, r, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeDecoder()
 
-	// Parse based on format (using contains for flexible matching)
-	var entries []*models.LogEntry
-	if strings.Contains(formatLower, FormatJSON) {
-		entries, err = s.parseJSON(buf)
-		if err != nil {
-			return nil, err
+	// Entries are decoded while the body streams through the size limit
+	body := &limitedReader{r: decoded, remaining: int64(limits.MaxBatchBytes)}
+	entries, rejected, err := parse(body, limits, partial)
+	if err != nil {
+		// An oversized batch is reported as such even if it also fails to parse
+		if _, drainErr := io.Copy(io.Discard, body); drainErr != nil {
+			err = drainErr
+		}
+		if s.isBodyLimitExceeded(err) {
+			return nil, nil, errBatchTooLarge(limits.MaxBatchBytes)
+		}
+		if _, ok := svcerrors.AsServiceError(err); ok {
+			return nil, nil, err
 		}
-	} else {
-		return nil, errValidationFailed(fmt.Sprintf("unsupported input format: %q", format), nil)
+		if decoded == r {
+			return nil, nil, errValidationFailed("unable to read request body", err)
+		}
+		return nil, nil, errValidationFailed(fmt.Sprintf("invalid %s request body", strings.ToLower(contentEncoding)), err)
 	}
 
 	// Validate that entries are not empty
 	if len(entries) == 0 {
-		return nil, errValidationFailed("log entries cannot be empty", nil)
+		return nil, nil, errValidationFailed("log entries cannot be empty", nil)
 	}
 
-	return entries, nil
+	return entries, rejected, nil
 }
 
-// readWithLimit reads up to max+1 bytes from r and checks if it exceeds max.
-func (s *ingestionService) readWithLimit(r io.Reader, max int) ([]byte, error) {
-	limitedReader := io.LimitReader(r, int64(max+1))
-	buf := make([]byte, max+1)
-	n, err := limitedReader.Read(buf)
-	if err != nil && err != io.EOF {
-		return nil, err
+// isPartialMode resolves the requested ingestion mode, falling back to the customer's configured mode.
+func (s *ingestionService) isPartialMode(mode string, limits IngestionLimits) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		return limits.PartialAccept, nil
+	case ModeStrict:
+		return false, nil
+	case ModePartial:
+		return true, nil
+	default:
+		return false, errValidationFailed(fmt.Sprintf("unsupported ingestion mode: %q", mode), nil)
 	}
+}
 
-	// If we read more than max bytes, the batch is too large
-	if n > max {
-		return nil, errValidationFailed("batch too large", nil)
+// decodeBody wraps r with a decompressor for contentEncoding. The returned close func releases the decompressor.
+func (s *ingestionService) decodeBody(contentEncoding string, r io.Reader, limits IngestionLimits) (io.Reader, func(), error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", EncodingIdentity:
+		return r, func() {}, nil
+	case EncodingGzip:
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, errValidationFailed("invalid gzip request body", err)
+		}
+		return gzipReader, func() { _ = gzipReader.Close() }, nil
+	case EncodingZstd:
+		zstdReader, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limits.MaxBatchBytes)+1))
+		if err != nil {
+			return nil, nil, errValidationFailed("invalid zstd request body", err)
+		}
+		return zstdReader, zstdReader.Close, nil
+	default:
+		return nil, nil, errValidationFailed(fmt.Sprintf("unsupported content encoding: %q", contentEncoding), nil)
 	}
+}
 
-	return buf[:n], nil
+func (s *ingestionService) isBodyLimitExceeded(err error) bool {
+	// The zstd decoder enforces the limit itself for frames that declare a larger size
+	return errors.Is(err, errBodyLimitExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded)
 }
 
-// parseJSON parses buf as a JSON array of objects into LogEntry slice.
-func (s *ingestionService) parseJSON(buf []byte) ([]*models.LogEntry, error) {
-	var arr []map[string]any
-	if err := json.Unmarshal(buf, &arr); err != nil {
-		return nil, errValidationFailed("invalid json", err)
+// parseJSON decodes a JSON array of log entries token by token, so only one entry is held
+// in its wire form at a time. Read errors are returned as is; JSON and entry errors as ServiceErrors.
+// In partial mode invalid entries are returned as rejected instead; malformed JSON still fails the batch.
+func (s *ingestionService) parseJSON(r io.Reader, limits IngestionLimits, partial bool) ([]*models.LogEntry, []*models.RejectedEntry, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, s.jsonError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, nil, errValidationFailed("invalid json: expected an array of log entries", nil)
 	}
 
-	entries := make([]*models.LogEntry, 0, len(arr))
-	for i, item := range arr {
-		entry, err := s.jsonObjectToLogEntry(item, i)
+	var entries []*models.LogEntry
+	var rejected []*models.RejectedEntry
+	for index := 0; decoder.More(); index++ {
+		if index >= limits.MaxEntries {
+			return nil, nil, errTooManyEntries(limits.MaxEntries)
+		}
+		label := fmt.Sprintf("item at index %d", index)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, nil, s.jsonError(err)
+		}
+		entry, err := s.rawToLogEntry(raw, label, limits)
 		if err != nil {
-			return nil, err
+			if !partial {
+				return nil, nil, err
+			}
+			rejected = append(rejected, s.newRejectedEntry(index, raw, rejectReasonFor(err), err))
+			continue
 		}
 		entries = append(entries, entry)
 	}
 
-	return entries, nil
+	// Closing bracket, then nothing but whitespace
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, s.jsonError(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			return nil, nil, errValidationFailed("invalid json: unexpected data after array", nil)
+		}
+		return nil, nil, s.jsonError(err)
+	}
+
+	// Nothing left to ingest, report why
+	if len(entries) == 0 && len(rejected) > 0 {
+		return nil, nil, s.rejectedEntriesToServiceError(FormatJSON, rejected)
+	}
+	return entries, rejected, nil
 }
 
-// jsonObjectToLogEntry converts a JSON object map to LogEntry.
-func (s *ingestionService) jsonObjectToLogEntry(obj map[string]any, index int) (*models.LogEntry, error) {
-	entry := &models.LogEntry{}
+// parseNDJSON decodes newline-delimited JSON log entries line by line. Blank lines are skipped.
+// Every invalid line is reported (up to maxReportedLineErrors) in a single validation error.
+// In partial mode invalid lines are returned as rejected instead, unless no valid line is left.
+func (s *ingestionService) parseNDJSON(r io.Reader, limits IngestionLimits, partial bool) ([]*models.LogEntry, []*models.RejectedEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), limits.MaxBatchBytes+1)
 
-	// Parse receivedAt
-	if receivedAtVal, ok := obj["receivedAt"]; ok {
-		receivedAtStr, ok := receivedAtVal.(string)
-		if !ok {
-			return entry, errValidationFailed(fmt.Sprintf("item at index %d: receivedAt must be a string", index), nil)
+	var entries []*models.LogEntry
+	var rejected []*models.RejectedEntry
+
+	for lineNumber, index := 1, 0; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if index >= limits.MaxEntries {
+			return nil, nil, errTooManyEntries(limits.MaxEntries)
 		}
-		receivedAt, err := s.parseTime(receivedAtStr, index)
+		label := fmt.Sprintf("line %d", lineNumber)
+
+		entry, err := s.rawToLogEntry(line, label, limits)
 		if err != nil {
-			return entry, err
+			// The raw line is only kept when it gets quarantined
+			var raw []byte
+			if partial {
+				raw = line
+			}
+			rejected = append(rejected, s.newRejectedEntry(index, raw, rejectReasonFor(err), err))
+		} else {
+			entries = append(entries, entry)
 		}
-		entry.ReceivedAt = receivedAt
-	} else {
-		return entry, errValidationFailed(fmt.Sprintf("item at index %d: missing receivedAt", index), nil)
+		index++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	// Parse method
-	if methodVal, ok := obj["method"]; ok {
-		if method, ok := methodVal.(string); ok {
-			entry.Method = method
-		} else {
-			return entry, errValidationFailed(fmt.Sprintf("item at index %d: method must be a string", index), nil)
+	if len(rejected) > 0 && (!partial || len(entries) == 0) {
+		return nil, nil, s.rejectedEntriesToServiceError(FormatNDJSON, rejected)
+	}
+	return entries, rejected, nil
+}
+
+// rawToLogEntry decodes a single JSON log entry.
+func (s *ingestionService) rawToLogEntry(raw []byte, label string, limits IngestionLimits) (*models.LogEntry, error) {
+	var payload logEntryPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			return nil, s.typeError(typeErr, label)
 		}
-	} else {
-		return entry, errValidationFailed(fmt.Sprintf("item at index %d: missing method", index), nil)
+		return nil, errValidationFailed(label+": invalid json", err)
+	}
+	return s.payloadToLogEntry(&payload, label, limits)
+}
+
+// newRejectedEntry describes an entry that failed validation. err is always a ServiceError.
+func (s *ingestionService) newRejectedEntry(index int, raw []byte, reason string, err error) *models.RejectedEntry {
+	rejectedEntry := &models.RejectedEntry{
+		Index:  index,
+		Reason: reason,
+		Code:   codeValidationFailed,
+		Raw:    string(raw),
+	}
+	if svcErr, ok := svcerrors.AsServiceError(err); ok {
+		rejectedEntry.Code = svcErr.Code
+		rejectedEntry.Message = svcErr.Message
 	}
+	return rejectedEntry
+}
 
-	// Parse path
-	if pathVal, ok := obj["path"]; ok {
-		if path, ok := pathVal.(string); ok {
-			entry.Path = path
-		} else {
-			return entry, errValidationFailed(fmt.Sprintf("item at index %d: path must be a string", index), nil)
+// rejectedEntriesToServiceError joins the errors of rejected entries into one error. It keeps the entries'
+// error code when they all share one, and falls back to the generic validation code otherwise.
+func (s *ingestionService) rejectedEntriesToServiceError(format string, rejected []*models.RejectedEntry) *svcerrors.ServiceError {
+	code := rejected[0].Code
+	messages := make([]string, 0, maxReportedLineErrors)
+	for i, rejectedEntry := range rejected {
+		if i < maxReportedLineErrors {
+			messages = append(messages, rejectedEntry.Message)
 		}
-	} else {
-		return entry, errValidationFailed(fmt.Sprintf("item at index %d: missing path", index), nil)
+		if rejectedEntry.Code != code {
+			code = codeValidationFailed
+		}
+	}
+
+	unit := "entries"
+	if format == FormatNDJSON {
+		unit = "lines"
 	}
+	msg := fmt.Sprintf("invalid %s: %s", format, strings.Join(messages, "; "))
+	if len(rejected) > len(messages) {
+		msg += fmt.Sprintf("; and %d more invalid %s", len(rejected)-len(messages), unit)
+	}
+	return svcerrors.NewInvalidArgumentError(code, msg, nil)
+}
+
+// rejectReasonFor labels an entry error for the rejection report and the rejected entries metric.
+func rejectReasonFor(err error) string {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return rejectReasonInvalidJSON
+	}
+	svcErr, ok := svcerrors.AsServiceError(err)
+	if !ok {
+		return rejectReasonInvalidEntry
+	}
+	switch svcErr.Code {
+	case codeFieldTooLong:
+		return rejectReasonFieldTooLong
+	case codeReceivedAtOutOfRange:
+		return rejectReasonReceivedAtOutOfRange
+	default:
+		return rejectReasonInvalidEntry
+	}
+}
+
+// jsonError converts a JSON decoding error into a validation error. Errors from the underlying
+// reader (size limit, decompression) are returned unchanged.
+func (s *ingestionService) jsonError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return errValidationFailed("invalid json", err)
+	}
+	return err
+}
+
+func (s *ingestionService) typeError(typeErr *json.UnmarshalTypeError, label string) error {
+	if typeErr.Field == "" {
+		return errValidationFailed(fmt.Sprintf("%s: must be a JSON object", label), typeErr)
+	}
+	switch typeErr.Type.Kind() {
+	case reflect.Int, reflect.Int64:
+		return errValidationFailed(fmt.Sprintf("%s: %s must be an integer", label, typeErr.Field), typeErr)
+	case reflect.Float64:
+		return errValidationFailed(fmt.Sprintf("%s: %s must be a number", label, typeErr.Field), typeErr)
+	case reflect.Map:
+		return errValidationFailed(fmt.Sprintf("%s: %s must be an object", label, typeErr.Field), typeErr)
+	default:
+		return errValidationFailed(fmt.Sprintf("%s: %s must be a string", label, typeErr.Field), typeErr)
+	}
+}
+
+// payloadToLogEntry converts a decoded wire entry into a normalized and validated LogEntry.
+func (s *ingestionService) payloadToLogEntry(payload *logEntryPayload, label string, limits IngestionLimits) (*models.LogEntry, error) {
+	entry := &models.LogEntry{}
+
+	// Parse receivedAt
+	if payload.ReceivedAt == nil {
+		return entry, errValidationFailed(fmt.Sprintf("%s: missing receivedAt", label), nil)
+	}
+	receivedAt, err := s.parseTime(*payload.ReceivedAt, label)
+	if err != nil {
+		return entry, err
+	}
+	entry.ReceivedAt = receivedAt
+	if limits.MaxReceivedAtSkew > 0 {
+		if skew := time.Since(receivedAt).Abs(); skew > limits.MaxReceivedAtSkew {
+			return entry, errReceivedAtOutOfRange(fmt.Sprintf("%s: receivedAt must be within %s of server time", label, limits.MaxReceivedAtSkew))
+		}
+	}
+
+	// Parse method
+	if payload.Method == nil {
+		return entry, errValidationFailed(fmt.Sprintf("%s: missing method", label), nil)
+	}
+	entry.Method = *payload.Method
+
+	// Parse path
+	if payload.Path == nil {
+		return entry, errValidationFailed(fmt.Sprintf("%s: missing path", label), nil)
+	}
+	entry.Path = *payload.Path
 
 	// Parse userAgent
-	if userAgentVal, ok := obj["userAgent"]; ok {
-		if userAgent, ok := userAgentVal.(string); ok {
-			entry.UserAgent = userAgent
-		} else {
-			return entry, errValidationFailed(fmt.Sprintf("item at index %d: userAgent must be a string", index), nil)
+	if payload.UserAgent == nil {
+		return entry, errValidationFailed(fmt.Sprintf("%s: missing userAgent", label), nil)
+	}
+	entry.UserAgent = *payload.UserAgent
+
+	// Optional fields
+	if payload.Status != nil {
+		if *payload.Status < minStatus || *payload.Status > maxStatus {
+			return entry, errValidationFailed(fmt.Sprintf("%s: status must be between %d and %d", label, minStatus, maxStatus), nil)
+		}
+		entry.Status = *payload.Status
+	}
+	if payload.DurationMs != nil {
+		if *payload.DurationMs < 0 {
+			return entry, errValidationFailed(fmt.Sprintf("%s: durationMs must not be negative", label), nil)
+		}
+		entry.DurationMs = payload.DurationMs
+	}
+	if payload.ResponseBytes != nil {
+		if *payload.ResponseBytes < 0 {
+			return entry, errValidationFailed(fmt.Sprintf("%s: responseBytes must not be negative", label), nil)
+		}
+		entry.ResponseBytes = *payload.ResponseBytes
+	}
+	if payload.ClientIP != nil {
+		addr, err := netip.ParseAddr(strings.TrimSpace(*payload.ClientIP))
+		if err != nil {
+			return entry, errValidationFailed(fmt.Sprintf("%s: clientIp must be an IPv4 or IPv6 address", label), err)
+		}
+		entry.ClientIP = addr.Unmap().String()
+	}
+	if payload.Host != nil {
+		entry.Host = *payload.Host
+	}
+	if payload.UserID != nil {
+		entry.UserID = *payload.UserID
+	}
+	if payload.Attributes != nil {
+		if len(payload.Attributes) > maxAttributes {
+			return entry, errValidationFailed(fmt.Sprintf("%s: at most %d attributes are allowed", label, maxAttributes), nil)
+		}
+		// Trim here rather than in normalizeLogEntry, so names differing only in spaces are rejected instead of
+		// one silently replacing the other
+		entry.Attributes = make(map[string]string, len(payload.Attributes))
+		for key, value := range payload.Attributes {
+			key = strings.TrimSpace(key)
+			if _, ok := entry.Attributes[key]; ok {
+				return entry, errValidationFailed(fmt.Sprintf("%s: duplicate attribute name %q", label, key), nil)
+			}
+			entry.Attributes[key] = strings.TrimSpace(value)
 		}
-	} else {
-		return entry, errValidationFailed(fmt.Sprintf("item at index %d: missing userAgent", index), nil)
 	}
 
 	s.normalizeLogEntry(entry)
-	if err := s.validateLogEntry(entry, index); err != nil {
+	if err := s.validateLogEntry(entry, label, limits); err != nil {
 		return entry, err
 	}
 	return entry, nil
@@ -234,20 +729,45 @@ func (s *ingestionService) normalizeLogEntry(entry *models.LogEntry) {
 	entry.Path = strings.TrimSpace(entry.Path)
 	entry.Method = strings.ToUpper(strings.TrimSpace(entry.Method))
 	entry.UserAgent = strings.TrimSpace(entry.UserAgent)
+	entry.Host = strings.ToLower(strings.TrimSpace(entry.Host))
+	entry.UserID = strings.TrimSpace(entry.UserID)
 }
 
-func (s *ingestionService) validateLogEntry(e *models.LogEntry, index int) error {
-	if len(e.Path) > maxPathLen {
-		return errValidationFailed(fmt.Sprintf("item at index %d: path too long: max %d characters", index, maxPathLen), nil)
+func (s *ingestionService) validateLogEntry(e *models.LogEntry, label string, limits IngestionLimits) error {
+	if len(e.Path) > limits.MaxPathLength {
+		return errFieldTooLong(fmt.Sprintf("%s: path too long: max %d characters", label, limits.MaxPathLength))
+	}
+	if len(e.UserAgent) > limits.MaxUserAgentLength {
+		return errFieldTooLong(fmt.Sprintf("%s: userAgent too long: max %d characters", label, limits.MaxUserAgentLength))
+	}
+	if len(e.Host) > maxHostLength {
+		return errFieldTooLong(fmt.Sprintf("%s: host too long: max %d characters", label, maxHostLength))
+	}
+	if len(e.UserID) > maxUserIDLength {
+		return errFieldTooLong(fmt.Sprintf("%s: userId too long: max %d characters", label, maxUserIDLength))
 	}
-	if len(e.UserAgent) > maxUserAgentLen {
-		return errValidationFailed(fmt.Sprintf("item at index %d: userAgent too long: max %d characters", index, maxUserAgentLen), nil)
+	if strings.ContainsAny(e.Host, " \t/") {
+		return errValidationFailed(fmt.Sprintf("%s: host must be a host name with an optional port", label), nil)
+	}
+	for key, value := range e.Attributes {
+		if key == "" {
+			return errValidationFailed(fmt.Sprintf("%s: attribute names must not be empty", label), nil)
+		}
+		if len(key) > maxAttributeKeyLength {
+			return errFieldTooLong(fmt.Sprintf("%s: attribute name too long: max %d characters", label, maxAttributeKeyLength))
+		}
+		if !attributeNamePattern.MatchString(key) {
+			return errValidationFailed(fmt.Sprintf("%s: attribute name must be letters, digits and underscores, starting with a letter: %q", label, key), nil)
+		}
+		if len(value) > maxAttributeValueLength {
+			return errFieldTooLong(fmt.Sprintf("%s: attributes.%s too long: max %d characters", label, key, maxAttributeValueLength))
+		}
 	}
 	return nil
 }
 
 // parseTime parses a time string in RFC3339 or ISO-8601 format.
-func (s *ingestionService) parseTime(timeStr string, index int) (time.Time, error) {
+func (s *ingestionService) parseTime(timeStr string, label string) (time.Time, error) {
 
 	// Try ISO-8601 with milliseconds
 	t, err := time.Parse("2006-01-02T15:04:05.000Z", timeStr)
@@ -261,5 +781,5 @@ func (s *ingestionService) parseTime(timeStr string, index int) (time.Time, erro
 		return t, nil
 	}
 
-	return time.Time{}, errValidationFailed(fmt.Sprintf("item at index %d: invalid time format: %s", index, timeStr), nil)
+	return time.Time{}, errValidationFailed(fmt.Sprintf("%s: invalid time format: %s", label, timeStr), nil)
 }
@@ -0,0 +1,105 @@
+package ingestors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"log-analytics/internal/models"
+)
+
+// Benchmarks compare the streaming decoder against the previous map-based path, which unmarshalled the
+// whole body into []map[string]any before converting each map. Run with:
+//
+//	go test ./internal/ingestors -run '^$' -bench ParseJSON -benchmem
+
+func BenchmarkParseJSON_Streaming(b *testing.B) {
+	for _, entryCount := range []int{100, 10_000} {
+		body := benchmarkBatchBody(entryCount)
+		limits := DefaultIngestionLimits()
+		limits.MaxBatchBytes = len(body)
+		limits.MaxEntries = entryCount
+		service := &ingestionService{limits: limits}
+
+		b.Run(fmt.Sprintf("entries=%d", entryCount), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				entries, _, err := service.parseJSON(bytes.NewReader(body), limits, false)
+				if err != nil || len(entries) != entryCount {
+					b.Fatalf("unexpected result: %d entries, err=%v", len(entries), err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseJSON_MapBaseline(b *testing.B) {
+	for _, entryCount := range []int{100, 10_000} {
+		body := benchmarkBatchBody(entryCount)
+
+		b.Run(fmt.Sprintf("entries=%d", entryCount), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				entries, err := mapBaselineParseJSON(body)
+				if err != nil || len(entries) != entryCount {
+					b.Fatalf("unexpected result: %d entries, err=%v", len(entries), err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkBatchBody(entryCount int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < entryCount; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"receivedAt":"2025-12-21T14:%02d:%02d.000Z","method":"GET","path":"/api/items/%d","userAgent":"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"}`,
+			i/60%60, i%60, i%50)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// mapBaselineParseJSON is the previous parsing path, kept here only as the benchmark baseline.
+func mapBaselineParseJSON(buf []byte) ([]*models.LogEntry, error) {
+	var arr []map[string]any
+	if err := json.Unmarshal(buf, &arr); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.LogEntry, 0, len(arr))
+	for i, item := range arr {
+		entry := &models.LogEntry{}
+		receivedAt, ok := item["receivedAt"].(string)
+		if !ok {
+			return nil, fmt.Errorf("item at index %d: receivedAt must be a string", i)
+		}
+		t, err := time.Parse("2006-01-02T15:04:05.000Z", receivedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.ReceivedAt = t
+		if entry.Method, ok = item["method"].(string); !ok {
+			return nil, fmt.Errorf("item at index %d: method must be a string", i)
+		}
+		if entry.Path, ok = item["path"].(string); !ok {
+			return nil, fmt.Errorf("item at index %d: path must be a string", i)
+		}
+		if entry.UserAgent, ok = item["userAgent"].(string); !ok {
+			return nil, fmt.Errorf("item at index %d: userAgent must be a string", i)
+		}
+		entry.Path = strings.TrimSpace(entry.Path)
+		entry.Method = strings.ToUpper(strings.TrimSpace(entry.Method))
+		entry.UserAgent = strings.TrimSpace(entry.UserAgent)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
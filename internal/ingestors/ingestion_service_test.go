@@ -2,9 +2,13 @@ package ingestors_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"log-analytics/internal/ingestors"
 	ingestormocks "log-analytics/internal/ingestors/mocks"
@@ -14,6 +18,7 @@ import (
 	storemocks "log-analytics/internal/stores/mocks"
 	streammocks "log-analytics/internal/streams/mocks"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -28,11 +33,13 @@ func TestIngestBatch_ErrValidationFailed_InvalidFormat(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	ctx := context.Background()
 	body := bytes.NewReader([]byte(`{}`))
-	result, err := service.IngestBatch(ctx, "customer1", "key1", "xml", body)
+	result, err := service.IngestBatch(ctx, "customer1", "key1", "xml", "", "", body)
 
 	require.Error(t, err, "expected error")
 	svcErr, ok := svcerrors.AsServiceError(err)
@@ -51,11 +58,13 @@ func TestIngestBatch_ErrValidationFailed_InvalidJSON(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	ctx := context.Background()
 	invalidJSON := bytes.NewReader([]byte(`{invalid json}`))
-	result, err := service.IngestBatch(ctx, "customer1", "key1", "json", invalidJSON)
+	result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", invalidJSON)
 
 	require.Error(t, err, "expected error")
 	svcErr, ok := svcerrors.AsServiceError(err)
@@ -74,19 +83,21 @@ func TestIngestBatch_ErrValidationFailed_BatchTooLarge(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	ctx := context.Background()
 	// Create body with size 2*1024*1024 + 1 bytes
 	largeBody := make([]byte, 2*1024*1024+1)
 	body := bytes.NewReader(largeBody)
 
-	_, err := service.IngestBatch(ctx, "customer1", "key1", "json", body)
+	_, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
 
 	require.Error(t, err, "expected error")
 	svcErr, ok := svcerrors.AsServiceError(err)
 	require.True(t, ok, "expected ServiceError")
-	assert.Equal(t, "ING_1000", svcErr.Code)
+	assert.Equal(t, "ING_1003", svcErr.Code)
 	assert.Equal(t, "invalid_argument", svcErr.Category)
 	assert.Equal(t, "batch too large: must be <= 2MB", svcErr.Message)
 }
@@ -100,43 +111,139 @@ func TestIngestBatch_ErrValidationFailed_LogEntryValidation(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	tests := []struct {
-		name string
-		json string
+		name         string
+		json         string
+		expectedCode string
 	}{
 		{
-			name: "empty entries",
-			json: `[]`,
+			name:         "empty entries",
+			json:         `[]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "missing receivedAt",
+			json:         `[{"method":"GET","path":"/","userAgent":"test"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "invalid receivedAt format",
+			json:         `[{"receivedAt":"invalid-time","method":"GET","path":"/","userAgent":"test"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "missing method",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","path":"/","userAgent":"test"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "missing path",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","userAgent":"test"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "missing userAgent",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "path exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"` + strings.Repeat("a", 2049) + `","userAgent":"test"}]`,
+			expectedCode: "ING_1005",
+		},
+		{
+			name:         "userAgent exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"` + strings.Repeat("a", 1025) + `"}]`,
+			expectedCode: "ING_1005",
+		},
+		{
+			name:         "status out of range",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","status":600}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "status is not an integer",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","status":"200"}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "negative durationMs",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","durationMs":-1}]`,
+			expectedCode: "ING_1000",
 		},
 		{
-			name: "missing receivedAt",
-			json: `[{"method":"GET","path":"/","userAgent":"test"}]`,
+			name:         "negative responseBytes",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","responseBytes":-1}]`,
+			expectedCode: "ING_1000",
 		},
 		{
-			name: "invalid receivedAt format",
-			json: `[{"receivedAt":"invalid-time","method":"GET","path":"/","userAgent":"test"}]`,
+			name:         "invalid clientIp",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","clientIp":"300.1.1.1"}]`,
+			expectedCode: "ING_1000",
 		},
 		{
-			name: "missing method",
-			json: `[{"receivedAt":"2025-12-21T14:21:00.000Z","path":"/","userAgent":"test"}]`,
+			name:         "host exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","host":"` + strings.Repeat("a", 261) + `"}]`,
+			expectedCode: "ING_1005",
 		},
 		{
-			name: "missing path",
-			json: `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","userAgent":"test"}]`,
+			name:         "userId exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","userId":"` + strings.Repeat("a", 257) + `"}]`,
+			expectedCode: "ING_1005",
 		},
 		{
-			name: "missing userAgent",
-			json: `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/"}]`,
+			name:         "attribute value exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{"region":"` + strings.Repeat("a", 257) + `"}}]`,
+			expectedCode: "ING_1005",
 		},
 		{
-			name: "path exceeds max length",
-			json: `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"` + strings.Repeat("a", 2049) + `","userAgent":"test"}]`,
+			name:         "attribute name exceeds max length",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{"` + strings.Repeat("a", 65) + `":"eu"}}]`,
+			expectedCode: "ING_1005",
 		},
 		{
-			name: "userAgent exceeds max length",
-			json: `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"` + strings.Repeat("a", 1025) + `"}]`,
+			name:         "empty attribute name",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{" ":"eu"}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "duplicate attribute name after trimming",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{" region":"eu","region":"us"}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "attribute name with a dot",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{"app.version":"1.2.0"}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "attribute name starting with a digit",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{"1region":"eu"}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "attribute value is not a string",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{"replicas":3}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "attributes is not an object",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":["eu"]}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "too many attributes",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","attributes":{` + manyAttributes(33) + `}}]`,
+			expectedCode: "ING_1000",
+		},
+		{
+			name:         "host with a path",
+			json:         `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","host":"example.com/about"}]`,
+			expectedCode: "ING_1000",
 		},
 	}
 
@@ -144,17 +251,46 @@ func TestIngestBatch_ErrValidationFailed_LogEntryValidation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
 			body := bytes.NewReader([]byte(tt.json))
-			result, err := service.IngestBatch(ctx, "customer1", "key1", "json", body)
+			result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
 
 			require.Error(t, err, "expected error")
 			svcErr, ok := svcerrors.AsServiceError(err)
 			require.True(t, ok, "expected ServiceError")
-			assert.Equal(t, "ING_1000", svcErr.Code)
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
 			assert.Nil(t, result, "expected nil result on error")
 		})
 	}
 }
 
+func TestIngestBatch_ErrValidationFailed_InvalidIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Rejected before anything is stored
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+	for _, idempotencyKey := range []string{"../cus-other/key1", "key/1", `key\1`, "-key1", "key 1", strings.Repeat("k", 65)} {
+		t.Run(idempotencyKey, func(t *testing.T) {
+			body := strings.NewReader(`[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`)
+			result, err := service.IngestBatch(context.Background(), "customer1", idempotencyKey, "json", "", "", body)
+
+			require.Error(t, err)
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok)
+			assert.Equal(t, "ING_1000", svcErr.Code)
+			assert.Equal(t, "invalid_argument", svcErr.Category)
+			assert.Nil(t, result)
+		})
+	}
+}
+
 func TestIngestBatch_ErrBatchPutFailed(t *testing.T) {
 	t.Parallel()
 
@@ -168,7 +304,7 @@ func TestIngestBatch_ErrBatchPutFailed(t *testing.T) {
 		expectedCategory string
 	}{
 		{
-			name:             "log batch already exists",
+			name:             "log batch already processed",
 			putError:         stores.ErrLogBatchAlreadyExist,
 			expectedCode:     "ING_1001",
 			expectedCategory: "resource_conflict",
@@ -186,16 +322,26 @@ func TestIngestBatch_ErrBatchPutFailed(t *testing.T) {
 			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 			batchStore := storemocks.NewMockLogBatchStore(ctrl)
 			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
 
-			batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(tt.putError)
+			gomock.InOrder(
+				outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(nil),
+				batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(tt.putError),
+			)
+			if errors.Is(tt.putError, stores.ErrLogBatchAlreadyExist) {
+				outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", "key1").Return(true, nil)
+			}
+			// The marker written before the failed store is dropped again
+			outboxStore.EXPECT().ClearPending(gomock.Any(), "customer1", "key1").Return(nil)
 
-			service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 			ctx := context.Background()
 			validJSON := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
 			body := bytes.NewReader([]byte(validJSON))
 
-			result, err := service.IngestBatch(ctx, "customer1", "key1", "json", body)
+			result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
 
 			require.Error(t, err, "expected error")
 			svcErr, ok := svcerrors.AsServiceError(err)
@@ -216,19 +362,24 @@ func TestIngestBatch_ErrPartialInsightPublishFailed(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
 
 	batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil)
-	batchSummarizer.EXPECT().Summarize(gomock.Any()).Return(&models.BatchSummary{})
+	outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(nil)
+	batchSummarizer.EXPECT().Summarize(gomock.Any()).Return([]*models.BatchSummary{{}})
 	partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).
 		Return(assert.AnError)
+	// The batch stays pending for the outbox relay
+	outboxStore.EXPECT().MarkPublished(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	ctx := context.Background()
 	validJSON := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
 	body := bytes.NewReader([]byte(validJSON))
 
-	result, err := service.IngestBatch(ctx, "customer1", "key1", "json", body)
+	result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
 
 	require.Error(t, err, "expected error")
 	svcErr, ok := svcerrors.AsServiceError(err)
@@ -247,9 +398,11 @@ func TestIngestBatch_Success(t *testing.T) {
 	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
 	batchStore := storemocks.NewMockLogBatchStore(ctrl)
 	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
 
 	var storedBatch *models.LogBatch
-	var publishedSummary *models.BatchSummary
+	var publishedSummaries []*models.BatchSummary
 	var summarizedBatch *models.LogBatch
 
 	batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).
@@ -262,19 +415,38 @@ func TestIngestBatch_Success(t *testing.T) {
 		Do(func(batch *models.LogBatch) {
 			summarizedBatch = batch
 		}).
-		Return(&models.BatchSummary{
-			BatchID:       "key1",
-			CustomerID:    "customer1",
-			ByWindowStart: map[string]models.WindowAggregates{},
+		Return([]*models.BatchSummary{
+			{
+				BatchID:    "key1",
+				CustomerID: "customer1",
+				WindowSize: models.WindowMinute,
+				ByWindowStart: map[string]models.WindowAggregates{
+					"2025-12-21T14:21:00Z": {},
+				},
+			},
+			{
+				BatchID:    "key1",
+				CustomerID: "customer1",
+				WindowSize: models.WindowHour,
+				ByWindowStart: map[string]models.WindowAggregates{
+					"2025-12-21T14:00:00Z": {},
+				},
+			},
 		})
 
 	partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).
 		Do(func(ctx context.Context, batchSummary *models.BatchSummary) {
-			publishedSummary = batchSummary
+			publishedSummaries = append(publishedSummaries, batchSummary)
 		}).
-		Return(nil)
+		Return(nil).
+		Times(2)
+
+	gomock.InOrder(
+		outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(nil),
+		outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "key1").Return(nil),
+	)
 
-	service := ingestors.NewIngestionService(batchSummarizer, batchStore, partialInsightProducer)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
 
 	ctx := context.Background()
 	customerID := "customer1"
@@ -282,7 +454,7 @@ func TestIngestBatch_Success(t *testing.T) {
 	validJSON := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
 	body := bytes.NewReader([]byte(validJSON))
 
-	result, err := service.IngestBatch(ctx, customerID, idempotencyKey, "json", body)
+	result, err := service.IngestBatch(ctx, customerID, idempotencyKey, "json", "", "", body)
 
 	require.NoError(t, err, "unexpected error")
 	assert.NotNil(t, result, "expected non-nil result")
@@ -290,9 +462,742 @@ func TestIngestBatch_Success(t *testing.T) {
 	// Verify parameters were passed correctly
 	assert.NotNil(t, storedBatch)
 	assert.NotNil(t, summarizedBatch)
-	assert.NotNil(t, publishedSummary)
+	require.Len(t, publishedSummaries, 2)
 	assert.Equal(t, "key1", storedBatch.BatchID)
 	assert.Equal(t, "customer1", storedBatch.CustomerID)
-	assert.Equal(t, "key1", publishedSummary.BatchID)
-	assert.Equal(t, "customer1", publishedSummary.CustomerID)
+	assert.False(t, storedBatch.IngestedAt.IsZero())
+	assert.Equal(t, "key1", publishedSummaries[0].BatchID)
+	assert.Equal(t, "customer1", publishedSummaries[0].CustomerID)
+	assert.Equal(t, models.WindowMinute, publishedSummaries[0].WindowSize)
+	assert.Equal(t, models.WindowHour, publishedSummaries[1].WindowSize)
+
+	assert.Equal(t, "key1", result.BatchID)
+	assert.Equal(t, 1, result.StoredCount)
+	assert.Equal(t, models.WindowMinute, result.WindowSize)
+	assert.Equal(t, []time.Time{time.Date(2025, 12, 21, 14, 21, 0, 0, time.UTC)}, result.Windows)
+}
+
+func TestIngestBatch_Success_OptionalFields(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+
+	var storedBatch *models.LogBatch
+	batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, batch *models.LogBatch) {
+			storedBatch = batch
+		}).
+		Return(nil)
+	batchSummarizer.EXPECT().Summarize(gomock.Any()).Return(nil)
+	outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(nil)
+	outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "key1").Return(nil)
+
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+	body := `[
+		{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test","status":404,"durationMs":12.5,"responseBytes":512,"clientIp":"::ffff:10.0.0.1","host":" API.Example.com:8443 ","userId":" user-1 ","attributes":{" region ":" eu-west-1 ","tenantPlan":"pro"}},
+		{"receivedAt":"2025-12-21T14:21:01.000Z","method":"GET","path":"/","userAgent":"test","durationMs":0}
+	]`
+	_, err := service.IngestBatch(context.Background(), "customer1", "key1", "json", "", "", strings.NewReader(body))
+	require.NoError(t, err)
+
+	require.NotNil(t, storedBatch)
+	require.Len(t, storedBatch.Entries, 2)
+	entry := storedBatch.Entries[0]
+	assert.Equal(t, 404, entry.Status)
+	require.NotNil(t, entry.DurationMs)
+	assert.Equal(t, 12.5, *entry.DurationMs)
+	assert.Equal(t, int64(512), entry.ResponseBytes)
+	assert.Equal(t, "10.0.0.1", entry.ClientIP)
+	assert.Equal(t, "api.example.com:8443", entry.Host)
+	assert.Equal(t, "user-1", entry.UserID)
+	assert.Equal(t, map[string]string{"region": "eu-west-1", "tenantPlan": "pro"}, entry.Attributes)
+
+	// Missing fields stay zero, a reported zero duration is kept
+	entry = storedBatch.Entries[1]
+	assert.Zero(t, entry.Status)
+	require.NotNil(t, entry.DurationMs)
+	assert.Zero(t, *entry.DurationMs)
+	assert.Empty(t, entry.ClientIP)
+	assert.Nil(t, entry.Attributes)
+}
+
+func TestIngestBatch_ErrOutboxMarkPendingFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+
+	// The batch is never stored without a pending marker
+	outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(assert.AnError)
+
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+	ctx := context.Background()
+	validJSON := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
+	body := bytes.NewReader([]byte(validJSON))
+
+	result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
+
+	require.Error(t, err, "expected error")
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok, "expected ServiceError")
+	assert.Equal(t, "ING_9002", svcErr.Code)
+	assert.Equal(t, "internal", svcErr.Category)
+	assert.Nil(t, result, "expected nil result on error")
+}
+
+func TestIngestBatch_RetryOfUnpublishedBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		ingestedAt   time.Time
+		expectResume bool
+		expectedCode string
+	}{
+		{
+			name:         "stored recently, still in progress",
+			ingestedAt:   time.Now().UTC().Add(-10 * time.Second),
+			expectedCode: "ING_1002",
+		},
+		{
+			name:         "pending timeout elapsed, resumes publishing",
+			ingestedAt:   time.Now().UTC().Add(-2 * time.Minute),
+			expectResume: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+
+			storedBatch := &models.LogBatch{BatchID: "key1", CustomerID: "customer1", IngestedAt: tt.ingestedAt}
+			outboxStore.EXPECT().MarkPending(gomock.Any(), "customer1", "key1").Return(nil)
+			batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(stores.ErrLogBatchAlreadyExist)
+			outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", "key1").Return(false, nil)
+			batchStore.EXPECT().Get(gomock.Any(), "customer1", "key1").Return(storedBatch, nil)
+			if tt.expectResume {
+				batchSummarizer.EXPECT().Summarize(storedBatch).Return([]*models.BatchSummary{{BatchID: "key1", CustomerID: "customer1"}})
+				gomock.InOrder(
+					partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil),
+					outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "key1").Return(nil),
+				)
+			}
+
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+			ctx := context.Background()
+			validJSON := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}]`
+			body := bytes.NewReader([]byte(validJSON))
+
+			result, err := service.IngestBatch(ctx, "customer1", "key1", "json", "", "", body)
+
+			if tt.expectResume {
+				require.NoError(t, err)
+				assert.NotNil(t, result)
+				return
+			}
+			require.Error(t, err, "expected error")
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, "resource_conflict", svcErr.Category)
+			assert.Nil(t, result, "expected nil result on error")
+		})
+	}
+}
+
+func TestIngestBatch_Success_NDJSONAndEncodings(t *testing.T) {
+	t.Parallel()
+
+	ndjson := []byte(`{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}
+
+{"receivedAt":"2025-12-21T14:21:01.000Z","method":"post","path":"/logs","userAgent":"test"}
+`)
+
+	tests := []struct {
+		name            string
+		format          string
+		contentEncoding string
+		body            []byte
+	}{
+		{
+			name:   "ndjson",
+			format: "application/x-ndjson",
+			body:   ndjson,
+		},
+		{
+			name:            "gzip ndjson",
+			format:          "application/x-ndjson",
+			contentEncoding: "gzip",
+			body:            gzipBytes(t, ndjson),
+		},
+		{
+			name:            "zstd ndjson",
+			format:          "application/x-ndjson",
+			contentEncoding: "zstd",
+			body:            zstdBytes(t, ndjson),
+		},
+		{
+			name:            "gzip json array",
+			format:          "application/json",
+			contentEncoding: "GZIP",
+			body: gzipBytes(t, []byte(`[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"},`+
+				`{"receivedAt":"2025-12-21T14:21:01.000Z","method":"POST","path":"/logs","userAgent":"test"}]`)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+
+			var storedBatch *models.LogBatch
+			batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, batch *models.LogBatch) {
+					storedBatch = batch
+				}).
+				Return(nil)
+			outboxStore.EXPECT().MarkPending(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			batchSummarizer.EXPECT().Summarize(gomock.Any()).Return([]*models.BatchSummary{{}})
+			partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
+			outboxStore.EXPECT().MarkPublished(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+			_, err := service.IngestBatch(context.Background(), "customer1", "key1", tt.format, tt.contentEncoding, "", bytes.NewReader(tt.body))
+
+			require.NoError(t, err)
+			require.Len(t, storedBatch.Entries, 2)
+			assert.Equal(t, "/", storedBatch.Entries[0].Path)
+			assert.Equal(t, "POST", storedBatch.Entries[1].Method)
+			assert.Equal(t, "/logs", storedBatch.Entries[1].Path)
+		})
+	}
+}
+
+func TestIngestBatch_ErrValidationFailed_NDJSONLineErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+	valid := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}`
+	lines := []string{
+		valid,
+		`{invalid json}`,
+		valid,
+		`{"method":"GET","path":"/","userAgent":"test"}`,
+	}
+	// 12 more lines missing a path, only the first ones are reported
+	for i := 0; i < 12; i++ {
+		lines = append(lines, `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","userAgent":"test"}`)
+	}
+	body := strings.NewReader(strings.Join(lines, "\n"))
+
+	result, err := service.IngestBatch(context.Background(), "customer1", "key1", "application/x-ndjson", "", "", body)
+
+	require.Error(t, err, "expected error")
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok, "expected ServiceError")
+	assert.Equal(t, "ING_1000", svcErr.Code)
+	assert.Contains(t, svcErr.Message, "line 2: invalid json")
+	assert.Contains(t, svcErr.Message, "line 4: missing receivedAt")
+	assert.Contains(t, svcErr.Message, "line 5: missing path")
+	assert.NotContains(t, svcErr.Message, "line 13:")
+	assert.Contains(t, svcErr.Message, "and 4 more invalid lines")
+	assert.Nil(t, result, "expected nil result on error")
+}
+
+func TestIngestBatch_ErrValidationFailed_Encoding(t *testing.T) {
+	t.Parallel()
+
+	// 3MB of zeros compresses to a few KB but exceeds the 2MB limit once decoded
+	bomb := make([]byte, 3*1024*1024)
+
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "gzip decompression bomb",
+			contentEncoding: "gzip",
+			body:            gzipBytes(t, bomb),
+			expectedCode:    "ING_1003",
+			expectedMessage: "batch too large: must be <= 2MB",
+		},
+		{
+			name:            "zstd decompression bomb",
+			contentEncoding: "zstd",
+			body:            zstdBytes(t, bomb),
+			expectedCode:    "ING_1003",
+			expectedMessage: "batch too large: must be <= 2MB",
+		},
+		{
+			name:            "invalid gzip body",
+			contentEncoding: "gzip",
+			body:            []byte(`[{"not":"gzip"}]`),
+			expectedCode:    "ING_1000",
+			expectedMessage: "invalid gzip request body",
+		},
+		{
+			name:            "unsupported encoding",
+			contentEncoding: "br",
+			body:            []byte(`[]`),
+			expectedCode:    "ING_1000",
+			expectedMessage: `unsupported content encoding: "br"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+			_, err := service.IngestBatch(context.Background(), "customer1", "key1", "application/json", tt.contentEncoding, "", bytes.NewReader(tt.body))
+
+			require.Error(t, err, "expected error")
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, tt.expectedMessage, svcErr.Message)
+		})
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil)
+}
+
+func TestIngestBatch_ErrValidationFailed_StreamingDecoder(t *testing.T) {
+	t.Parallel()
+
+	validEntry := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}`
+
+	tests := []struct {
+		name            string
+		body            string
+		maxBatchBytes   int
+		expectedMessage string
+	}{
+		{
+			name:            "not an array",
+			body:            validEntry,
+			expectedMessage: "invalid json: expected an array of log entries",
+		},
+		{
+			name:            "field with wrong type",
+			body:            `[` + validEntry + `,{"receivedAt":123,"method":"GET","path":"/","userAgent":"test"}]`,
+			expectedMessage: "item at index 1: receivedAt must be a string",
+		},
+		{
+			name:            "item is not an object",
+			body:            `[` + validEntry + `,42]`,
+			expectedMessage: "item at index 1: must be a JSON object",
+		},
+		{
+			name:            "truncated array",
+			body:            `[` + validEntry + `,`,
+			expectedMessage: "invalid json",
+		},
+		{
+			name:            "trailing data",
+			body:            `[` + validEntry + `] []`,
+			expectedMessage: "invalid json: unexpected data after array",
+		},
+		{
+			name:            "configured limit",
+			body:            `[` + validEntry + `,` + validEntry + `]`,
+			maxBatchBytes:   100,
+			expectedMessage: "batch too large: must be <= 100 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limits := ingestors.DefaultIngestionLimits()
+			if tt.maxBatchBytes > 0 {
+				limits.MaxBatchBytes = tt.maxBatchBytes
+			}
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, limits, nil)
+
+			_, err := service.IngestBatch(context.Background(), "customer1", "key1", "application/json", "", "", strings.NewReader(tt.body))
+
+			require.Error(t, err, "expected error")
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			if tt.maxBatchBytes > 0 {
+				assert.Equal(t, "ING_1003", svcErr.Code)
+			} else {
+				assert.Equal(t, "ING_1000", svcErr.Code)
+			}
+			assert.Equal(t, tt.expectedMessage, svcErr.Message)
+		})
+	}
+}
+
+func TestIngestBatch_ErrLimitExceeded(t *testing.T) {
+	t.Parallel()
+
+	entry := func(receivedAt string, path string) string {
+		return `{"receivedAt":"` + receivedAt + `","method":"GET","path":"` + path + `","userAgent":"test"}`
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	old := time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name            string
+		customerID      string
+		format          string
+		body            string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "too many entries",
+			customerID:      "customer1",
+			format:          "application/json",
+			body:            `[` + entry(now, "/") + `,` + entry(now, "/") + `,` + entry(now, "/") + `]`,
+			expectedCode:    "ING_1004",
+			expectedMessage: "too many log entries: max 2 per batch",
+		},
+		{
+			name:            "too many ndjson entries",
+			customerID:      "customer1",
+			format:          "application/x-ndjson",
+			body:            entry(now, "/") + "\n" + entry(now, "/") + "\n" + entry(now, "/"),
+			expectedCode:    "ING_1004",
+			expectedMessage: "too many log entries: max 2 per batch",
+		},
+		{
+			name:            "receivedAt outside of allowed skew",
+			customerID:      "customer1",
+			format:          "application/json",
+			body:            `[` + entry(old, "/") + `]`,
+			expectedCode:    "ING_1006",
+			expectedMessage: "item at index 0: receivedAt must be within 1h0m0s of server time",
+		},
+		{
+			name:            "ndjson lines sharing one code keep it",
+			customerID:      "customer1",
+			format:          "application/x-ndjson",
+			body:            entry(now, "/12345678901") + "\n" + entry(now, "/12345678901"),
+			expectedCode:    "ING_1005",
+			expectedMessage: "invalid ndjson: line 1: path too long: max 10 characters; line 2: path too long: max 10 characters",
+		},
+		{
+			name:            "customer override",
+			customerID:      "customer-strict",
+			format:          "application/json",
+			body:            `[` + entry(now, "/") + `,` + entry(now, "/") + `]`,
+			expectedCode:    "ING_1004",
+			expectedMessage: "too many log entries: max 1 per batch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limits := ingestors.DefaultIngestionLimits()
+			limits.MaxEntries = 2
+			limits.MaxPathLength = 10
+			limits.MaxReceivedAtSkew = time.Hour
+			strictLimits := limits
+			strictLimits.MaxEntries = 1
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, limits,
+				map[string]ingestors.IngestionLimits{"customer-strict": strictLimits})
+
+			_, err := service.IngestBatch(context.Background(), tt.customerID, "key1", tt.format, "", "", strings.NewReader(tt.body))
+
+			require.Error(t, err, "expected error")
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, tt.expectedMessage, svcErr.Message)
+		})
+	}
+}
+
+func TestIngestBatch_PartialMode_DropsInvalidEntries(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}`
+	missingPath := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","userAgent":"test"}`
+	longPath := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/12345678901","userAgent":"test"}`
+
+	tests := []struct {
+		name             string
+		format           string
+		mode             string
+		customerID       string
+		body             string
+		expectedRejected []*models.RejectedEntry
+	}{
+		{
+			name:       "json requested per request",
+			format:     "application/json",
+			mode:       "partial",
+			customerID: "customer1",
+			body:       `[` + valid + `,` + missingPath + `,` + valid + `,` + longPath + `]`,
+			expectedRejected: []*models.RejectedEntry{
+				{Index: 1, Reason: "invalid_entry", Code: "ING_1000", Message: "item at index 1: missing path", Raw: missingPath},
+				{Index: 3, Reason: "field_too_long", Code: "ING_1005", Message: "item at index 3: path too long: max 10 characters", Raw: longPath},
+			},
+		},
+		{
+			name:       "ndjson configured per customer",
+			format:     "application/x-ndjson",
+			customerID: "customer-partial",
+			body:       valid + "\n\n{invalid json}\n" + valid,
+			expectedRejected: []*models.RejectedEntry{
+				{Index: 1, Reason: "invalid_json", Code: "ING_1000", Message: "line 3: invalid json", Raw: "{invalid json}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limits := ingestors.DefaultIngestionLimits()
+			limits.MaxPathLength = 10
+			partialLimits := limits
+			partialLimits.PartialAccept = true
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, limits,
+				map[string]ingestors.IngestionLimits{"customer-partial": partialLimits})
+
+			var storedBatch *models.LogBatch
+			gomock.InOrder(
+				rejectedEntryStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, rejectedBatch *models.RejectedBatch) {
+						assert.Equal(t, "key1", rejectedBatch.BatchID)
+						assert.Equal(t, tt.customerID, rejectedBatch.CustomerID)
+						assert.Equal(t, tt.expectedRejected, rejectedBatch.Entries)
+					}).
+					Return(nil),
+				batchStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, batch *models.LogBatch) {
+						storedBatch = batch
+					}).
+					Return(nil),
+			)
+			outboxStore.EXPECT().MarkPending(gomock.Any(), tt.customerID, "key1").Return(nil)
+			batchSummarizer.EXPECT().Summarize(gomock.Any()).Return([]*models.BatchSummary{{BatchID: "key1", CustomerID: tt.customerID}})
+			partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
+			outboxStore.EXPECT().MarkPublished(gomock.Any(), tt.customerID, "key1").Return(nil)
+
+			result, err := service.IngestBatch(context.Background(), tt.customerID, "key1", tt.format, "", tt.mode, strings.NewReader(tt.body))
+
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.StoredCount)
+			assert.Len(t, storedBatch.Entries, 2)
+			assert.Equal(t, tt.expectedRejected, result.Rejected)
+		})
+	}
+}
+
+func TestIngestBatch_PartialMode_Errors(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"}`
+	missingPath := `{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","userAgent":"test"}`
+
+	tests := []struct {
+		name            string
+		customerID      string
+		mode            string
+		body            string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "every entry rejected",
+			customerID:      "customer1",
+			mode:            "partial",
+			body:            `[` + missingPath + `,` + missingPath + `]`,
+			expectedCode:    "ING_1000",
+			expectedMessage: "invalid json: item at index 0: missing path; item at index 1: missing path",
+		},
+		{
+			name:            "malformed json still rejects the batch",
+			customerID:      "customer1",
+			mode:            "partial",
+			body:            `[` + valid + `,{invalid}]`,
+			expectedCode:    "ING_1000",
+			expectedMessage: "invalid json",
+		},
+		{
+			name:            "strict request overrides the customer mode",
+			customerID:      "customer-partial",
+			mode:            "strict",
+			body:            `[` + valid + `,` + missingPath + `]`,
+			expectedCode:    "ING_1000",
+			expectedMessage: "item at index 1: missing path",
+		},
+		{
+			name:            "unsupported mode",
+			customerID:      "customer1",
+			mode:            "lenient",
+			body:            `[` + valid + `]`,
+			expectedCode:    "ING_1000",
+			expectedMessage: `unsupported ingestion mode: "lenient"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limits := ingestors.DefaultIngestionLimits()
+			partialLimits := limits
+			partialLimits.PartialAccept = true
+
+			batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+			batchStore := storemocks.NewMockLogBatchStore(ctrl)
+			partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+			outboxStore := storemocks.NewMockOutboxStore(ctrl)
+			rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+			service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, limits,
+				map[string]ingestors.IngestionLimits{"customer-partial": partialLimits})
+
+			_, err := service.IngestBatch(context.Background(), tt.customerID, "key1", "application/json", "", tt.mode, strings.NewReader(tt.body))
+
+			require.Error(t, err, "expected error")
+			svcErr, ok := svcerrors.AsServiceError(err)
+			require.True(t, ok, "expected ServiceError")
+			assert.Equal(t, tt.expectedCode, svcErr.Code)
+			assert.Equal(t, tt.expectedMessage, svcErr.Message)
+		})
+	}
+}
+
+func TestIngestBatch_PartialMode_ErrRejectedEntryStoreFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+	rejectedEntryStore := storemocks.NewMockRejectedEntryStore(ctrl)
+	service := ingestors.NewIngestionService(batchSummarizer, batchStore, outboxStore, rejectedEntryStore, partialInsightProducer, time.Minute, ingestors.DefaultIngestionLimits(), nil)
+
+	// Nothing is stored when the rejected entries can't be quarantined
+	rejectedEntryStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(assert.AnError)
+
+	body := `[{"receivedAt":"2025-12-21T14:21:00.000Z","method":"GET","path":"/","userAgent":"test"},{"method":"GET"}]`
+	result, err := service.IngestBatch(context.Background(), "customer1", "key1", "application/json", "", "partial", strings.NewReader(body))
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	svcErr, ok := svcerrors.AsServiceError(err)
+	require.True(t, ok)
+	assert.Equal(t, "ING_9004", svcErr.Code)
+}
+
+// manyAttributes returns the members of a JSON object holding n attributes.
+func manyAttributes(n int) string {
+	members := make([]string, n)
+	for i := range members {
+		members[i] = fmt.Sprintf(`"key%d":"value"`, i)
+	}
+	return strings.Join(members, ",")
 }
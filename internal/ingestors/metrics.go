@@ -4,6 +4,11 @@ import (
 	"log-analytics/internal/shared/metrics"
 )
 
+const (
+	resumeSourceIngestion = "ingestion"
+	resumeSourceRelay     = "relay"
+)
+
 var (
 	metricBatchIngestedTotal = metrics.NewCounterVec(
 		metrics.CounterOpts{
@@ -13,4 +18,38 @@ var (
 		},
 		[]string{metrics.FieldErrorCode},
 	)
+
+	// metricBatchPublishResumedTotal counts stored batches whose partial insights were published after the
+	// original request failed to do so. The source label is "ingestion" when a client retry resumed
+	// publishing and "relay" when the background outbox relay picked the batch up.
+	metricBatchPublishResumedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubIngestion,
+			Name:      "batch_publish_resumed_total",
+		},
+		[]string{"source"},
+	)
+
+	// metricEntryRejectedTotal counts log entries dropped from partially accepted batches, by reason.
+	metricEntryRejectedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubIngestion,
+			Name:      "entry_rejected_total",
+		},
+		[]string{"reason"},
+	)
+
+	// metricSummaryKeysCappedTotal counts the batch summary windows that exceeded their cardinality limit
+	// and had their least requested keys folded into the "__other__" bucket, by dimension ("path",
+	// "user_agent").
+	metricSummaryKeysCappedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubIngestion,
+			Name:      "summary_keys_capped_total",
+		},
+		[]string{"dimension"},
+	)
 )
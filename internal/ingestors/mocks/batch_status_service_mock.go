@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: batch_status_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=batch_status_service.go -destination=./mocks/batch_status_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	ingestors "log-analytics/internal/ingestors"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBatchStatusService is a mock of BatchStatusService interface.
+type MockBatchStatusService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchStatusServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchStatusServiceMockRecorder is the mock recorder for MockBatchStatusService.
+type MockBatchStatusServiceMockRecorder struct {
+	mock *MockBatchStatusService
+}
+
+// NewMockBatchStatusService creates a new mock instance.
+func NewMockBatchStatusService(ctrl *gomock.Controller) *MockBatchStatusService {
+	mock := &MockBatchStatusService{ctrl: ctrl}
+	mock.recorder = &MockBatchStatusServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchStatusService) EXPECT() *MockBatchStatusServiceMockRecorder {
+	return m.recorder
+}
+
+// GetBatchStatus mocks base method.
+func (m *MockBatchStatusService) GetBatchStatus(ctx context.Context, query ingestors.BatchStatusQuery) (*ingestors.BatchStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatchStatus", ctx, query)
+	ret0, _ := ret[0].(*ingestors.BatchStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatchStatus indicates an expected call of GetBatchStatus.
+func (mr *MockBatchStatusServiceMockRecorder) GetBatchStatus(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatchStatus", reflect.TypeOf((*MockBatchStatusService)(nil).GetBatchStatus), ctx, query)
+}
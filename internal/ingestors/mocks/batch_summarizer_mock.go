@@ -41,10 +41,10 @@ func (m *MockBatchSummarizer) EXPECT() *MockBatchSummarizerMockRecorder {
 }
 
 // Summarize mocks base method.
-func (m *MockBatchSummarizer) Summarize(batch *models.LogBatch) *models.BatchSummary {
+func (m *MockBatchSummarizer) Summarize(batch *models.LogBatch) []*models.BatchSummary {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Summarize", batch)
-	ret0, _ := ret[0].(*models.BatchSummary)
+	ret0, _ := ret[0].([]*models.BatchSummary)
 	return ret0
 }
 
@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dimension_extractor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=dimension_extractor.go -destination=./mocks/dimension_extractor_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	ingestors "log-analytics/internal/ingestors"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDimensionExtractor is a mock of DimensionExtractor interface.
+type MockDimensionExtractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockDimensionExtractorMockRecorder
+	isgomock struct{}
+}
+
+// MockDimensionExtractorMockRecorder is the mock recorder for MockDimensionExtractor.
+type MockDimensionExtractorMockRecorder struct {
+	mock *MockDimensionExtractor
+}
+
+// NewMockDimensionExtractor creates a new mock instance.
+func NewMockDimensionExtractor(ctrl *gomock.Controller) *MockDimensionExtractor {
+	mock := &MockDimensionExtractor{ctrl: ctrl}
+	mock.recorder = &MockDimensionExtractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDimensionExtractor) EXPECT() *MockDimensionExtractorMockRecorder {
+	return m.recorder
+}
+
+// Extract mocks base method.
+func (m *MockDimensionExtractor) Extract(entry *ingestors.DimensionEntry) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extract", entry)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Extract indicates an expected call of Extract.
+func (mr *MockDimensionExtractorMockRecorder) Extract(entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extract", reflect.TypeOf((*MockDimensionExtractor)(nil).Extract), entry)
+}
+
+// Name mocks base method.
+func (m *MockDimensionExtractor) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockDimensionExtractorMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockDimensionExtractor)(nil).Name))
+}
@@ -43,16 +43,16 @@ func (m *MockIngestionService) EXPECT() *MockIngestionServiceMockRecorder {
 }
 
 // IngestBatch mocks base method.
-func (m *MockIngestionService) IngestBatch(ctx context.Context, customerID, idempotencyKey, format string, r io.Reader) (*ingestors.IngestResult, error) {
+func (m *MockIngestionService) IngestBatch(ctx context.Context, customerID, idempotencyKey, format, contentEncoding, mode string, r io.Reader) (*ingestors.IngestResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IngestBatch", ctx, customerID, idempotencyKey, format, r)
+	ret := m.ctrl.Call(m, "IngestBatch", ctx, customerID, idempotencyKey, format, contentEncoding, mode, r)
 	ret0, _ := ret[0].(*ingestors.IngestResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IngestBatch indicates an expected call of IngestBatch.
-func (mr *MockIngestionServiceMockRecorder) IngestBatch(ctx, customerID, idempotencyKey, format, r any) *gomock.Call {
+func (mr *MockIngestionServiceMockRecorder) IngestBatch(ctx, customerID, idempotencyKey, format, contentEncoding, mode, r any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestBatch", reflect.TypeOf((*MockIngestionService)(nil).IngestBatch), ctx, customerID, idempotencyKey, format, r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestBatch", reflect.TypeOf((*MockIngestionService)(nil).IngestBatch), ctx, customerID, idempotencyKey, format, contentEncoding, mode, r)
 }
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox_relay.go
+//
+// Generated by this command:
+//
+//	mockgen -source=outbox_relay.go -destination=./mocks/outbox_relay_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxRelay is a mock of OutboxRelay interface.
+type MockOutboxRelay struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRelayMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRelayMockRecorder is the mock recorder for MockOutboxRelay.
+type MockOutboxRelayMockRecorder struct {
+	mock *MockOutboxRelay
+}
+
+// NewMockOutboxRelay creates a new mock instance.
+func NewMockOutboxRelay(ctrl *gomock.Controller) *MockOutboxRelay {
+	mock := &MockOutboxRelay{ctrl: ctrl}
+	mock.recorder = &MockOutboxRelayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRelay) EXPECT() *MockOutboxRelayMockRecorder {
+	return m.recorder
+}
+
+// RelayPending mocks base method.
+func (m *MockOutboxRelay) RelayPending(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayPending", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RelayPending indicates an expected call of RelayPending.
+func (mr *MockOutboxRelayMockRecorder) RelayPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayPending", reflect.TypeOf((*MockOutboxRelay)(nil).RelayPending), ctx)
+}
+
+// Start mocks base method.
+func (m *MockOutboxRelay) Start(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start", ctx)
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockOutboxRelayMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockOutboxRelay)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockOutboxRelay) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockOutboxRelayMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockOutboxRelay)(nil).Stop))
+}
@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: path_normalizer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=path_normalizer.go -destination=./mocks/path_normalizer_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPathNormalizer is a mock of PathNormalizer interface.
+type MockPathNormalizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockPathNormalizerMockRecorder
+	isgomock struct{}
+}
+
+// MockPathNormalizerMockRecorder is the mock recorder for MockPathNormalizer.
+type MockPathNormalizerMockRecorder struct {
+	mock *MockPathNormalizer
+}
+
+// NewMockPathNormalizer creates a new mock instance.
+func NewMockPathNormalizer(ctrl *gomock.Controller) *MockPathNormalizer {
+	mock := &MockPathNormalizer{ctrl: ctrl}
+	mock.recorder = &MockPathNormalizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPathNormalizer) EXPECT() *MockPathNormalizerMockRecorder {
+	return m.recorder
+}
+
+// Normalize mocks base method.
+func (m *MockPathNormalizer) Normalize(customerID, path string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Normalize", customerID, path)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Normalize indicates an expected call of Normalize.
+func (mr *MockPathNormalizerMockRecorder) Normalize(customerID, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Normalize", reflect.TypeOf((*MockPathNormalizer)(nil).Normalize), customerID, path)
+}
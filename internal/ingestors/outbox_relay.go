@@ -0,0 +1,146 @@
+package ingestors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/shared/ulid"
+	"log-analytics/internal/stores"
+	"log-analytics/internal/streams"
+)
+
+// OutboxRelay periodically republishes stored log batches whose partial insights were never acknowledged,
+// e.g. because the producer failed or the process crashed between storing the raw batch and publishing.
+// A pending batch is only picked up once it is older than the pending timeout, so batches still being
+// published by an in-flight request are left alone.
+//
+//go:generate mockgen -source=outbox_relay.go -destination=./mocks/outbox_relay_mock.go -package=mocks
+type OutboxRelay interface {
+	Start(ctx context.Context)
+	Stop()
+	// RelayPending runs a single relay pass over all pending outbox records.
+	RelayPending(ctx context.Context) error
+}
+
+type outboxRelay struct {
+	batchStore     stores.LogBatchStore
+	outboxStore    stores.OutboxStore
+	batchPublisher *batchPublisher
+	interval       time.Duration
+	pendingTimeout time.Duration
+
+	wg sync.WaitGroup
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+
+	logger loggers.Logger
+}
+
+func NewOutboxRelay(batchSummarizer BatchSummarizer, batchStore stores.LogBatchStore, outboxStore stores.OutboxStore, partialInsightProducer streams.PartialInsightProducer, interval time.Duration, pendingTimeout time.Duration, logger loggers.Logger) OutboxRelay {
+	return &outboxRelay{
+		batchStore:  batchStore,
+		outboxStore: outboxStore,
+		batchPublisher: &batchPublisher{
+			batchSummarizer:        batchSummarizer,
+			outboxStore:            outboxStore,
+			partialInsightProducer: partialInsightProducer,
+		},
+		interval:       interval,
+		pendingTimeout: pendingTimeout,
+		stopCh:         make(chan struct{}),
+		logger:         logger,
+	}
+}
+
+// Start spawns the relay goroutine, which runs a relay pass on every tick of the interval.
+func (relay *outboxRelay) Start(ctx context.Context) {
+	relay.wg.Add(1)
+	go func() {
+		defer relay.wg.Done()
+
+		ticker := time.NewTicker(relay.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-relay.stopCh:
+				return
+			case <-ticker.C:
+				requestLogger := relay.logger.With().
+					Str(loggers.FieldRequestID, ulid.NewULID()).
+					Logger()
+				if err := relay.RelayPending(requestLogger.WithContext(ctx)); err != nil {
+					requestLogger.Error().Err(err).Msg("outbox relay pass failed")
+				}
+			}
+		}
+	}()
+}
+
+// Stop waits for the relay goroutine to stop (best called during app shutdown).
+func (relay *outboxRelay) Stop() {
+	relay.stopOnce.Do(func() { close(relay.stopCh) })
+	relay.wg.Wait()
+}
+
+func (relay *outboxRelay) RelayPending(ctx context.Context) error {
+	records, err := relay.outboxStore.ListPending(ctx)
+	if err != nil {
+		return errInternalOutboxStoreFailed(err)
+	}
+
+	var errs []error
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Since(record.MarkedAt) < relay.pendingTimeout {
+			continue
+		}
+		if err := relay.relayRecord(ctx, record); err != nil {
+			loggers.Ctx(ctx).Error().Err(err).
+				Msgf("failed to relay batch %s for customer %s", record.BatchID, record.CustomerID)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (relay *outboxRelay) relayRecord(ctx context.Context, record *stores.OutboxRecord) error {
+	// A crash between writing the acknowledgement and deleting the pending marker leaves both behind
+	published, err := relay.outboxStore.IsPublished(ctx, record.CustomerID, record.BatchID)
+	if err != nil {
+		return errInternalOutboxStoreFailed(err)
+	}
+	if published {
+		if err := relay.outboxStore.MarkPublished(ctx, record.CustomerID, record.BatchID); err != nil {
+			return errInternalOutboxStoreFailed(err)
+		}
+		return nil
+	}
+
+	logBatch, err := relay.batchStore.Get(ctx, record.CustomerID, record.BatchID)
+	if err != nil {
+		if errors.Is(err, stores.ErrLogBatchNotFound) {
+			// The batch was marked pending but storing it failed, there is nothing to publish
+			if err := relay.outboxStore.ClearPending(ctx, record.CustomerID, record.BatchID); err != nil {
+				return errInternalOutboxStoreFailed(err)
+			}
+			loggers.Ctx(ctx).Info().Msgf("dropped pending marker of unstored batch %s for customer %s", record.BatchID, record.CustomerID)
+			return nil
+		}
+		return errInternalLogBatchStoreFailed(err)
+	}
+	if _, err := relay.batchPublisher.publish(ctx, logBatch); err != nil {
+		return err
+	}
+
+	loggers.Ctx(ctx).Info().Msgf("relayed batch %s for customer %s", record.BatchID, record.CustomerID)
+	metricBatchPublishResumedTotal.WithLabelValues(resumeSourceRelay).Inc()
+	return nil
+}
@@ -0,0 +1,99 @@
+package ingestors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"log-analytics/internal/ingestors"
+	ingestormocks "log-analytics/internal/ingestors/mocks"
+	"log-analytics/internal/models"
+	"log-analytics/internal/stores"
+	storemocks "log-analytics/internal/stores/mocks"
+	streammocks "log-analytics/internal/streams/mocks"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRelayPending_RepublishesStalePendingBatches(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+
+	now := time.Now().UTC()
+	outboxStore.EXPECT().ListPending(gomock.Any()).Return([]*stores.OutboxRecord{
+		// still within the pending timeout, left to the in-flight request
+		{CustomerID: "customer1", BatchID: "batch-recent", MarkedAt: now.Add(-5 * time.Second)},
+		// acknowledged but the pending marker was never removed
+		{CustomerID: "customer1", BatchID: "batch-acked", MarkedAt: now.Add(-time.Hour)},
+		// never published
+		{CustomerID: "customer1", BatchID: "batch-lost", MarkedAt: now.Add(-time.Hour)},
+		// marked pending but never stored
+		{CustomerID: "customer1", BatchID: "batch-unstored", MarkedAt: now.Add(-time.Hour)},
+	}, nil)
+
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", "batch-acked").Return(true, nil)
+	outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "batch-acked").Return(nil)
+
+	lostBatch := &models.LogBatch{BatchID: "batch-lost", CustomerID: "customer1"}
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", "batch-lost").Return(false, nil)
+	batchStore.EXPECT().Get(gomock.Any(), "customer1", "batch-lost").Return(lostBatch, nil)
+	batchSummarizer.EXPECT().Summarize(lostBatch).Return([]*models.BatchSummary{{BatchID: "batch-lost", CustomerID: "customer1"}})
+	gomock.InOrder(
+		partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil),
+		outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "batch-lost").Return(nil),
+	)
+
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", "batch-unstored").Return(false, nil)
+	batchStore.EXPECT().Get(gomock.Any(), "customer1", "batch-unstored").Return(nil, stores.ErrLogBatchNotFound)
+	outboxStore.EXPECT().ClearPending(gomock.Any(), "customer1", "batch-unstored").Return(nil)
+
+	relay := ingestors.NewOutboxRelay(batchSummarizer, batchStore, outboxStore, partialInsightProducer, time.Second, time.Minute, zerolog.Nop())
+
+	err := relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRelayPending_ContinuesAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchSummarizer := ingestormocks.NewMockBatchSummarizer(ctrl)
+	batchStore := storemocks.NewMockLogBatchStore(ctrl)
+	partialInsightProducer := streammocks.NewMockPartialInsightProducer(ctrl)
+	outboxStore := storemocks.NewMockOutboxStore(ctrl)
+
+	markedAt := time.Now().UTC().Add(-time.Hour)
+	outboxStore.EXPECT().ListPending(gomock.Any()).Return([]*stores.OutboxRecord{
+		{CustomerID: "customer1", BatchID: "batch-1", MarkedAt: markedAt},
+		{CustomerID: "customer1", BatchID: "batch-2", MarkedAt: markedAt},
+	}, nil)
+	outboxStore.EXPECT().IsPublished(gomock.Any(), "customer1", gomock.Any()).Return(false, nil).Times(2)
+
+	batch1 := &models.LogBatch{BatchID: "batch-1", CustomerID: "customer1"}
+	batch2 := &models.LogBatch{BatchID: "batch-2", CustomerID: "customer1"}
+	batchStore.EXPECT().Get(gomock.Any(), "customer1", "batch-1").Return(batch1, nil)
+	batchStore.EXPECT().Get(gomock.Any(), "customer1", "batch-2").Return(batch2, nil)
+	batchSummarizer.EXPECT().Summarize(gomock.Any()).Return([]*models.BatchSummary{{}}).Times(2)
+	gomock.InOrder(
+		partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(assert.AnError),
+		partialInsightProducer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil),
+	)
+	// Only the second batch is acknowledged; the first stays pending for the next pass
+	outboxStore.EXPECT().MarkPublished(gomock.Any(), "customer1", "batch-2").Return(nil)
+
+	relay := ingestors.NewOutboxRelay(batchSummarizer, batchStore, outboxStore, partialInsightProducer, time.Second, time.Minute, zerolog.Nop())
+
+	err := relay.RelayPending(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package ingestors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idPlaceholder replaces the path segments recognized as identifiers by the built-in rules.
+const idPlaceholder = ":id"
+
+// Built-in identifier rules, matched against whole lower-cased segments
+var (
+	numericIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	uuidPattern      = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	ulidPattern      = regexp.MustCompile(`^[0-7][0-9a-hjkmnp-tv-z]{25}$`)
+	hexHashPattern   = regexp.MustCompile(`^[0-9a-f]{16,}$`)
+)
+
+// PathNormalizer collapses request paths into route-like keys, so every request of a route is counted
+// under one key instead of one key per URL (e.g. /users/123 and /users/456 → /users/:id).
+//
+//go:generate mockgen -source=path_normalizer.go -destination=./mocks/path_normalizer_mock.go -package=mocks
+type PathNormalizer interface {
+	// Normalize returns the normalized form of path for the customer.
+	Normalize(customerID string, path string) string
+}
+
+// pathTemplate is a route template split into segments; a "{name}" segment matches any single segment.
+type pathTemplate struct {
+	template string
+	segments []string
+}
+
+type pathNormalizer struct {
+	customerTemplates map[string][]pathTemplate
+}
+
+// NewPathNormalizer creates a PathNormalizer. customerTemplates holds the route templates of every customer
+// that defines some, e.g. "/orders/{orderId}/items"; the first matching template of a customer wins over
+// the built-in rules.
+func NewPathNormalizer(customerTemplates map[string][]string) (PathNormalizer, error) {
+	compiled := make(map[string][]pathTemplate, len(customerTemplates))
+	for customerID, templates := range customerTemplates {
+		for _, template := range templates {
+			if !strings.HasPrefix(template, "/") {
+				return nil, fmt.Errorf("path template of customer %s must start with /: %s", customerID, template)
+			}
+			normalized := cleanPath(template)
+			compiled[customerID] = append(compiled[customerID], pathTemplate{
+				template: normalized,
+				segments: strings.Split(normalized, "/"),
+			})
+		}
+	}
+	return &pathNormalizer{customerTemplates: compiled}, nil
+}
+
+// Normalize strips the query string and fragment, lower-cases the path and drops empty segments and the
+// trailing slash. The result is then replaced by the first matching route template of the customer, or
+// has its identifier segments (numeric IDs, UUIDs, ULIDs, hex hashes) replaced by :id.
+func (n *pathNormalizer) Normalize(customerID string, path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	path = cleanPath(path)
+	segments := strings.Split(path, "/")
+
+	for _, template := range n.customerTemplates[customerID] {
+		if template.matches(segments) {
+			return template.template
+		}
+	}
+
+	replaced := false
+	for i, segment := range segments {
+		if isIdentifier(segment) {
+			segments[i] = idPlaceholder
+			replaced = true
+		}
+	}
+	if !replaced {
+		return path
+	}
+	return strings.Join(segments, "/")
+}
+
+func (t pathTemplate) matches(segments []string) bool {
+	if len(segments) != len(t.segments) {
+		return false
+	}
+	for i, segment := range t.segments {
+		isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if !isParam && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanPath lower-cases path and removes empty segments, so "/Users//42/" becomes "/users/42".
+// Template parameters keep their case.
+func cleanPath(path string) string {
+	var b strings.Builder
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if !strings.HasPrefix(segment, "{") {
+			segment = strings.ToLower(segment)
+		}
+		b.WriteString("/")
+		b.WriteString(segment)
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+func isIdentifier(segment string) bool {
+	return numericIDPattern.MatchString(segment) ||
+		uuidPattern.MatchString(segment) ||
+		ulidPattern.MatchString(segment) ||
+		hexHashPattern.MatchString(segment)
+}
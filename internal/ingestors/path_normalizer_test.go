@@ -0,0 +1,58 @@
+package ingestors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathNormalizer_Normalize(t *testing.T) {
+	t.Parallel()
+
+	pathNormalizer, err := NewPathNormalizer(map[string][]string{
+		"cus-axon": {"/orders/{orderId}/items", "/Shops/{shop}/"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		customerID string
+		path       string
+		expected   string
+	}{
+		{name: "root", customerID: "cus-axon", path: "/", expected: "/"},
+		{name: "empty", customerID: "cus-axon", path: "", expected: "/"},
+		{name: "query string only", customerID: "cus-axon", path: "/?utm=1", expected: "/"},
+		{name: "static path", customerID: "cus-axon", path: "/about", expected: "/about"},
+		{name: "query string and fragment", customerID: "cus-axon", path: "/search?q=go#top", expected: "/search"},
+		{name: "trailing slash and case", customerID: "cus-axon", path: "/About/Team/", expected: "/about/team"},
+		{name: "duplicate slashes", customerID: "cus-axon", path: "//about///team", expected: "/about/team"},
+		{name: "numeric id", customerID: "cus-axon", path: "/users/123", expected: "/users/:id"},
+		{name: "several ids", customerID: "cus-axon", path: "/users/123/posts/456", expected: "/users/:id/posts/:id"},
+		{name: "uuid", customerID: "cus-axon", path: "/carts/3F2504E0-4F89-11D3-9A0C-0305E82C3301", expected: "/carts/:id"},
+		{name: "ulid", customerID: "cus-axon", path: "/batches/01ARZ3NDEKTSV4RRFFQ69G5FAV", expected: "/batches/:id"},
+		{name: "hex hash", customerID: "cus-axon", path: "/assets/9f86d081884c7d659a2feaa0c55ad015/app.js", expected: "/assets/:id/app.js"},
+		{name: "short hex word kept", customerID: "cus-axon", path: "/cafe/faded", expected: "/cafe/faded"},
+		{name: "version segment kept", customerID: "cus-axon", path: "/api/v1/users", expected: "/api/v1/users"},
+		{name: "customer template", customerID: "cus-axon", path: "/orders/ord-xyz/items", expected: "/orders/{orderId}/items"},
+		{name: "customer template normalized", customerID: "cus-axon", path: "/SHOPS/berlin/", expected: "/shops/{shop}"},
+		{name: "template of another customer", customerID: "cus-bolt", path: "/orders/ord-xyz/items", expected: "/orders/ord-xyz/items"},
+		{name: "template length mismatch", customerID: "cus-axon", path: "/orders/42", expected: "/orders/:id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, pathNormalizer.Normalize(tt.customerID, tt.path))
+		})
+	}
+}
+
+func TestNewPathNormalizer_InvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPathNormalizer(map[string][]string{"cus-axon": {"orders/{orderId}"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package ingestors
+
+import (
+	"strconv"
+
+	"log-analytics/internal/models"
+
+	"github.com/mileusna/useragent"
+)
+
+// Device types and bot flags counted per window
+const (
+	deviceTypeDesktop = "desktop"
+	deviceTypeMobile  = "mobile"
+	deviceTypeTablet  = "tablet"
+	deviceTypeBot     = "bot"
+
+	botFlagBot   = "bot"
+	botFlagHuman = "human"
+)
+
+// ParsedUserAgent holds the keys a user agent is counted under in every user agent dimension.
+type ParsedUserAgent struct {
+	Family              string // browser or client name, e.g. "Chrome", "curl"
+	OS                  string
+	DeviceType          string // desktop, mobile, tablet or bot
+	BrowserMajorVersion string // family and major version, e.g. "Chrome 120"
+	BotOrHuman          string // bot or human
+}
+
+// parseUserAgent breaks a user agent down into its dimensions. The parser names any unknown string after
+// its first token, so a user agent counts as recognized only when the parser also found a version for
+// it or flagged it as a bot; every other user agent is counted under models.UnknownKey, except for the
+// bot flag, which is "human" unless the parser says otherwise.
+func parseUserAgent(userAgent string) ParsedUserAgent {
+	parsed := useragent.Parse(userAgent)
+	if parsed.Name == "" || (parsed.Version == "" && !parsed.Bot) {
+		return ParsedUserAgent{
+			Family:              models.UnknownKey,
+			OS:                  models.UnknownKey,
+			DeviceType:          models.UnknownKey,
+			BrowserMajorVersion: models.UnknownKey,
+			BotOrHuman:          botFlagHuman,
+		}
+	}
+
+	dimensions := ParsedUserAgent{
+		Family:              parsed.Name,
+		OS:                  parsed.OS,
+		DeviceType:          models.UnknownKey,
+		BrowserMajorVersion: parsed.Name,
+		BotOrHuman:          botFlagHuman,
+	}
+	if dimensions.OS == "" {
+		dimensions.OS = models.UnknownKey
+	}
+	if parsed.Version != "" {
+		dimensions.BrowserMajorVersion += " " + strconv.Itoa(parsed.VersionNo.Major)
+	}
+	switch {
+	case parsed.Bot:
+		dimensions.DeviceType = deviceTypeBot
+		dimensions.BotOrHuman = botFlagBot
+	case parsed.Tablet:
+		dimensions.DeviceType = deviceTypeTablet
+	case parsed.Mobile:
+		dimensions.DeviceType = deviceTypeMobile
+	case parsed.Desktop:
+		dimensions.DeviceType = deviceTypeDesktop
+	}
+	return dimensions
+}
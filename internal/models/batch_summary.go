@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // BatchSummary represents an aggregated summary of a log batch, reducing thousands of raw log entries
 // into compact time-windowed aggregates. This dramatically reduces the processing effort required
 // downstream by eliminating the need to process individual log entries.
@@ -14,27 +16,32 @@ package models
 //	  "batchId": "01ARZ3NDEKTSV4RRFFQ69G5FAV",
 //	  "customerId": "cus-axon",
 //	  "windowSize": "minute",
+//	  "maxReceivedAt": "2025-12-28T18:04:59Z",
 //	  "byWindowStart": {
 //	    "2025-12-28T18:03:00Z": {
-//	      "requestsByPath": {
-//	        "GET /": 150,
-//	        "GET /about": 50,
-//	        "GET /careers": 30
-//	      },
-//	      "requestsByUserAgent": {
-//	        "Chrome": 120,
-//	        "Firefox": 80,
-//	        "Googlebot": 30
+//	      "dimensions": {
+//	        "path": {
+//	          "GET /": 150,
+//	          "GET /about": 50,
+//	          "GET /careers": 30
+//	        },
+//	        "user_agent": {
+//	          "Chrome": 120,
+//	          "Firefox": 80,
+//	          "Googlebot": 30
+//	        }
 //	      }
 //	    },
 //	    "2025-12-28T18:04:00Z": {
-//	      "requestsByPath": {
-//	        "GET /": 200,
-//	        "GET /contact": 50
-//	      },
-//	      "requestsByUserAgent": {
-//	        "Chrome": 180,
-//	        "Firefox": 70
+//	      "dimensions": {
+//	        "path": {
+//	          "GET /": 200,
+//	          "GET /contact": 50
+//	        },
+//	        "user_agent": {
+//	          "Chrome": 180,
+//	          "Firefox": 70
+//	        }
 //	      }
 //	    }
 //	  }
@@ -43,10 +50,6 @@ type BatchSummary struct {
 	BatchID       string                      `json:"batchId"`
 	CustomerID    string                      `json:"customerId"`
 	WindowSize    WindowSize                  `json:"windowSize"`
+	MaxReceivedAt time.Time                   `json:"maxReceivedAt"` // latest entry of the batch, advances the watermark
 	ByWindowStart map[string]WindowAggregates `json:"byWindowStart"`
 }
-
-type WindowAggregates struct {
-	RequestsByPath      map[string]int64 `json:"requestsByPath"`
-	RequestsByUserAgent map[string]int64 `json:"requestsByUserAgent"`
-}
@@ -0,0 +1,188 @@
+package models
+
+import "sort"
+
+const (
+	// OtherKey collects the counts of the keys folded away by CapCounts.
+	OtherKey = "__other__"
+	// UnknownKey counts the requests whose user agent could not be parsed, so raw strings never become keys.
+	UnknownKey = "__unknown__"
+)
+
+// Names of the built-in dimensions, also the labels of the counted maps in metrics. Custom dimensions are
+// labelled with their own name.
+const (
+	DimensionPath                = "path"
+	DimensionUserAgent           = "user_agent"
+	DimensionOS                  = "os"
+	DimensionDeviceType          = "device_type"
+	DimensionBrowserMajorVersion = "browser_major_version"
+	DimensionBotOrHuman          = "bot_or_human"
+	DimensionStatusClass         = "status_class"
+	DimensionPathUserAgent       = "path_user_agent"
+	DimensionUniqueVisitorPath   = "unique_visitor_path"
+	DimensionAttribute           = "attribute" // every attribute dimension, see DimensionLabel
+)
+
+// CardinalityLimits caps the number of distinct keys kept per window and dimension. 0 means unlimited.
+// MaxDimensionKeys applies to every dimension without a limit of its own, see MaxKeys.
+type CardinalityLimits struct {
+	MaxPaths                int
+	MaxUserAgents           int
+	MaxBrowserMajorVersions int
+	MaxUniqueVisitorPaths   int // paths keeping their own unique visitor sketch, see CapUniqueVisitors
+	MaxBreakdownUserAgents  int // user agents per path of the path by user agent breakdown, see CapBreakdown
+	MaxDimensionKeys        int
+}
+
+// MaxKeys returns the number of keys kept per window in dimension name.
+func (l CardinalityLimits) MaxKeys(name string) int {
+	switch name {
+	case DimensionPath:
+		return l.MaxPaths
+	case DimensionUserAgent:
+		return l.MaxUserAgents
+	case DimensionBrowserMajorVersion:
+		return l.MaxBrowserMajorVersions
+	}
+	return l.MaxDimensionKeys
+}
+
+// CapCounts keeps the limit keys with the highest estimates and folds every other key into OtherKey, so the
+// map holds at most limit+1 keys and its total is unchanged. It returns the number of keys folded, 0 when the
+// map is within the limit or the limit is 0.
+//
+// Together with AddSummary this is a Space-Saving summary: counts holds the requests of every kept key since
+// it was last kept, and errors the requests it may have lost to OtherKey before, so its requests are between
+// its count and its estimate, count plus error. Keys are ranked by estimate, ties broken by key to stay
+// deterministic, and folded keys drop their errors, see missingBound for what they may have had. errors may be
+// nil for maps not tracking errors, whose kept counts are then only lower bounds.
+func CapCounts(counts map[string]int64, errors map[string]int64, limit int) int {
+	keys := len(counts)
+	if _, ok := counts[OtherKey]; ok {
+		keys--
+	}
+	if limit <= 0 || keys <= limit {
+		return 0
+	}
+
+	ranked := rankKeys(counts, estimates(counts, errors))
+	for _, key := range ranked[limit:] {
+		counts[OtherKey] += counts[key]
+		delete(counts, key)
+		delete(errors, key)
+	}
+	return len(ranked) - limit
+}
+
+// AddSummary adds the summary src, capped by CapCounts with the errors srcErrors, to dst and dstErrors and
+// returns both, allocating them when nil. A key kept by only one side may have lost requests to the OtherKey
+// of the other side, up to the bound of that side (see missingBound), which is added to its error.
+func AddSummary(dst map[string]int64, dstErrors map[string]int64, src map[string]int64, srcErrors map[string]int64) (map[string]int64, map[string]int64) {
+	dstBound, srcBound := missingBound(dst, dstErrors), missingBound(src, srcErrors)
+	addError := func(key string, err int64) {
+		if err <= 0 {
+			return
+		}
+		if dstErrors == nil {
+			dstErrors = make(map[string]int64)
+		}
+		dstErrors[key] += err
+	}
+	for key := range dst {
+		if _, ok := src[key]; !ok && key != OtherKey {
+			addError(key, srcBound)
+		}
+	}
+	for key := range src {
+		if key == OtherKey {
+			continue
+		}
+		if _, ok := dst[key]; !ok {
+			addError(key, dstBound)
+		}
+		addError(key, srcErrors[key])
+	}
+	return AddCounts(dst, src), dstErrors
+}
+
+// missingBound returns the most requests a key missing from a summary capped by CapCounts may have: 0 when
+// nothing was folded, else the OtherKey count or the smallest estimate kept, whichever is lower, since a
+// folded key never had a higher estimate than the keys kept instead.
+func missingBound(counts map[string]int64, errors map[string]int64) int64 {
+	bound := counts[OtherKey]
+	if bound == 0 {
+		return 0
+	}
+	for key, count := range counts {
+		if key != OtherKey && count+errors[key] < bound {
+			bound = count + errors[key]
+		}
+	}
+	return bound
+}
+
+// estimates returns the estimate of every key of counts, counts itself when no key has an error.
+func estimates(counts map[string]int64, errors map[string]int64) map[string]int64 {
+	if len(errors) == 0 {
+		return counts
+	}
+	estimated := make(map[string]int64, len(counts))
+	for key, count := range counts {
+		estimated[key] = count + errors[key]
+	}
+	return estimated
+}
+
+// rankKeys returns the keys of values except OtherKey, ordered by their counts in counts, highest first, and
+// then by key.
+func rankKeys[V any](values map[string]V, counts map[string]int64) []string {
+	ranked := make([]string, 0, len(values))
+	for key := range values {
+		if key != OtherKey {
+			ranked = append(ranked, key)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if counts[ranked[i]] != counts[ranked[j]] {
+			return counts[ranked[i]] > counts[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
+
+// AddCounts adds the counts of src to dst and returns dst. A nil dst is allocated, as results stored before
+// a dimension existed have no map for it.
+func AddCounts(dst map[string]int64, src map[string]int64) map[string]int64 {
+	if dst == nil {
+		dst = make(map[string]int64, len(src))
+	}
+	for key, count := range src {
+		dst[key] += count
+	}
+	return dst
+}
+
+// addNestedCounts adds every counted map of src to the map under the same key in dst and returns dst.
+func addNestedCounts(dst map[string]map[string]int64, src map[string]map[string]int64) map[string]map[string]int64 {
+	if dst == nil {
+		dst = make(map[string]map[string]int64, len(src))
+	}
+	for key, counts := range src {
+		dst[key] = AddCounts(dst[key], counts)
+	}
+	return dst
+}
+
+// foldMissingKeys merges the value of every key missing from counts into the value of OtherKey, which is
+// nil until the first fold. It keeps per key sketches in line with counts capped by CapCounts.
+func foldMissingKeys[V any](values map[string]V, counts map[string]int64, merge func(other V, folded V) V) {
+	for key, value := range values {
+		if _, ok := counts[key]; ok || key == OtherKey {
+			continue
+		}
+		values[OtherKey] = merge(values[OtherKey], value)
+		delete(values, key)
+	}
+}
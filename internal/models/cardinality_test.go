@@ -0,0 +1,170 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		counts         map[string]int64
+		errors         map[string]int64
+		limit          int
+		expected       map[string]int64
+		expectedErrors map[string]int64
+		folded         int
+	}{
+		{
+			name:     "within limit",
+			counts:   map[string]int64{"GET /": 3, "GET /about": 1},
+			limit:    2,
+			expected: map[string]int64{"GET /": 3, "GET /about": 1},
+		},
+		{
+			name:     "unlimited",
+			counts:   map[string]int64{"GET /": 3, "GET /about": 1},
+			limit:    0,
+			expected: map[string]int64{"GET /": 3, "GET /about": 1},
+		},
+		{
+			name:     "folds the tail",
+			counts:   map[string]int64{"GET /": 5, "GET /a": 1, "GET /b": 2, "GET /c": 1},
+			limit:    2,
+			expected: map[string]int64{"GET /": 5, "GET /b": 2, OtherKey: 2},
+			folded:   2,
+		},
+		{
+			name:     "adds to an existing other bucket",
+			counts:   map[string]int64{"GET /": 5, "GET /a": 1, OtherKey: 10},
+			limit:    1,
+			expected: map[string]int64{"GET /": 5, OtherKey: 11},
+			folded:   1,
+		},
+		{
+			name:     "other bucket does not count against the limit",
+			counts:   map[string]int64{"GET /": 5, OtherKey: 10},
+			limit:    1,
+			expected: map[string]int64{"GET /": 5, OtherKey: 10},
+		},
+		{
+			name:     "ties broken by key",
+			counts:   map[string]int64{"GET /b": 1, "GET /a": 1, "GET /c": 1},
+			limit:    1,
+			expected: map[string]int64{"GET /a": 1, OtherKey: 2},
+			folded:   2,
+		},
+		{
+			name:           "ranks by count plus error",
+			counts:         map[string]int64{"GET /": 5, "GET /a": 2, "GET /b": 3, OtherKey: 4},
+			errors:         map[string]int64{"GET /a": 4, "GET /b": 1},
+			limit:          2,
+			expected:       map[string]int64{"GET /": 5, "GET /a": 2, OtherKey: 7},
+			expectedErrors: map[string]int64{"GET /a": 4},
+			folded:         1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			folded := CapCounts(tt.counts, tt.errors, tt.limit)
+			assert.Equal(t, tt.folded, folded)
+			assert.Equal(t, tt.expected, tt.counts)
+			if tt.expectedErrors != nil {
+				assert.Equal(t, tt.expectedErrors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestAddSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		dst            map[string]int64
+		dstErrors      map[string]int64
+		src            map[string]int64
+		srcErrors      map[string]int64
+		expected       map[string]int64
+		expectedErrors map[string]int64
+	}{
+		{
+			name:     "nothing folded on either side",
+			dst:      map[string]int64{"GET /": 3},
+			src:      map[string]int64{"GET /": 1, "GET /a": 2},
+			expected: map[string]int64{"GET /": 4, "GET /a": 2},
+		},
+		{
+			name:           "key missing from a side that folded keys",
+			dst:            map[string]int64{"GET /": 5, "GET /a": 3, OtherKey: 4},
+			src:            map[string]int64{"GET /": 1, "GET /b": 2},
+			expected:       map[string]int64{"GET /": 6, "GET /a": 3, "GET /b": 2, OtherKey: 4},
+			expectedErrors: map[string]int64{"GET /b": 3},
+		},
+		{
+			name:           "bound is at most the other count",
+			dst:            map[string]int64{"GET /": 5, OtherKey: 1},
+			src:            map[string]int64{"GET /b": 2, OtherKey: 2},
+			expected:       map[string]int64{"GET /": 5, "GET /b": 2, OtherKey: 3},
+			expectedErrors: map[string]int64{"GET /": 2, "GET /b": 1},
+		},
+		{
+			name:           "errors of both sides add up",
+			dst:            map[string]int64{"GET /": 5, "GET /a": 2, OtherKey: 6},
+			dstErrors:      map[string]int64{"GET /a": 3},
+			src:            map[string]int64{"GET /a": 1, OtherKey: 1},
+			srcErrors:      map[string]int64{"GET /a": 2},
+			expected:       map[string]int64{"GET /": 5, "GET /a": 3, OtherKey: 7},
+			expectedErrors: map[string]int64{"GET /": 1, "GET /a": 5},
+		},
+		{
+			name:     "nil dst",
+			src:      map[string]int64{"GET /": 1, OtherKey: 1},
+			expected: map[string]int64{"GET /": 1, OtherKey: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			counts, errors := AddSummary(tt.dst, tt.dstErrors, tt.src, tt.srcErrors)
+			assert.Equal(t, tt.expected, counts)
+			if tt.expectedErrors == nil {
+				assert.Empty(t, errors)
+			} else {
+				assert.Equal(t, tt.expectedErrors, errors)
+			}
+		})
+	}
+}
+
+func TestAddSummary_KeepsHeavyHitterSpreadAcrossBatches(t *testing.T) {
+	t.Parallel()
+
+	// The first batch fills the summary with two keys, later batches only add one request of GET /hot each,
+	// which a plain top-k truncation folds away every time
+	counts := map[string]int64{"GET /a": 10, "GET /b": 10}
+	var errors map[string]int64
+	for range 30 {
+		counts, errors = AddSummary(counts, errors, map[string]int64{"GET /hot": 1}, nil)
+		CapCounts(counts, errors, 2)
+	}
+
+	require.Contains(t, counts, "GET /hot")
+	assert.LessOrEqual(t, counts["GET /hot"], int64(30))
+	assert.GreaterOrEqual(t, counts["GET /hot"]+errors["GET /hot"], int64(30))
+	// The total stays exact
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	assert.Equal(t, int64(50), total)
+}
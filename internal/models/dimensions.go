@@ -0,0 +1,160 @@
+package models
+
+import "strings"
+
+// Dimensions holds the requests of a window per dimension and key, e.g. Dimensions[DimensionPath]["GET /"].
+// Built-in and custom dimensions are counted alike, so a new dimension only needs its extractor.
+type Dimensions map[string]map[string]int64
+
+// AttributeDimensionPrefix prefixes the dimensions counting requests per value of an entry attribute, e.g.
+// "attribute.region". Custom dimension names hold no dot, so they never collide with attribute dimensions.
+const AttributeDimensionPrefix = "attribute."
+
+// AttributeDimension returns the name of the dimension counting requests per value of attribute.
+func AttributeDimension(attribute string) string {
+	return AttributeDimensionPrefix + attribute
+}
+
+// IsAttributeDimension reports whether name counts requests per value of an entry attribute.
+func IsAttributeDimension(name string) bool {
+	return strings.HasPrefix(name, AttributeDimensionPrefix)
+}
+
+// DimensionLabel returns the label of dimension name in the cap metrics. Attribute dimensions share
+// DimensionAttribute, as attribute names are chosen by customers.
+func DimensionLabel(name string) string {
+	if IsAttributeDimension(name) {
+		return DimensionAttribute
+	}
+	return name
+}
+
+// IsBuiltinDimension reports whether name is a built-in dimension, or a label of the cap metrics reserved
+// for the built-in breakdowns. Custom dimensions may not take these names.
+func IsBuiltinDimension(name string) bool {
+	switch name {
+	case DimensionPath, DimensionUserAgent, DimensionOS, DimensionDeviceType, DimensionBrowserMajorVersion,
+		DimensionBotOrHuman, DimensionStatusClass, DimensionPathUserAgent, DimensionUniqueVisitorPath, DimensionAttribute:
+		return true
+	}
+	return false
+}
+
+// Counts returns the counts of dimension name, allocated on first use.
+func (w *WindowAggregates) Counts(name string) map[string]int64 {
+	if w.Dimensions == nil {
+		w.Dimensions = make(Dimensions)
+	}
+	counts, ok := w.Dimensions[name]
+	if !ok {
+		counts = make(map[string]int64)
+		w.Dimensions[name] = counts
+	}
+	return counts
+}
+
+// AddDimensions adds the counts of every dimension of src to dst and returns dst. A nil dst is allocated,
+// see AddCounts.
+func AddDimensions(dst Dimensions, src Dimensions) Dimensions {
+	return addNestedCounts(dst, src)
+}
+
+// AddDimensionSummaries adds every dimension of src with its errors in srcErrors to dst and dstErrors with
+// AddSummary and returns both. A nil dst is allocated; dstErrors only holds dimensions with errors.
+func AddDimensionSummaries(dst Dimensions, dstErrors Dimensions, src Dimensions, srcErrors Dimensions) (Dimensions, Dimensions) {
+	if dst == nil {
+		dst = make(Dimensions, len(src))
+	}
+	for name, counts := range src {
+		var errors map[string]int64
+		dst[name], errors = AddSummary(dst[name], dstErrors[name], counts, srcErrors[name])
+		if len(errors) > 0 {
+			if dstErrors == nil {
+				dstErrors = make(Dimensions)
+			}
+			dstErrors[name] = errors
+		}
+	}
+	return dst, dstErrors
+}
+
+// CapDimensions caps every dimension to its limit in limits with CapCounts, along with its errors in errors,
+// and returns the names of the dimensions that folded keys. Dimensions left without errors are dropped from
+// errors.
+func CapDimensions(dimensions Dimensions, errors Dimensions, limits CardinalityLimits) []string {
+	var capped []string
+	for name, counts := range dimensions {
+		if CapCounts(counts, errors[name], limits.MaxKeys(name)) > 0 {
+			capped = append(capped, name)
+		}
+		if _, ok := errors[name]; ok && len(errors[name]) == 0 {
+			delete(errors, name)
+		}
+	}
+	return capped
+}
+
+// LegacyDimensions holds the built-in and attribute dimensions in the JSON fields they had before they moved
+// into Dimensions. Types embedding WindowAggregates decode it next to themselves to read what earlier versions
+// stored, and the API renders it so responses keep their shape.
+type LegacyDimensions struct {
+	RequestsByPath                map[string]int64 `json:"requestsByPath"`
+	RequestsByUserAgent           map[string]int64 `json:"requestsByUserAgent"`
+	RequestsByOS                  map[string]int64 `json:"requestsByOS"`
+	RequestsByDeviceType          map[string]int64 `json:"requestsByDeviceType"`
+	RequestsByBrowserMajorVersion map[string]int64 `json:"requestsByBrowserMajorVersion"`
+	BotVsHuman                    map[string]int64 `json:"botVsHuman"`
+	RequestsByStatusClass         map[string]int64 `json:"requestsByStatusClass"`
+	// Requests per attribute and value, e.g. RequestsByAttribute["region"] for "attribute.region"
+	RequestsByAttribute map[string]map[string]int64 `json:"requestsByAttribute,omitempty"`
+}
+
+// NewLegacyDimensions returns the built-in and attribute dimensions of dimensions in their legacy fields,
+// sharing the maps.
+func NewLegacyDimensions(dimensions Dimensions) LegacyDimensions {
+	var attributes map[string]map[string]int64
+	for name, counts := range dimensions {
+		if attribute, ok := strings.CutPrefix(name, AttributeDimensionPrefix); ok {
+			if attributes == nil {
+				attributes = make(map[string]map[string]int64)
+			}
+			attributes[attribute] = counts
+		}
+	}
+	return LegacyDimensions{
+		RequestsByPath:                dimensions[DimensionPath],
+		RequestsByUserAgent:           dimensions[DimensionUserAgent],
+		RequestsByOS:                  dimensions[DimensionOS],
+		RequestsByDeviceType:          dimensions[DimensionDeviceType],
+		RequestsByBrowserMajorVersion: dimensions[DimensionBrowserMajorVersion],
+		BotVsHuman:                    dimensions[DimensionBotOrHuman],
+		RequestsByStatusClass:         dimensions[DimensionStatusClass],
+		RequestsByAttribute:           attributes,
+	}
+}
+
+// AddTo adds the legacy fields to dimensions and returns dimensions, see AddDimensions. Missing fields are
+// skipped, so documents without them leave dimensions unchanged.
+func (l LegacyDimensions) AddTo(dimensions Dimensions) Dimensions {
+	legacy := Dimensions{
+		DimensionPath:                l.RequestsByPath,
+		DimensionUserAgent:           l.RequestsByUserAgent,
+		DimensionOS:                  l.RequestsByOS,
+		DimensionDeviceType:          l.RequestsByDeviceType,
+		DimensionBrowserMajorVersion: l.RequestsByBrowserMajorVersion,
+		DimensionBotOrHuman:          l.BotVsHuman,
+		DimensionStatusClass:         l.RequestsByStatusClass,
+	}
+	for attribute, counts := range l.RequestsByAttribute {
+		legacy[AttributeDimension(attribute)] = counts
+	}
+	for name, counts := range legacy {
+		if counts == nil {
+			delete(legacy, name)
+		}
+	}
+	if len(legacy) == 0 {
+		return dimensions
+	}
+	return AddDimensions(dimensions, legacy)
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowAggregates_Counts(t *testing.T) {
+	t.Parallel()
+
+	// Zero aggregates allocate the dimensions on first use
+	var window WindowAggregates
+	window.Counts(DimensionPath)["GET /"]++
+	window.Counts("host")["api.example.com"]++
+	window.Counts("host")["api.example.com"]++
+
+	assert.Equal(t, Dimensions{DimensionPath: {"GET /": 1}, "host": {"api.example.com": 2}}, window.Dimensions)
+}
+
+func TestCapDimensions(t *testing.T) {
+	t.Parallel()
+
+	dimensions := Dimensions{
+		DimensionPath: {"GET /": 3, "GET /a": 2, "GET /b": 1},
+		"host":        {"a.example.com": 3, "b.example.com": 1},
+		"method":      {"GET": 2},
+	}
+	errors := Dimensions{"host": {"b.example.com": 1}}
+	capped := CapDimensions(dimensions, errors, CardinalityLimits{MaxPaths: 2, MaxDimensionKeys: 1})
+
+	assert.ElementsMatch(t, []string{DimensionPath, "host"}, capped)
+	assert.Equal(t, Dimensions{
+		DimensionPath: {"GET /": 3, "GET /a": 2, OtherKey: 1},
+		"host":        {"a.example.com": 3, OtherKey: 1},
+		"method":      {"GET": 2},
+	}, dimensions)
+	// The folded key dropped its error, and the dimension without errors left
+	assert.Empty(t, errors)
+}
+
+func TestLegacyDimensions(t *testing.T) {
+	t.Parallel()
+
+	dimensions := Dimensions{
+		DimensionPath:       {"GET /": 1},
+		DimensionBotOrHuman: {"human": 1},
+		"host":              {"api.example.com": 1},
+		"attribute.region":  {"eu-west-1": 1},
+	}
+	legacy := NewLegacyDimensions(dimensions)
+	assert.Equal(t, map[string]int64{"GET /": 1}, legacy.RequestsByPath)
+	assert.Equal(t, map[string]int64{"human": 1}, legacy.BotVsHuman)
+	assert.Nil(t, legacy.RequestsByUserAgent)
+	assert.Equal(t, map[string]map[string]int64{"region": {"eu-west-1": 1}}, legacy.RequestsByAttribute)
+
+	assert.Equal(t, Dimensions{
+		DimensionPath:       {"GET /": 2},
+		DimensionBotOrHuman: {"human": 2},
+		"host":              {"api.example.com": 1},
+		"attribute.region":  {"eu-west-1": 2},
+	}, legacy.AddTo(Dimensions{DimensionPath: {"GET /": 1}, DimensionBotOrHuman: {"human": 1}, "host": {"api.example.com": 1}, "attribute.region": {"eu-west-1": 1}}))
+	assert.Nil(t, LegacyDimensions{}.AddTo(nil))
+}
+
+func TestWindowAggregateResult_UnmarshalJSON_LegacyDimensions(t *testing.T) {
+	t.Parallel()
+
+	// Stored before the built-in and attribute dimensions moved into dimensions
+	data := `{"customerId":"cus-axon","windowSize":"minute","requestsByPath":{"GET /":2},"requestsByUserAgent":{"Chrome":2},
+		"requestsByOS":{"Windows":2},"botVsHuman":{"human":2},"requestsByStatusClass":{},"dimensions":{"host":{"api.example.com":2}},
+		"requestsByAttribute":{"region":{"eu-west-1":2}},"bytesSum":10,"revision":1}`
+
+	var result WindowAggregateResult
+	require.NoError(t, json.Unmarshal([]byte(data), &result))
+
+	assert.Equal(t, "cus-axon", result.CustomerID)
+	assert.Equal(t, int64(10), result.BytesSum)
+	assert.Equal(t, 1, result.Revision)
+	assert.Equal(t, Dimensions{
+		DimensionPath:        {"GET /": 2},
+		DimensionUserAgent:   {"Chrome": 2},
+		DimensionOS:          {"Windows": 2},
+		DimensionBotOrHuman:  {"human": 2},
+		DimensionStatusClass: {},
+		"host":               {"api.example.com": 2},
+		"attribute.region":   {"eu-west-1": 2},
+	}, result.Dimensions)
+
+	// Written back, the built-in dimensions are only found in dimensions
+	written, err := json.Marshal(&result)
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(written, &fields))
+	assert.NotContains(t, fields, "requestsByPath")
+	assert.NotContains(t, fields, "requestsByAttribute")
+	assert.JSONEq(t, `{"Chrome":2}`, string(mustField(t, fields["dimensions"], DimensionUserAgent)))
+}
+
+func mustField(t *testing.T, object json.RawMessage, name string) json.RawMessage {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(object, &fields))
+	require.Contains(t, fields, name)
+	return fields[name]
+}
@@ -0,0 +1,89 @@
+package models
+
+import "log-analytics/internal/shared/sketches"
+
+// LatencyStats summarizes the durations reported for the requests of one key. Stats add up across batches
+// and windows; the average is SumMs / Count. Sketch answers quantile queries, it is nil for stats stored
+// before sketches were kept.
+type LatencyStats struct {
+	Count  int64              `json:"count"`
+	SumMs  float64            `json:"sumMs"`
+	MinMs  float64            `json:"minMs"`
+	MaxMs  float64            `json:"maxMs"`
+	Sketch *sketches.DDSketch `json:"sketch,omitempty"`
+}
+
+// Observe adds one duration.
+func (s *LatencyStats) Observe(durationMs float64) {
+	if s.Count == 0 || durationMs < s.MinMs {
+		s.MinMs = durationMs
+	}
+	if s.Count == 0 || durationMs > s.MaxMs {
+		s.MaxMs = durationMs
+	}
+	s.Count++
+	s.SumMs += durationMs
+	if s.Sketch == nil {
+		s.Sketch = sketches.NewDDSketch()
+	}
+	s.Sketch.Add(durationMs)
+}
+
+// Merge adds the durations summarized by other. other is never aliased.
+func (s *LatencyStats) Merge(other *LatencyStats) {
+	if other == nil || other.Count == 0 {
+		return
+	}
+	if s.Count == 0 || other.MinMs < s.MinMs {
+		s.MinMs = other.MinMs
+	}
+	if s.Count == 0 || other.MaxMs > s.MaxMs {
+		s.MaxMs = other.MaxMs
+	}
+	s.Count += other.Count
+	s.SumMs += other.SumMs
+	if other.Sketch != nil {
+		if s.Sketch == nil {
+			s.Sketch = other.Sketch.Clone()
+		} else {
+			s.Sketch.Merge(other.Sketch)
+		}
+	}
+}
+
+// Quantile returns the duration at quantile q, within 0 and 1, accurate to 1%. It reports false when no
+// sketch was kept.
+func (s *LatencyStats) Quantile(q float64) (float64, bool) {
+	if s.Sketch == nil || s.Sketch.Count() == 0 {
+		return 0, false
+	}
+	return s.Sketch.Quantile(q), true
+}
+
+// AddLatencies merges the stats of src into dst and returns dst. A nil dst is allocated, see AddCounts.
+func AddLatencies(dst map[string]*LatencyStats, src map[string]*LatencyStats) map[string]*LatencyStats {
+	if dst == nil {
+		dst = make(map[string]*LatencyStats, len(src))
+	}
+	for key, stats := range src {
+		merged, ok := dst[key]
+		if !ok {
+			merged = &LatencyStats{}
+			dst[key] = merged
+		}
+		merged.Merge(stats)
+	}
+	return dst
+}
+
+// CapLatencies folds the stats of every key missing from counts into OtherKey. It follows a map capped by
+// CapCounts, so latencies are kept for the same keys as their request counts.
+func CapLatencies(latencies map[string]*LatencyStats, counts map[string]int64) {
+	foldMissingKeys(latencies, counts, func(other *LatencyStats, folded *LatencyStats) *LatencyStats {
+		if other == nil {
+			other = &LatencyStats{}
+		}
+		other.Merge(folded)
+		return other
+	})
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyStats_ObserveAndMerge(t *testing.T) {
+	t.Parallel()
+
+	var stats LatencyStats
+	stats.Observe(20)
+	stats.Observe(5)
+	assertLatencyStats(t, LatencyStats{Count: 2, SumMs: 25, MinMs: 5, MaxMs: 20}, &stats)
+
+	other := LatencyStats{}
+	for _, durationMs := range []float64{50, 100, 150} {
+		other.Observe(durationMs)
+	}
+	stats.Merge(&other)
+	assertLatencyStats(t, LatencyStats{Count: 5, SumMs: 325, MinMs: 5, MaxMs: 150}, &stats)
+	p50, ok := stats.Quantile(0.5)
+	require.True(t, ok)
+	assert.InEpsilon(t, 50, p50, 0.01)
+	assert.Equal(t, uint64(3), other.Sketch.Count(), "merged stats are not modified")
+
+	// Empty stats do not reset the minimum
+	stats.Merge(&LatencyStats{})
+	stats.Merge(nil)
+	assertLatencyStats(t, LatencyStats{Count: 5, SumMs: 325, MinMs: 5, MaxMs: 150}, &stats)
+}
+
+func TestLatencyStats_Quantile_WithoutSketch(t *testing.T) {
+	t.Parallel()
+
+	// Stored before sketches were kept
+	stats := LatencyStats{Count: 2, SumMs: 30, MinMs: 10, MaxMs: 20}
+	_, ok := stats.Quantile(0.5)
+	assert.False(t, ok)
+}
+
+func TestAddLatencies(t *testing.T) {
+	t.Parallel()
+
+	src := map[string]*LatencyStats{"GET /": {Count: 1, SumMs: 10, MinMs: 10, MaxMs: 10}}
+	dst := AddLatencies(nil, src)
+	dst = AddLatencies(dst, src)
+
+	assert.Equal(t, map[string]*LatencyStats{"GET /": {Count: 2, SumMs: 20, MinMs: 10, MaxMs: 10}}, dst)
+	// src is not aliased
+	assert.Equal(t, int64(1), src["GET /"].Count)
+}
+
+func TestAddLatencies_CopiesSketch(t *testing.T) {
+	t.Parallel()
+
+	var stats LatencyStats
+	stats.Observe(10)
+	src := map[string]*LatencyStats{"GET /": &stats}
+	dst := AddLatencies(nil, src)
+	dst["GET /"].Observe(20)
+
+	assert.NotSame(t, stats.Sketch, dst["GET /"].Sketch)
+	assert.Equal(t, uint64(1), stats.Sketch.Count())
+	assert.Equal(t, uint64(2), dst["GET /"].Sketch.Count())
+}
+
+func TestCapLatencies(t *testing.T) {
+	t.Parallel()
+
+	latencies := map[string]*LatencyStats{
+		"GET /":  {Count: 1, SumMs: 10, MinMs: 10, MaxMs: 10},
+		"GET /a": {Count: 1, SumMs: 20, MinMs: 20, MaxMs: 20},
+		"GET /b": {Count: 1, SumMs: 2, MinMs: 2, MaxMs: 2},
+		OtherKey: {Count: 1, SumMs: 5, MinMs: 5, MaxMs: 5},
+	}
+	CapLatencies(latencies, map[string]int64{"GET /": 3, OtherKey: 3})
+
+	assert.Equal(t, map[string]*LatencyStats{
+		"GET /":  {Count: 1, SumMs: 10, MinMs: 10, MaxMs: 10},
+		OtherKey: {Count: 3, SumMs: 27, MinMs: 2, MaxMs: 20},
+	}, latencies)
+}
+
+// assertLatencyStats compares everything but the sketch, which is covered by the sketches package.
+func assertLatencyStats(t *testing.T, expected LatencyStats, actual *LatencyStats) {
+	t.Helper()
+	require.NotNil(t, actual)
+	assert.Equal(t, expected.Count, actual.Count, "count")
+	assert.Equal(t, expected.SumMs, actual.SumMs, "sumMs")
+	assert.Equal(t, expected.MinMs, actual.MinMs, "minMs")
+	assert.Equal(t, expected.MaxMs, actual.MaxMs, "maxMs")
+}
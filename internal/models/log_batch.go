@@ -1,16 +1,42 @@
 package models
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
+
+// keySegmentIDPattern keeps client-chosen IDs, e.g. batch and replay IDs, usable as a single storage key
+// segment, so they cannot reach outside the prefix they are stored under
+var keySegmentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// IsKeySegmentID reports whether id is 1 to 64 letters, digits, underscores and hyphens starting with a letter
+// or digit, and thus safe as a single storage key segment.
+func IsKeySegmentID(id string) bool {
+	return keySegmentIDPattern.MatchString(id)
+}
 
 type LogEntry struct {
 	ReceivedAt time.Time
 	Method     string
 	Path       string
 	UserAgent  string
+
+	// Optional fields, zero when the client did not report them
+	Status        int      `json:",omitempty"` // HTTP status code, 100-599
+	DurationMs    *float64 `json:",omitempty"` // nil when not reported, as 0 is a valid duration
+	ResponseBytes int64    `json:",omitempty"`
+	ClientIP      string   `json:",omitempty"`
+	Host          string   `json:",omitempty"`
+	UserID        string   `json:",omitempty"` // the client's own user identifier, for unique visitor counts
+
+	// Attributes are free-form tags of the entry, e.g. region or appVersion. Customers pick the attributes
+	// their requests are grouped by.
+	Attributes map[string]string `json:",omitempty"`
 }
 
 type LogBatch struct {
 	BatchID    string
 	CustomerID string
+	IngestedAt time.Time
 	Entries    []*LogEntry
 }
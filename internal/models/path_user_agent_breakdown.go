@@ -0,0 +1,22 @@
+package models
+
+// AddBreakdown adds the path by user agent counts of src to dst and returns dst. A nil dst is allocated,
+// see AddCounts.
+func AddBreakdown(dst map[string]map[string]int64, src map[string]map[string]int64) map[string]map[string]int64 {
+	return addNestedCounts(dst, src)
+}
+
+// CapBreakdown caps a path by user agent breakdown: the rows of paths missing from pathCounts, a map capped
+// by CapCounts, are folded into the OtherKey row, then every row is capped to maxUserAgents user agents. Rows
+// follow the path limit, so maxUserAgents is kept much lower than the user agent limit to bound the cells.
+// Rows track no errors, so their kept counts are lower bounds, see CapCounts. It returns the number of user agent keys folded.
+func CapBreakdown(breakdown map[string]map[string]int64, pathCounts map[string]int64, maxUserAgents int) int {
+	foldMissingKeys(breakdown, pathCounts, func(other map[string]int64, folded map[string]int64) map[string]int64 {
+		return AddCounts(other, folded)
+	})
+	folded := 0
+	for _, userAgents := range breakdown {
+		folded += CapCounts(userAgents, nil, maxUserAgents)
+	}
+	return folded
+}
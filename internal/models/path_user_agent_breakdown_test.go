@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddBreakdown(t *testing.T) {
+	t.Parallel()
+
+	src := map[string]map[string]int64{"GET /": {"Chrome": 1, "Firefox": 2}}
+	dst := AddBreakdown(nil, src)
+	dst = AddBreakdown(dst, src)
+
+	assert.Equal(t, map[string]map[string]int64{"GET /": {"Chrome": 2, "Firefox": 4}}, dst)
+	// src is not aliased
+	assert.Equal(t, int64(1), src["GET /"]["Chrome"])
+}
+
+func TestCapBreakdown(t *testing.T) {
+	t.Parallel()
+
+	breakdown := map[string]map[string]int64{
+		"GET /":  {"Chrome": 5, "Firefox": 2, "Safari": 1},
+		"GET /a": {"Chrome": 1},
+		"GET /b": {"Safari": 2},
+		OtherKey: {"Chrome": 1},
+	}
+	folded := CapBreakdown(breakdown, map[string]int64{"GET /": 8, OtherKey: 4}, 1)
+
+	assert.Equal(t, map[string]map[string]int64{
+		"GET /":  {"Chrome": 5, OtherKey: 3},
+		OtherKey: {"Chrome": 2, OtherKey: 2},
+	}, breakdown)
+	assert.Equal(t, 3, folded)
+}
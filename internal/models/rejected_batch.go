@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RejectedEntry is a log entry dropped from a batch ingested in partial accept mode.
+type RejectedEntry struct {
+	Index   int    `json:"index"` // zero-based position of the entry in the batch, blank NDJSON lines not counted
+	Reason  string `json:"reason"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Raw     string `json:"raw,omitempty"` // the entry as received
+}
+
+// RejectedBatch holds the entries dropped from a log batch, quarantined for inspection.
+type RejectedBatch struct {
+	BatchID    string           `json:"batchId"`
+	CustomerID string           `json:"customerId"`
+	RejectedAt time.Time        `json:"rejectedAt"`
+	Entries    []*RejectedEntry `json:"entries"`
+}
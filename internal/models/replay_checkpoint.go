@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ReplayCheckpoint records the progress of a replay: every batch whose ID sorts at or before CompletedUpTo
+// was rebuilt into the replay output. The customer and range are kept so a resumed replay can be checked
+// against the one that was started.
+type ReplayCheckpoint struct {
+	ReplayID      string    `json:"replayId"`
+	CustomerID    string    `json:"customerId"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	CompletedUpTo string    `json:"completedUpTo,omitempty"` // empty until the first batch completed
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
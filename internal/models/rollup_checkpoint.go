@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RollupCheckpoint records how far the window aggregate results of a customer were rolled up into a
+// coarser window size. Every window of WindowSize ending at or before RolledUpTo has been rolled up.
+type RollupCheckpoint struct {
+	CustomerID string     `json:"customerId"`
+	WindowSize WindowSize `json:"windowSize"`
+	RolledUpTo time.Time  `json:"rolledUpTo"` // zero when nothing was rolled up yet
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// RollupRequeue asks the rollup job to roll a target window up again because one of its source windows changed
+// after the window was rolled up, e.g. by a late insight correction.
+type RollupRequeue struct {
+	ID          string     `json:"id"`
+	CustomerID  string     `json:"customerId"`
+	WindowSize  WindowSize `json:"windowSize"` // the target window size
+	WindowStart time.Time  `json:"windowStart"`
+	RequeuedAt  time.Time  `json:"requeuedAt"`
+}
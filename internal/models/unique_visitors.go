@@ -0,0 +1,73 @@
+package models
+
+import "log-analytics/internal/shared/sketches"
+
+// VisitorIdentity names the log entry fields identifying a visitor for unique visitor counts.
+type VisitorIdentity string
+
+const (
+	VisitorIdentityNone        VisitorIdentity = "none"          // unique visitors are not counted
+	VisitorIdentityClientIP    VisitorIdentity = "client_ip"     // clientIp
+	VisitorIdentityUserAgentIP VisitorIdentity = "user_agent_ip" // clientIp and userAgent, tells apart clients behind one NAT
+	VisitorIdentityUserID      VisitorIdentity = "user_id"       // userId
+)
+
+// Of returns the identity of the visitor of entry, empty when the entry lacks a field the identity needs.
+func (i VisitorIdentity) Of(entry *LogEntry) string {
+	switch i {
+	case VisitorIdentityClientIP:
+		return entry.ClientIP
+	case VisitorIdentityUserAgentIP:
+		if entry.ClientIP == "" {
+			return ""
+		}
+		return entry.ClientIP + "\x00" + entry.UserAgent
+	case VisitorIdentityUserID:
+		return entry.UserID
+	default:
+		return ""
+	}
+}
+
+// MergeHyperLogLog merges src into dst and returns dst. A nil dst is allocated, src is never aliased.
+func MergeHyperLogLog(dst *sketches.HyperLogLog, src *sketches.HyperLogLog) *sketches.HyperLogLog {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		return src.Clone()
+	}
+	dst.Merge(src)
+	return dst
+}
+
+// AddUniqueVisitors merges the sketches of src into dst and returns dst. A nil dst is allocated, see AddCounts.
+func AddUniqueVisitors(dst map[string]*sketches.HyperLogLog, src map[string]*sketches.HyperLogLog) map[string]*sketches.HyperLogLog {
+	if dst == nil {
+		dst = make(map[string]*sketches.HyperLogLog, len(src))
+	}
+	for key, visitors := range src {
+		dst[key] = MergeHyperLogLog(dst[key], visitors)
+	}
+	return dst
+}
+
+// CapUniqueVisitors folds the sketches of every key missing from counts into OtherKey, see CapLatencies, then
+// keeps a sketch for at most limit of the remaining keys, the ones with the highest counts, and folds the others
+// too. Dense sketches take 4KB each, so they are capped much lower than the keys of counts. 0 means unlimited.
+// It returns the number of sketches folded.
+func CapUniqueVisitors(visitors map[string]*sketches.HyperLogLog, counts map[string]int64, limit int) int {
+	kept := counts
+	if ranked := rankKeys(visitors, counts); limit > 0 && len(ranked) > limit {
+		kept = make(map[string]int64, limit)
+		for _, key := range ranked[:limit] {
+			kept[key] = counts[key]
+		}
+	}
+	folded := 0
+	foldMissingKeys(visitors, kept, func(other *sketches.HyperLogLog, visitors *sketches.HyperLogLog) *sketches.HyperLogLog {
+		folded++
+		return MergeHyperLogLog(other, visitors)
+	})
+	return folded
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"log-analytics/internal/shared/sketches"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapUniqueVisitors(t *testing.T) {
+	t.Parallel()
+
+	sketchOf := func(visitors ...string) *sketches.HyperLogLog {
+		sketch := sketches.NewHyperLogLog()
+		for _, visitor := range visitors {
+			sketch.Add(visitor)
+		}
+		return sketch
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		wantKeys   []string
+		wantOther  int64
+		wantFolded int
+	}{
+		{name: "unlimited follows the counts", limit: 0, wantKeys: []string{"GET /", "GET /a", OtherKey}, wantOther: 2, wantFolded: 1},
+		{name: "capped below the counts", limit: 1, wantKeys: []string{"GET /", OtherKey}, wantOther: 3, wantFolded: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitors := map[string]*sketches.HyperLogLog{
+				"GET /":  sketchOf("v1", "v2"),
+				"GET /a": sketchOf("v3"),
+				"GET /b": sketchOf("v4"),
+				OtherKey: sketchOf("v5"),
+			}
+			// GET /b was folded away from the counts already
+			counts := map[string]int64{"GET /": 5, "GET /a": 2, OtherKey: 4}
+
+			folded := CapUniqueVisitors(visitors, counts, tt.limit)
+
+			assert.Equal(t, tt.wantFolded, folded)
+			assert.ElementsMatch(t, tt.wantKeys, keysOf(visitors))
+			require.NotNil(t, visitors[OtherKey])
+			assert.Equal(t, tt.wantOther, visitors[OtherKey].Estimate())
+			assert.Equal(t, int64(2), visitors["GET /"].Estimate())
+		})
+	}
+}
+
+func keysOf[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	return keys
+}
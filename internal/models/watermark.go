@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Watermark tracks the event-time progress of a customer. The watermark itself is MaxReceivedAt minus the
+// allowed lateness: entries older than that are not expected anymore, so the windows ending before it can
+// be finalized.
+type Watermark struct {
+	CustomerID    string    `json:"customerId"`
+	MaxReceivedAt time.Time `json:"maxReceivedAt"` // zero when nothing was aggregated yet
+	// FinalizedUpTo holds, per window size, the time every window ending at or before it was finalized at.
+	FinalizedUpTo map[WindowSize]time.Time `json:"finalizedUpTo,omitempty"`
+	UpdatedAt     time.Time                `json:"updatedAt"`
+}
+
+func NewEmptyWatermark(customerID string) *Watermark {
+	return &Watermark{
+		CustomerID:    customerID,
+		FinalizedUpTo: make(map[WindowSize]time.Time),
+	}
+}
+
+// Watermark returns the event time up to which windows can be finalized, zero when nothing was seen yet.
+func (w *Watermark) Watermark(allowedLateness time.Duration) time.Time {
+	if w.MaxReceivedAt.IsZero() {
+		return time.Time{}
+	}
+	return w.MaxReceivedAt.Add(-allowedLateness)
+}
+
+// IsWindowFinalized reports whether the window of windowSize starting at windowStart was finalized.
+func (w *Watermark) IsWindowFinalized(windowSize WindowSize, windowStart time.Time) bool {
+	return windowStart.Before(w.FinalizedUpTo[windowSize])
+}
+
+// Clone returns a deep copy of the watermark.
+func (w *Watermark) Clone() *Watermark {
+	clone := *w
+	clone.FinalizedUpTo = make(map[WindowSize]time.Time, len(w.FinalizedUpTo))
+	for windowSize, upTo := range w.FinalizedUpTo {
+		clone.FinalizedUpTo[windowSize] = upTo
+	}
+	return &clone
+}
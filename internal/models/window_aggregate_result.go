@@ -1,25 +1,96 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"log-analytics/internal/shared/sketches"
+)
 
 type WindowAggregateResult struct {
-	CustomerID          string           `json:"customerId"`
-	WindowStart         time.Time        `json:"windowStart"`
-	WindowSize          WindowSize       `json:"windowSize"`
-	RequestsByPath      map[string]int64 `json:"requestsByPath"`
-	RequestsByUserAgent map[string]int64 `json:"requestsByUserAgent"`
+	CustomerID  string     `json:"customerId"`
+	WindowStart time.Time  `json:"windowStart"`
+	WindowSize  WindowSize `json:"windowSize"`
+	// Counts of the window, merged from its partial insights or from finer windows
+	WindowAggregates
+	// AppliedBatchIDs is the sorted set of batch IDs already rolled up into this window, used to skip
+	// redelivered partial insights. Past maxAppliedBatchIDs the IDs move to AppliedBatches, keeping the
+	// window file bounded at the cost of the filter's false positives.
+	AppliedBatchIDs []string              `json:"appliedBatchIds,omitempty"`
+	AppliedBatches  *sketches.BloomFilter `json:"appliedBatches,omitempty"`
+	// Finalized is set once the customer's watermark passed the end of the window. A finalized window is
+	// no longer updated; partial insights arriving for it are late.
+	Finalized   bool      `json:"finalized,omitempty"`
+	FinalizedAt time.Time `json:"finalizedAt,omitzero"`
+	// Revision is bumped by every correction applied to the finalized window, 0 until the first one.
+	// Consumers seeing a higher revision for a window they already read know it was restated.
+	Revision int `json:"revision,omitempty"`
 }
 
 func NewEmptyWindowAggregateResult(customerID string, windowStart time.Time, windowSize WindowSize) *WindowAggregateResult {
 	return &WindowAggregateResult{
-		CustomerID:          customerID,
-		WindowStart:         windowStart,
-		WindowSize:          windowSize,
-		RequestsByPath:      make(map[string]int64),
-		RequestsByUserAgent: make(map[string]int64),
+		CustomerID:       customerID,
+		WindowStart:      windowStart,
+		WindowSize:       windowSize,
+		WindowAggregates: NewEmptyWindowAggregates(),
+	}
+}
+
+// UnmarshalJSON also reads the built-in dimensions of results stored before they moved into Dimensions, see
+// LegacyDimensions.
+func (w *WindowAggregateResult) UnmarshalJSON(data []byte) error {
+	type plain WindowAggregateResult
+	wire := struct {
+		*plain
+		LegacyDimensions
+	}{plain: (*plain)(w)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
 	}
+	w.Dimensions = wire.LegacyDimensions.AddTo(w.Dimensions)
+	return nil
 }
 
 func (w *WindowAggregateResult) IsNewAggregate() bool {
-	return len(w.RequestsByPath) == 0 && len(w.RequestsByUserAgent) == 0
-}
\ No newline at end of file
+	return len(w.Dimensions[DimensionPath]) == 0 && len(w.Dimensions[DimensionUserAgent]) == 0
+}
+
+// maxAppliedBatchIDs is how many applied batch IDs a window keeps exactly. A window with more batches tracks
+// them in a sketches.BloomFilter: beyond a few thousand batches per window a redelivered batch may then be
+// missed by at most the filter's false positive rate, and a new batch is skipped just as rarely.
+const maxAppliedBatchIDs = 256
+
+// HasAppliedBatch reports whether the partial insight of batchID was already rolled up into this window.
+func (w *WindowAggregateResult) HasAppliedBatch(batchID string) bool {
+	if w.AppliedBatches != nil {
+		return w.AppliedBatches.Contains(batchID)
+	}
+	idx := sort.SearchStrings(w.AppliedBatchIDs, batchID)
+	return idx < len(w.AppliedBatchIDs) && w.AppliedBatchIDs[idx] == batchID
+}
+
+// MarkBatchApplied records batchID in AppliedBatchIDs, keeping the set sorted, or in AppliedBatches once
+// the window has applied more than maxAppliedBatchIDs batches.
+func (w *WindowAggregateResult) MarkBatchApplied(batchID string) {
+	if w.AppliedBatches != nil {
+		w.AppliedBatches.Add(batchID)
+		return
+	}
+	idx := sort.SearchStrings(w.AppliedBatchIDs, batchID)
+	if idx < len(w.AppliedBatchIDs) && w.AppliedBatchIDs[idx] == batchID {
+		return
+	}
+	if len(w.AppliedBatchIDs) == maxAppliedBatchIDs {
+		w.AppliedBatches = sketches.NewBloomFilter()
+		for _, appliedBatchID := range w.AppliedBatchIDs {
+			w.AppliedBatches.Add(appliedBatchID)
+		}
+		w.AppliedBatches.Add(batchID)
+		w.AppliedBatchIDs = nil
+		return
+	}
+	w.AppliedBatchIDs = append(w.AppliedBatchIDs, "")
+	copy(w.AppliedBatchIDs[idx+1:], w.AppliedBatchIDs[idx:])
+	w.AppliedBatchIDs[idx] = batchID
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowAggregateResult_MarkBatchApplied(t *testing.T) {
+	t.Parallel()
+
+	result := &WindowAggregateResult{}
+	result.MarkBatchApplied("batch-b")
+	result.MarkBatchApplied("batch-a")
+	result.MarkBatchApplied("batch-b")
+
+	assert.Equal(t, []string{"batch-a", "batch-b"}, result.AppliedBatchIDs)
+	assert.Nil(t, result.AppliedBatches)
+	assert.True(t, result.HasAppliedBatch("batch-a"))
+	assert.False(t, result.HasAppliedBatch("batch-c"))
+}
+
+func TestWindowAggregateResult_MarkBatchApplied_SwitchesToBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	result := &WindowAggregateResult{}
+	for i := range maxAppliedBatchIDs + 10 {
+		result.MarkBatchApplied(fmt.Sprintf("batch-%d", i))
+	}
+
+	require.NotNil(t, result.AppliedBatches)
+	assert.Empty(t, result.AppliedBatchIDs, "the exact IDs move into the filter")
+	for i := range maxAppliedBatchIDs + 10 {
+		assert.True(t, result.HasAppliedBatch(fmt.Sprintf("batch-%d", i)))
+	}
+	assert.False(t, result.HasAppliedBatch("batch-new"))
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"slices"
+
+	"log-analytics/internal/shared/sketches"
+)
+
+// WindowAggregates holds the request counts of one window per dimension, see Dimensions. The built-in
+// dimensions count the path, the browser family (DimensionUserAgent), the operating system, the device type
+// (desktop, mobile, tablet, bot), the browser major version (e.g. "Chrome 120"), bot or human and the status
+// class ("2xx", "4xx", ...) of entries reporting a status. User agents the parser does not recognize are
+// counted under UnknownKey in every user agent dimension. Custom dimensions and the attribute group-bys of the
+// customer, e.g. "attribute.region" (see AttributeDimension), are counted next to them.
+//
+// Response bytes are summed and reported durations are summarized per path, under the same keys as the path
+// dimension. Unique visitors are HyperLogLog sketches, serialized with their estimate.
+//
+// Customers enabling the path by user agent breakdown also get requestsByPathAndUserAgent, the requests of
+// every path per browser family. Its paths follow the path dimension and every path keeps
+// CardinalityLimits.MaxBreakdownUserAgents user agents, folding the rest into OtherKey.
+//
+// Window results, partial insights and corrections embed WindowAggregates, so they are merged with Add and
+// capped with Cap as one.
+type WindowAggregates struct {
+	// Requests per dimension and key
+	Dimensions Dimensions `json:"dimensions"`
+	// Requests a kept key may have lost to OtherKey before it was kept, per dimension and key, see CapCounts.
+	// Only keys with an error are present.
+	CountErrors Dimensions `json:"countErrors,omitempty"`
+
+	BytesSum      int64                    `json:"bytesSum"`
+	LatencyByPath map[string]*LatencyStats `json:"latencyByPath"`
+
+	// Distinct visitors of the window and per path, see VisitorIdentity. Nil when not counted.
+	UniqueVisitors       *sketches.HyperLogLog            `json:"uniqueVisitors,omitempty"`
+	UniqueVisitorsByPath map[string]*sketches.HyperLogLog `json:"uniqueVisitorsByPath,omitempty"`
+
+	// Requests per path and browser family, nil unless enabled for the customer
+	RequestsByPathAndUserAgent map[string]map[string]int64 `json:"requestsByPathAndUserAgent,omitempty"`
+}
+
+func NewEmptyWindowAggregates() WindowAggregates {
+	return WindowAggregates{
+		Dimensions:    make(Dimensions),
+		LatencyByPath: make(map[string]*LatencyStats),
+	}
+}
+
+// Add adds the counts, sums, latencies and sketches of src, never aliasing them. Dimensions are merged as
+// summaries with their errors, see AddSummary. Fields missing from w, as in
+// results stored before they existed, are allocated.
+func (w *WindowAggregates) Add(src WindowAggregates) {
+	w.Dimensions, w.CountErrors = AddDimensionSummaries(w.Dimensions, w.CountErrors, src.Dimensions, src.CountErrors)
+	w.BytesSum += src.BytesSum
+	w.LatencyByPath = AddLatencies(w.LatencyByPath, src.LatencyByPath)
+	w.UniqueVisitors = MergeHyperLogLog(w.UniqueVisitors, src.UniqueVisitors)
+	w.UniqueVisitorsByPath = AddUniqueVisitors(w.UniqueVisitorsByPath, src.UniqueVisitorsByPath)
+	if src.RequestsByPathAndUserAgent != nil {
+		w.RequestsByPathAndUserAgent = AddBreakdown(w.RequestsByPathAndUserAgent, src.RequestsByPathAndUserAgent)
+	}
+}
+
+// Cap applies limits to every dimension and its errors, then keeps the latencies, the unique visitor sketches and the
+// breakdown in line with the capped paths. It returns the labels of the capped maps for the cap metrics once
+// each: the DimensionLabel of every capped dimension, DimensionUniqueVisitorPath and DimensionPathUserAgent.
+func (w *WindowAggregates) Cap(limits CardinalityLimits) []string {
+	var capped []string
+	for _, name := range CapDimensions(w.Dimensions, w.CountErrors, limits) {
+		if label := DimensionLabel(name); !slices.Contains(capped, label) {
+			capped = append(capped, label)
+		}
+	}
+	paths := w.Dimensions[DimensionPath]
+	if slices.Contains(capped, DimensionPath) {
+		CapLatencies(w.LatencyByPath, paths)
+	}
+	if CapUniqueVisitors(w.UniqueVisitorsByPath, paths, limits.MaxUniqueVisitorPaths) > 0 {
+		capped = append(capped, DimensionUniqueVisitorPath)
+	}
+	if CapBreakdown(w.RequestsByPathAndUserAgent, paths, limits.MaxBreakdownUserAgents) > 0 {
+		capped = append(capped, DimensionPathUserAgent)
+	}
+	return capped
+}
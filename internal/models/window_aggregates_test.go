@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+
+	"log-analytics/internal/shared/sketches"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowAggregates_Add(t *testing.T) {
+	t.Parallel()
+
+	visitors := sketches.NewHyperLogLog()
+	visitors.Add("10.0.0.1")
+	src := WindowAggregates{
+		Dimensions:           Dimensions{DimensionPath: {"GET /": 1}, "host": {"api.example.com": 1}},
+		BytesSum:             100,
+		LatencyByPath:        map[string]*LatencyStats{"GET /": {Count: 1, SumMs: 5, MinMs: 5, MaxMs: 5}},
+		UniqueVisitors:       visitors,
+		UniqueVisitorsByPath: map[string]*sketches.HyperLogLog{"GET /": visitors},
+	}
+
+	// Results stored before a field existed have nothing to add to
+	var dst WindowAggregates
+	dst.Add(src)
+	dst.Add(src)
+
+	assert.Equal(t, Dimensions{DimensionPath: {"GET /": 2}, "host": {"api.example.com": 2}}, dst.Dimensions)
+	assert.Equal(t, int64(200), dst.BytesSum)
+	assert.Equal(t, int64(2), dst.LatencyByPath["GET /"].Count)
+	require.NotNil(t, dst.UniqueVisitors)
+	assert.Equal(t, int64(1), dst.UniqueVisitors.Estimate())
+	// Without a breakdown in src, none is allocated
+	assert.Nil(t, dst.RequestsByPathAndUserAgent)
+	// src is not aliased
+	assert.Equal(t, int64(1), src.Dimensions[DimensionPath]["GET /"])
+	assert.NotSame(t, visitors, dst.UniqueVisitors)
+}
+
+func TestWindowAggregates_Cap(t *testing.T) {
+	t.Parallel()
+
+	window := WindowAggregates{
+		Dimensions: Dimensions{
+			DimensionPath:      {"GET /": 3, "GET /a": 1},
+			DimensionUserAgent: {"Chrome": 4},
+		},
+		LatencyByPath: map[string]*LatencyStats{
+			"GET /":  {Count: 3, SumMs: 30, MinMs: 5, MaxMs: 15},
+			"GET /a": {Count: 1, SumMs: 20, MinMs: 20, MaxMs: 20},
+		},
+		RequestsByPathAndUserAgent: map[string]map[string]int64{"GET /": {"Chrome": 3}, "GET /a": {"Chrome": 1}},
+	}
+	capped := window.Cap(CardinalityLimits{MaxPaths: 1})
+
+	assert.Equal(t, []string{DimensionPath}, capped)
+	assert.Equal(t, map[string]int64{"GET /": 3, OtherKey: 1}, window.Dimensions[DimensionPath])
+	// Latencies and the breakdown follow the capped paths
+	assert.ElementsMatch(t, []string{"GET /", OtherKey}, keysOf(window.LatencyByPath))
+	assert.Equal(t, map[string]map[string]int64{"GET /": {"Chrome": 3}, OtherKey: {"Chrome": 1}}, window.RequestsByPathAndUserAgent)
+}
+
+func TestWindowAggregates_Cap_AttributeLabel(t *testing.T) {
+	t.Parallel()
+
+	window := WindowAggregates{
+		Dimensions: Dimensions{
+			"attribute.region":     {"eu-west-1": 2, "us-east-1": 1},
+			"attribute.tenantPlan": {"pro": 2, "free": 1},
+		},
+	}
+	capped := window.Cap(CardinalityLimits{MaxDimensionKeys: 1})
+
+	// Attribute dimensions share one label, reported once
+	assert.Equal(t, []string{DimensionAttribute}, capped)
+	assert.Equal(t, map[string]int64{"eu-west-1": 2, OtherKey: 1}, window.Dimensions["attribute.region"])
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// WindowCorrection is the audit record of one correction applied to a finalized window: the late batches it
+// applied and the counts they added. Revision is the revision of the window after the correction.
+type WindowCorrection struct {
+	CustomerID  string     `json:"customerId"`
+	WindowStart time.Time  `json:"windowStart"`
+	WindowSize  WindowSize `json:"windowSize"`
+	Revision    int        `json:"revision"`
+	BatchIDs    []string   `json:"batchIds"`
+	// Counts added by the late batches. Merged into the window, their unique visitors may add fewer visitors
+	// than they hold, as some were already counted.
+	WindowAggregates
+	CorrectedAt time.Time `json:"correctedAt"`
+}
+
+func NewWindowCorrection(aggregateResult *WindowAggregateResult) *WindowCorrection {
+	return &WindowCorrection{
+		CustomerID:       aggregateResult.CustomerID,
+		WindowStart:      aggregateResult.WindowStart,
+		WindowSize:       aggregateResult.WindowSize,
+		Revision:         aggregateResult.Revision,
+		WindowAggregates: NewEmptyWindowAggregates(),
+	}
+}
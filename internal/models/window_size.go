@@ -5,57 +5,175 @@ import (
 	"time"
 )
 
+// WindowSize is the length of an aggregation window. Besides the named sizes, any whole number of
+// minutes or hours that evenly divides a day is accepted as a custom size, e.g. "10m" or "6h".
 type WindowSize string
 
 const (
-	WindowMinute WindowSize = "minute"
-	WindowHour   WindowSize = "hour"
+	WindowMinute         WindowSize = "minute"
+	WindowFiveMinutes    WindowSize = "5m"
+	WindowFifteenMinutes WindowSize = "15m"
+	WindowHour           WindowSize = "hour"
+	WindowDay            WindowSize = "day"
+	WindowWeek           WindowSize = "week"
 )
 
+const day = 24 * time.Hour
+
 func NewWindowSizeFromString(windowSize string) (WindowSize, error) {
-	switch windowSize {
-	case "minute":
+	switch WindowSize(windowSize) {
+	case WindowMinute, WindowFiveMinutes, WindowFifteenMinutes, WindowHour, WindowDay, WindowWeek:
+		return WindowSize(windowSize), nil
+	}
+
+	d, err := parseCustomWindow(windowSize)
+	if err != nil {
+		return "", fmt.Errorf("invalid window size: %s", windowSize)
+	}
+	// Custom sizes equal to a named size use the name, so both spellings share one storage key
+	switch d {
+	case time.Minute:
 		return WindowMinute, nil
-	case "hour":
+	case time.Hour:
 		return WindowHour, nil
-	default:
-		return "", fmt.Errorf("invalid window size: %s", windowSize)
+	case day:
+		return WindowDay, nil
+	}
+	if d%time.Hour == 0 {
+		return WindowSize(fmt.Sprintf("%dh", d/time.Hour)), nil
+	}
+	return WindowSize(fmt.Sprintf("%dm", d/time.Minute)), nil
+}
+
+// parseCustomWindow parses a custom window size. Windows must tile a UTC day, so they never straddle midnight.
+func parseCustomWindow(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Minute || d > day || d%time.Minute != 0 || day%d != 0 {
+		return 0, fmt.Errorf("window size must be whole minutes evenly dividing a day: %s", s)
 	}
+	return d, nil
 }
 
+// Duration returns the nominal length of the window. Day and week windows in a time zone with
+// daylight saving time can be an hour shorter or longer.
 func (w WindowSize) Duration() time.Duration {
 	switch w {
 	case WindowMinute:
 		return time.Minute
 	case WindowHour:
 		return time.Hour
-	default:
+	case WindowDay:
+		return day
+	case WindowWeek:
+		return 7 * day
+	}
+	d, err := parseCustomWindow(string(w))
+	if err != nil {
 		panic(fmt.Sprintf("invalid WindowSize: %q", w))
 	}
+	return d
+}
+
+// IsCalendar reports whether the window follows the calendar (day, week) rather than a fixed UTC grid.
+func (w WindowSize) IsCalendar() bool {
+	return w == WindowDay || w == WindowWeek
+}
+
+// Truncate returns the start of the window containing t, in UTC. Day and week windows start at midnight
+// in loc, weeks on Monday; a nil loc means UTC. Shorter windows are always aligned in UTC.
+func (w WindowSize) Truncate(t time.Time, loc *time.Location) time.Time {
+	if !w.IsCalendar() {
+		return t.UTC().Truncate(w.Duration())
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	year, month, dayOfMonth := local.Date()
+	if w == WindowWeek {
+		dayOfMonth -= (int(local.Weekday()) + 6) % 7
+	}
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, loc).UTC()
+}
+
+// WindowEnd returns the exclusive end of the window starting at start, see Truncate for loc. Day and week
+// windows end at the next local midnight, so they follow daylight saving time changes.
+func (w WindowSize) WindowEnd(start time.Time, loc *time.Location) time.Time {
+	if !w.IsCalendar() {
+		return start.Add(w.Duration())
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	days := 1
+	if w == WindowWeek {
+		days = 7
+	}
+	return start.In(loc).AddDate(0, 0, days).UTC()
 }
 
+// FormatWindowStart formats a window start for storage keys. Hour windows use an hour precision
+// layout, every other window a minute precision one, as day and week windows in a customer's time
+// zone do not start on a UTC hour. Fixed size windows are truncated in UTC first.
 func (w WindowSize) FormatWindowStart(t time.Time) string {
 	utc := t.UTC()
-
-	switch w.Duration() {
-	case time.Minute:
+	if w.IsCalendar() {
 		return utc.Truncate(time.Minute).Format("20060102T1504Z")
+	}
 
+	switch d := w.Duration(); d {
 	case time.Hour:
 		return utc.Truncate(time.Hour).Format("20060102T15Z")
+	default:
+		return utc.Truncate(d).Format("20060102T1504Z")
+	}
+}
+
+// ParseWindowStart is the inverse of FormatWindowStart. It returns an error when s is not
+// formatted for this window size (e.g. an hour-formatted start parsed as a minute window).
+func (w WindowSize) ParseWindowStart(s string) (time.Time, error) {
+	if w.IsCalendar() {
+		return time.Parse("20060102T1504Z", s)
 	}
 
-	return ""
+	switch w.Duration() {
+	case time.Hour:
+		return time.Parse("20060102T15Z", s)
+	default:
+		return time.Parse("20060102T1504Z", s)
+	}
 }
 
-func (w WindowSize) BucketID(t time.Time) string {
+// BucketID identifies the window among its neighbours: the position of the window within the hour for
+// sub-hour windows, within the day for sub-day windows, the day of month for day windows and the ISO
+// week for week windows. It keys partitions and metric labels, so its cardinality stays small. Day and
+// week windows are numbered in loc, the location they were truncated in (see Truncate); a nil loc means UTC.
+func (w WindowSize) BucketID(t time.Time, loc *time.Location) string {
 	utc := t.UTC()
 
-	switch w.Duration() {
-	case time.Minute:
+	switch w {
+	case WindowMinute:
 		return fmt.Sprintf("minute-%02d", utc.Minute())
-	case time.Hour:
+	case WindowHour:
 		return fmt.Sprintf("hour-%02d", utc.Hour())
+	case WindowDay, WindowWeek:
+		if loc == nil {
+			loc = time.UTC
+		}
+		local := t.In(loc)
+		if w == WindowWeek {
+			_, week := local.ISOWeek()
+			return fmt.Sprintf("week-%02d", week)
+		}
+		return fmt.Sprintf("day-%02d", local.Day())
+	}
+
+	minutes := int(w.Duration() / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%s-%02d", w, utc.Minute()/minutes)
 	}
-	return ""
+	return fmt.Sprintf("%s-%02d", w, (utc.Hour()*60+utc.Minute())/minutes)
 }
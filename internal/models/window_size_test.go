@@ -25,6 +25,21 @@ func TestWindowSize_Duration(t *testing.T) {
 			window:   WindowHour,
 			expected: time.Hour,
 		},
+		{
+			name:     "15 minute window",
+			window:   WindowFifteenMinutes,
+			expected: 15 * time.Minute,
+		},
+		{
+			name:     "week window",
+			window:   WindowWeek,
+			expected: 7 * 24 * time.Hour,
+		},
+		{
+			name:     "custom window",
+			window:   WindowSize("6h"),
+			expected: 6 * time.Hour,
+		},
 	}
 
 	for _, tt := range tests {
@@ -35,6 +50,170 @@ func TestWindowSize_Duration(t *testing.T) {
 	}
 }
 
+func TestNewWindowSizeFromString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected WindowSize
+		wantErr  bool
+	}{
+		{input: "minute", expected: WindowMinute},
+		{input: "5m", expected: WindowFiveMinutes},
+		{input: "15m", expected: WindowFifteenMinutes},
+		{input: "day", expected: WindowDay},
+		{input: "week", expected: WindowWeek},
+		{input: "10m", expected: WindowSize("10m")},
+		{input: "90m", expected: WindowSize("90m")},
+		{input: "120m", expected: WindowSize("2h")},
+		{input: "60m", expected: WindowHour},
+		{input: "24h", expected: WindowDay},
+		{input: "7m", wantErr: true},  // does not divide a day
+		{input: "30s", wantErr: true}, // shorter than a minute
+		{input: "48h", wantErr: true}, // longer than a day
+		{input: "month", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			result, err := NewWindowSizeFromString(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestWindowSize_Truncate(t *testing.T) {
+	t.Parallel()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday 2025-12-31 03:10:45 UTC, still Tuesday evening in New York
+	testTime := time.Date(2025, 12, 31, 3, 10, 45, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		window   WindowSize
+		loc      *time.Location
+		input    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "5 minute window",
+			window:   WindowFiveMinutes,
+			input:    testTime,
+			expected: time.Date(2025, 12, 31, 3, 10, 0, 0, time.UTC),
+		},
+		{
+			name:     "15 minute window ignores time zone",
+			window:   WindowFifteenMinutes,
+			loc:      newYork,
+			input:    testTime,
+			expected: time.Date(2025, 12, 31, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day window in UTC",
+			window:   WindowDay,
+			input:    testTime,
+			expected: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day window in customer time zone",
+			window:   WindowDay,
+			loc:      newYork,
+			input:    testTime,
+			expected: time.Date(2025, 12, 30, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "week window starts on Monday",
+			window:   WindowWeek,
+			input:    testTime,
+			expected: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "week window in customer time zone",
+			window:   WindowWeek,
+			loc:      newYork,
+			input:    testTime,
+			expected: time.Date(2025, 12, 29, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "week window on a Sunday belongs to the previous Monday",
+			window:   WindowWeek,
+			input:    time.Date(2025, 12, 28, 23, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.window.Truncate(tt.input, tt.loc)
+			assert.True(t, tt.expected.Equal(result), "expected %s, got %s", tt.expected, result)
+			assert.Equal(t, time.UTC, result.Location())
+		})
+	}
+}
+
+func TestWindowSize_WindowEnd(t *testing.T) {
+	t.Parallel()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		window   WindowSize
+		loc      *time.Location
+		start    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "hour window",
+			window:   WindowHour,
+			start:    time.Date(2025, 12, 31, 3, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 12, 31, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day window in UTC",
+			window:   WindowDay,
+			start:    time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day window spanning a daylight saving change is 23 hours",
+			window:   WindowDay,
+			loc:      newYork,
+			start:    time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "week window",
+			window:   WindowWeek,
+			start:    time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.window.WindowEnd(tt.start, tt.loc)
+			assert.True(t, tt.expected.Equal(result), "expected %s, got %s", tt.expected, result)
+			assert.Equal(t, time.UTC, result.Location())
+		})
+	}
+}
+
 func TestWindowSize_Duration_Invalid(t *testing.T) {
 	t.Parallel()
 
@@ -86,6 +265,18 @@ func TestWindowSize_FormatWindowStart(t *testing.T) {
 			input:    time.Date(2025, 12, 28, 18, 0, 30, 0, time.UTC),
 			expected: "20251228T1800Z",
 		},
+		{
+			name:     "15 minute window truncates to quarter hour",
+			window:   WindowFifteenMinutes,
+			input:    testTime,
+			expected: "20251228T1800Z",
+		},
+		{
+			name:     "day window keeps a time zone aligned start",
+			window:   WindowDay,
+			input:    time.Date(2025, 12, 28, 5, 0, 0, 0, time.UTC),
+			expected: "20251228T0500Z",
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,6 +288,62 @@ func TestWindowSize_FormatWindowStart(t *testing.T) {
 	}
 }
 
+func TestWindowSize_ParseWindowStart(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		window   WindowSize
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "minute window",
+			window:   WindowMinute,
+			input:    "20251228T1803Z",
+			expected: time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC),
+		},
+		{
+			name:     "hour window",
+			window:   WindowHour,
+			input:    "20251228T18Z",
+			expected: time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "hour formatted start parsed as minute window",
+			window:  WindowMinute,
+			input:   "20251228T18Z",
+			wantErr: true,
+		},
+		{
+			name:    "minute formatted start parsed as hour window",
+			window:  WindowHour,
+			input:   "20251228T1803Z",
+			wantErr: true,
+		},
+		{
+			name:     "week window",
+			window:   WindowWeek,
+			input:    "20251229T0500Z",
+			expected: time.Date(2025, 12, 29, 5, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := tt.window.ParseWindowStart(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result))
+		})
+	}
+}
+
 func TestWindowSize_BucketID(t *testing.T) {
 	t.Parallel()
 
@@ -104,6 +351,7 @@ func TestWindowSize_BucketID(t *testing.T) {
 		name     string
 		window   WindowSize
 		input    time.Time
+		loc      *time.Location
 		expected string
 	}{
 		{
@@ -142,6 +390,44 @@ func TestWindowSize_BucketID(t *testing.T) {
 			input:    time.Date(2025, 12, 28, 23, 30, 0, 0, time.UTC),
 			expected: "hour-23",
 		},
+		{
+			name:     "5 minute window",
+			window:   WindowFiveMinutes,
+			input:    time.Date(2025, 12, 28, 18, 55, 0, 0, time.UTC),
+			expected: "5m-11",
+		},
+		{
+			name:     "custom sub-day window",
+			window:   WindowSize("6h"),
+			input:    time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+			expected: "6h-03",
+		},
+		{
+			name:     "day window",
+			window:   WindowDay,
+			input:    time.Date(2025, 12, 28, 5, 0, 0, 0, time.UTC),
+			expected: "day-28",
+		},
+		{
+			name:     "week window",
+			window:   WindowWeek,
+			input:    time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC),
+			expected: "week-01",
+		},
+		{
+			name:     "day window numbered in its location",
+			window:   WindowDay,
+			input:    time.Date(2025, 12, 27, 15, 0, 0, 0, time.UTC), // midnight Dec 28 in Tokyo
+			loc:      time.FixedZone("JST", 9*3600),
+			expected: "day-28",
+		},
+		{
+			name:     "week window numbered in its location",
+			window:   WindowWeek,
+			input:    time.Date(2025, 12, 28, 15, 0, 0, 0, time.UTC), // midnight Monday Dec 29 in Tokyo
+			loc:      time.FixedZone("JST", 9*3600),
+			expected: "week-01",
+		},
 		{
 			name:     "minute window with different timezone converts to UTC",
 			window:   WindowMinute,
@@ -159,7 +445,7 @@ func TestWindowSize_BucketID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			result := tt.window.BucketID(tt.input)
+			result := tt.window.BucketID(tt.input, tt.loc)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -185,6 +471,6 @@ func TestWindowSize_BucketID_InvalidWindow(t *testing.T) {
 
 	// BucketID calls Duration() which will panic on invalid window
 	assert.Panics(t, func() {
-		invalidWindow.BucketID(testTime)
+		invalidWindow.BucketID(testTime, nil)
 	}, "BucketID should panic on invalid WindowSize")
 }
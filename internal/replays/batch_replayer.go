@@ -0,0 +1,244 @@
+package replays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/events"
+	"log-analytics/internal/ingestors"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/loggers"
+	"log-analytics/internal/stores"
+)
+
+// progressEvery is the number of completed batches between two progress reports and checkpoints.
+const progressEvery = 100
+
+// ValidateReplayID returns an error when replayID is not 1 to 64 letters, digits, underscores and hyphens
+// starting with a letter or digit, so a replay cannot write outside replays/{replayID}.
+func ValidateReplayID(replayID string) error {
+	if !models.IsKeySegmentID(replayID) {
+		return fmt.Errorf("replay ID must be 1 to 64 letters, digits, underscores and hyphens starting with a letter or digit: %q", replayID)
+	}
+	return nil
+}
+
+// ReplayRequest selects the windows a replay rebuilds: the windows of the customer starting within [From, To).
+type ReplayRequest struct {
+	CustomerID string
+	From       time.Time
+	To         time.Time
+}
+
+// ReplayReport summarizes a replay run.
+type ReplayReport struct {
+	TotalBatches    int // stored batches of the customer
+	ResumedBatches  int // completed by an earlier run of the same replay, not read again
+	ReplayedBatches int
+	UpdatedWindows  int // window aggregate results written to the replay output
+}
+
+// BatchReplayer rebuilds window aggregate results from the raw batches, e.g. after a normalization fix or a
+// window size change. The results are written to a separate output, never to the live aggregate results.
+//
+// Batches are summarized concurrently and applied to their windows like partial insights, so a batch
+// already applied to a window is skipped. Progress is checkpointed as the ID of the last batch of the
+// completed prefix; a rerun of the same replay resumes after it and re-applies the few batches that
+// completed out of order, which the skip turns into no-ops.
+//
+//go:generate mockgen -source=batch_replayer.go -destination=./mocks/batch_replayer_mock.go -package=mocks
+type BatchReplayer interface {
+	Replay(ctx context.Context, request ReplayRequest) (*ReplayReport, error)
+}
+
+type batchReplayer struct {
+	replayID              string
+	logBatchStore         stores.LogBatchStore
+	batchSummarizer       ingestors.BatchSummarizer
+	aggregateRolluper     aggregators.WindowAggregateRolluper
+	outputStore           stores.AggregateResultStore
+	replayCheckpointStore stores.ReplayCheckpointStore
+	concurrency           int
+
+	// windowLocks serializes the read-modify-write of each output window across workers
+	windowLocks sync.Map
+
+	logger loggers.Logger
+}
+
+// NewBatchReplayer creates the BatchReplayer of replayID, writing the rebuilt results to outputStore with
+// at most concurrency batches in flight.
+func NewBatchReplayer(replayID string, logBatchStore stores.LogBatchStore, batchSummarizer ingestors.BatchSummarizer, aggregateRolluper aggregators.WindowAggregateRolluper,
+	outputStore stores.AggregateResultStore, replayCheckpointStore stores.ReplayCheckpointStore, concurrency int, logger loggers.Logger) BatchReplayer {
+	return &batchReplayer{
+		replayID:              replayID,
+		logBatchStore:         logBatchStore,
+		batchSummarizer:       batchSummarizer,
+		aggregateRolluper:     aggregateRolluper,
+		outputStore:           outputStore,
+		replayCheckpointStore: replayCheckpointStore,
+		concurrency:           max(concurrency, 1),
+		logger:                logger,
+	}
+}
+
+type batchResult struct {
+	index   int
+	windows int
+	err     error
+}
+
+func (r *batchReplayer) Replay(ctx context.Context, request ReplayRequest) (*ReplayReport, error) {
+	checkpoint, err := r.replayCheckpointStore.Get(ctx, r.replayID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		checkpoint = &models.ReplayCheckpoint{ReplayID: r.replayID, CustomerID: request.CustomerID, From: request.From, To: request.To}
+	} else if checkpoint.CustomerID != request.CustomerID || !checkpoint.From.Equal(request.From) || !checkpoint.To.Equal(request.To) {
+		return nil, fmt.Errorf("replay %s was started for customer %s from %s to %s", r.replayID, checkpoint.CustomerID,
+			checkpoint.From.Format(time.RFC3339), checkpoint.To.Format(time.RFC3339))
+	}
+
+	batchIDs, err := r.logBatchStore.ListBatchIDs(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	// Batch IDs are sorted, so the completed ones are a prefix
+	pending := batchIDs
+	if checkpoint.CompletedUpTo != "" {
+		pending = batchIDs[sort.Search(len(batchIDs), func(i int) bool { return batchIDs[i] > checkpoint.CompletedUpTo }):]
+	}
+	report := &ReplayReport{TotalBatches: len(batchIDs), ResumedBatches: len(batchIDs) - len(pending)}
+	r.logger.Info().Msgf("replaying %d of %d batches of customer %s", len(pending), len(batchIDs), request.CustomerID)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for range r.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				windows, err := r.replayBatch(ctx, request, pending[index])
+				results <- batchResult{index: index, windows: windows, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for index := range pending {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Only this goroutine tracks completion, so checkpoints are written in order
+	var errs []error
+	completed := make([]bool, len(pending))
+	next, saved := 0, 0
+	for result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			cancel()
+			continue
+		}
+		report.ReplayedBatches++
+		report.UpdatedWindows += result.windows
+		completed[result.index] = true
+		for next < len(pending) && completed[next] {
+			next++
+		}
+		if next-saved >= progressEvery {
+			if err := r.saveCheckpoint(ctx, checkpoint, pending[next-1]); err != nil {
+				errs = append(errs, err)
+				cancel()
+				continue
+			}
+			saved = next
+			r.logger.Info().Msgf("replayed %d of %d batches", report.ResumedBatches+next, report.TotalBatches)
+		}
+	}
+
+	if next > saved {
+		// The run context may be cancelled already, the completed prefix is still recorded
+		if err := r.saveCheckpoint(context.WithoutCancel(ctx), checkpoint, pending[next-1]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// replayBatch applies the windows of one batch within the requested range and returns how many it updated.
+func (r *batchReplayer) replayBatch(ctx context.Context, request ReplayRequest, batchID string) (int, error) {
+	logBatch, err := r.logBatchStore.Get(ctx, request.CustomerID, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay batch %s: %w", batchID, err)
+	}
+
+	updated := 0
+	for _, batchSummary := range r.batchSummarizer.Summarize(logBatch) {
+		for windowKey, windowAggregates := range batchSummary.ByWindowStart {
+			windowStart, err := time.Parse(time.RFC3339, windowKey)
+			if err != nil {
+				return updated, fmt.Errorf("failed to replay batch %s: %w", batchID, err)
+			}
+			if windowStart.Before(request.From) || !windowStart.Before(request.To) {
+				continue
+			}
+			applied, err := r.applyWindow(ctx, events.NewPartialInsightEvent(batchSummary, windowStart, windowAggregates))
+			if err != nil {
+				return updated, fmt.Errorf("failed to replay batch %s: %w", batchID, err)
+			}
+			if applied {
+				updated++
+			}
+		}
+	}
+	return updated, nil
+}
+
+// applyWindow rolls the partial insight up into its output window, reporting false when the window
+// already applied the batch.
+func (r *batchReplayer) applyWindow(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) (bool, error) {
+	lockKey := string(partialInsightEvent.WindowSize) + "/" + partialInsightEvent.WindowStart.Format(time.RFC3339)
+	lock, _ := r.windowLocks.LoadOrStore(lockKey, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	aggregateResult, err := r.outputStore.Get(ctx, partialInsightEvent.CustomerID, partialInsightEvent.WindowStart, partialInsightEvent.WindowSize)
+	if err != nil {
+		return false, err
+	}
+	if err := r.aggregateRolluper.Rollup(aggregateResult, partialInsightEvent); err != nil {
+		if errors.Is(err, aggregators.ErrBatchAlreadyApplied) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := r.outputStore.Upsert(ctx, aggregateResult); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *batchReplayer) saveCheckpoint(ctx context.Context, checkpoint *models.ReplayCheckpoint, completedUpTo string) error {
+	checkpoint.CompletedUpTo = completedUpTo
+	checkpoint.UpdatedAt = time.Now().UTC()
+	return r.replayCheckpointStore.Put(ctx, checkpoint)
+}
@@ -0,0 +1,175 @@
+package replays_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"log-analytics/internal/aggregators"
+	"log-analytics/internal/ingestors"
+	"log-analytics/internal/models"
+	"log-analytics/internal/replays"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/stores"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replayFixture struct {
+	logBatchStore         stores.LogBatchStore
+	liveStore             stores.AggregateResultStore
+	outputStore           stores.AggregateResultStore
+	replayCheckpointStore stores.ReplayCheckpointStore
+}
+
+func newReplayFixture(t *testing.T) *replayFixture {
+	fileStorage, err := filestorages.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	return &replayFixture{
+		logBatchStore:         stores.NewLogBatchStore(fileStorage),
+		liveStore:             stores.NewAggregateResultStore(fileStorage),
+		outputStore:           stores.NewAggregateResultStoreWithDir(fileStorage, "replays/fix-ua/aggregate-results"),
+		replayCheckpointStore: stores.NewReplayCheckpointStore(fileStorage),
+	}
+}
+
+func (f *replayFixture) newReplayer(t *testing.T, concurrency int) replays.BatchReplayer {
+	pathNormalizer, err := ingestors.NewPathNormalizer(nil)
+	require.NoError(t, err)
+	batchSummarizer := ingestors.NewBatchSummarizer([]models.WindowSize{models.WindowMinute, models.WindowHour}, nil, pathNormalizer, models.CardinalityLimits{}, models.VisitorIdentityNone, nil, nil, nil)
+	return replays.NewBatchReplayer("fix-ua", f.logBatchStore, batchSummarizer, aggregators.NewAggregateRolluper(models.CardinalityLimits{}),
+		f.outputStore, f.replayCheckpointStore, concurrency, zerolog.Nop())
+}
+
+// putBatches stores the batches from..to-1 of one entry each, received a minute apart from 18:00.
+func (f *replayFixture) putBatches(t *testing.T, from int, to int) {
+	start := time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC)
+	for i := from; i < to; i++ {
+		require.NoError(t, f.logBatchStore.Put(context.Background(), &models.LogBatch{
+			BatchID:    fmt.Sprintf("batch-%03d", i),
+			CustomerID: "cus-axon",
+			IngestedAt: start.Add(time.Duration(i) * time.Minute),
+			Entries: []*models.LogEntry{
+				{ReceivedAt: start.Add(time.Duration(i)*time.Minute + 10*time.Second), Method: "get", Path: "/", UserAgent: "curl/8.0"},
+			},
+		}))
+	}
+}
+
+func TestReplay_RebuildsWindowsWithinRange(t *testing.T) {
+	t.Parallel()
+
+	fixture := newReplayFixture(t)
+	fixture.putBatches(t, 0, 10)
+
+	request := replays.ReplayRequest{
+		CustomerID: "cus-axon",
+		From:       time.Date(2025, 12, 28, 18, 2, 0, 0, time.UTC),
+		To:         time.Date(2025, 12, 28, 18, 5, 0, 0, time.UTC),
+	}
+	report, err := fixture.newReplayer(t, 4).Replay(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, &replays.ReplayReport{TotalBatches: 10, ReplayedBatches: 10, UpdatedWindows: 3}, report)
+
+	minutes, err := fixture.outputStore.ListRange(context.Background(), "cus-axon", models.WindowMinute, time.Time{}, request.To.Add(time.Hour), math.MaxInt)
+	require.NoError(t, err)
+	require.Len(t, minutes, 3)
+	assert.True(t, request.From.Equal(minutes[0].WindowStart))
+	assert.Equal(t, map[string]int64{"GET /": 1}, minutes[0].Dimensions[models.DimensionPath])
+
+	// The hour window starts before the range, so it is not rebuilt
+	hours, err := fixture.outputStore.ListRange(context.Background(), "cus-axon", models.WindowHour, time.Time{}, request.To.Add(time.Hour), math.MaxInt)
+	require.NoError(t, err)
+	assert.Empty(t, hours)
+
+	// The live results are left alone
+	live, err := fixture.liveStore.ListCustomerIDs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, live)
+
+	checkpoint, err := fixture.replayCheckpointStore.Get(context.Background(), "fix-ua")
+	require.NoError(t, err)
+	assert.Equal(t, "batch-009", checkpoint.CompletedUpTo)
+}
+
+func TestReplay_ResumesAfterCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	fixture := newReplayFixture(t)
+	fixture.putBatches(t, 0, 3)
+
+	request := replays.ReplayRequest{
+		CustomerID: "cus-axon",
+		From:       time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+		To:         time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC),
+	}
+	report, err := fixture.newReplayer(t, 1).Replay(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.ReplayedBatches)
+
+	// A rerun after more batches arrived only replays the new ones
+	fixture.putBatches(t, 3, 5)
+	report, err = fixture.newReplayer(t, 2).Replay(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, &replays.ReplayReport{TotalBatches: 5, ResumedBatches: 3, ReplayedBatches: 2, UpdatedWindows: 4}, report)
+
+	hour, err := fixture.outputStore.Get(context.Background(), "cus-axon", request.From, models.WindowHour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), hour.Dimensions[models.DimensionPath]["GET /"])
+}
+
+func TestReplay_RejectsDifferentRequestForSameReplay(t *testing.T) {
+	t.Parallel()
+
+	fixture := newReplayFixture(t)
+	fixture.putBatches(t, 0, 1)
+
+	request := replays.ReplayRequest{
+		CustomerID: "cus-axon",
+		From:       time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+		To:         time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC),
+	}
+	_, err := fixture.newReplayer(t, 1).Replay(context.Background(), request)
+	require.NoError(t, err)
+
+	request.To = request.To.Add(time.Hour)
+	_, err = fixture.newReplayer(t, 1).Replay(context.Background(), request)
+	assert.Error(t, err)
+}
+
+func TestValidateReplayID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		replayID string
+		wantErr  bool
+	}{
+		{name: "letters digits and separators", replayID: "fix-ua_2025"},
+		{name: "longest", replayID: strings.Repeat("a", 64)},
+		{name: "empty", replayID: "", wantErr: true},
+		{name: "too long", replayID: strings.Repeat("a", 65), wantErr: true},
+		{name: "current directory", replayID: ".", wantErr: true},
+		{name: "parent directory", replayID: "..", wantErr: true},
+		{name: "path separator", replayID: "fix/ua", wantErr: true},
+		{name: "leading hyphen", replayID: "-fix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := replays.ValidateReplayID(tt.replayID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: batch_replayer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=batch_replayer.go -destination=./mocks/batch_replayer_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	replays "log-analytics/internal/replays"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBatchReplayer is a mock of BatchReplayer interface.
+type MockBatchReplayer struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchReplayerMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchReplayerMockRecorder is the mock recorder for MockBatchReplayer.
+type MockBatchReplayerMockRecorder struct {
+	mock *MockBatchReplayer
+}
+
+// NewMockBatchReplayer creates a new mock instance.
+func NewMockBatchReplayer(ctrl *gomock.Controller) *MockBatchReplayer {
+	mock := &MockBatchReplayer{ctrl: ctrl}
+	mock.recorder = &MockBatchReplayerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchReplayer) EXPECT() *MockBatchReplayerMockRecorder {
+	return m.recorder
+}
+
+// Replay mocks base method.
+func (m *MockBatchReplayer) Replay(ctx context.Context, request replays.ReplayRequest) (*replays.ReplayReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, request)
+	ret0, _ := ret[0].(*replays.ReplayReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockBatchReplayerMockRecorder) Replay(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockBatchReplayer)(nil).Replay), ctx, request)
+}
@@ -5,7 +5,11 @@ type Config struct {
 	Server      ServerConfig      `mapstructure:"server" validate:"required"`
 	Log         LogConfig         `mapstructure:"log" validate:"required"`
 	FileStorage FileStorageConfig `mapstructure:"file_storage" validate:"required"`
+	Ingestion   IngestionConfig   `mapstructure:"ingestion" validate:"required"`
 	Aggregation AggregationConfig `mapstructure:"aggregation" validate:"required"`
+	Outbox      OutboxConfig      `mapstructure:"outbox" validate:"required"`
+	Stream      StreamConfig      `mapstructure:"stream" validate:"required"`
+	Customers   []CustomerConfig  `mapstructure:"customers" validate:"unique=ID,dive"`
 }
 
 // ServerConfig holds server-related configuration.
@@ -27,7 +31,89 @@ type FileStorageConfig struct {
 	RootDir string `mapstructure:"root_dir" validate:"required"`
 }
 
+// IngestionConfig holds ingestion configuration, the defaults for every customer.
+type IngestionConfig struct {
+	MaxBatchBytes      int  `mapstructure:"max_batch_bytes" validate:"required,min=1"`       // decoded request body size
+	MaxEntries         int  `mapstructure:"max_entries" validate:"required,min=1"`           // log entries per batch
+	MaxPathLength      int  `mapstructure:"max_path_length" validate:"required,min=1"`       // characters
+	MaxUserAgentLength int  `mapstructure:"max_user_agent_length" validate:"required,min=1"` // characters
+	MaxReceivedAtSkew  int  `mapstructure:"max_received_at_skew" validate:"min=0"`           // seconds from now, 0 disables the check
+	PartialAccept      bool `mapstructure:"partial_accept"`                                  // drop invalid entries instead of rejecting the batch
+}
+
+// CustomerConfig holds per-customer overrides of the global configuration.
+type CustomerConfig struct {
+	ID            string                   `mapstructure:"id" validate:"required"`
+	TimeZone      string                   `mapstructure:"time_zone" validate:"omitempty,timezone"` // IANA name, aligns day and week windows
+	Ingestion     *CustomerIngestionConfig `mapstructure:"ingestion"`
+	PathTemplates []string                 `mapstructure:"path_templates" validate:"omitempty,dive,startswith=/"` // e.g. /orders/{orderId}/items, matching paths count under the template
+	// PathUserAgentBreakdown also counts requests per path and user agent. Paths are capped like the path
+	// dimension and every path keeps aggregation.max_breakdown_user_agents_per_path user agents.
+	PathUserAgentBreakdown bool `mapstructure:"path_user_agent_breakdown"`
+	// GroupByAttributes names the entry attributes requests are counted by, e.g. region, each in the
+	// dimension attribute.{name} keeping aggregation.max_dimension_keys_per_window values.
+	GroupByAttributes []string `mapstructure:"group_by_attributes" validate:"max=10,unique,dive,required,max=64"`
+}
+
+// CustomerIngestionConfig overrides IngestionConfig for a single customer. Unset fields keep the global value.
+type CustomerIngestionConfig struct {
+	MaxBatchBytes      *int  `mapstructure:"max_batch_bytes" validate:"omitempty,min=1"`
+	MaxEntries         *int  `mapstructure:"max_entries" validate:"omitempty,min=1"`
+	MaxPathLength      *int  `mapstructure:"max_path_length" validate:"omitempty,min=1"`
+	MaxUserAgentLength *int  `mapstructure:"max_user_agent_length" validate:"omitempty,min=1"`
+	MaxReceivedAtSkew  *int  `mapstructure:"max_received_at_skew" validate:"omitempty,min=0"`
+	PartialAccept      *bool `mapstructure:"partial_accept"`
+}
+
 // AggregationConfig holds aggregation configuration.
 type AggregationConfig struct {
-	WindowSize string `mapstructure:"window_size" validate:"required,oneof=minute hour"`
+	WindowSize  string       `mapstructure:"window_size" validate:"required,window_size"`        // minute, 5m, 15m, hour, day, week or a custom size like 10m
+	WindowSizes []string     `mapstructure:"window_sizes" validate:"omitempty,dive,window_size"` // additional resolutions every batch is also aggregated into
+	Rollup      RollupConfig `mapstructure:"rollup"`
+	// AllowedLateness is how far behind the latest receivedAt of a customer an entry may arrive, in seconds.
+	// Windows ending before that watermark are finalized; later partial insights for them are late.
+	AllowedLateness  int `mapstructure:"allowed_lateness" validate:"min=0"`
+	FinalizeInterval int `mapstructure:"finalize_interval" validate:"required,min=1"` // seconds between finalization passes
+	// Distinct keys kept per window, the least requested ones are folded into "__other__". 0 disables the limit.
+	MaxPathsPerWindow      int `mapstructure:"max_paths_per_window" validate:"min=0"`
+	MaxUserAgentsPerWindow int `mapstructure:"max_user_agents_per_window" validate:"min=0"`
+	// Browser versions are roughly families times versions, so they get their own limit
+	MaxBrowserMajorVersionsPerWindow int `mapstructure:"max_browser_major_versions_per_window" validate:"min=0"`
+	// Paths keeping their own unique visitor sketch of up to 4KB, the others share the "__other__" sketch
+	MaxUniqueVisitorPathsPerWindow int `mapstructure:"max_unique_visitor_paths_per_window" validate:"min=0"`
+	// User agents kept per path by customers enabling the path by user agent breakdown
+	MaxBreakdownUserAgentsPerPath int `mapstructure:"max_breakdown_user_agents_per_path" validate:"min=0"`
+	// Dimensions registers custom dimensions, counted per window next to the built-in ones. Each of them keeps
+	// MaxDimensionKeysPerWindow keys, as do the built-in dimensions without a limit of their own.
+	Dimensions                []DimensionConfig `mapstructure:"dimensions" validate:"unique=Name,dive"`
+	MaxDimensionKeysPerWindow int               `mapstructure:"max_dimension_keys_per_window" validate:"min=0"`
+	// UniqueVisitorIdentity tells visitors apart for unique visitor counts: client_ip, user_agent_ip
+	// (client IP and user agent) or user_id. none disables the counts.
+	UniqueVisitorIdentity string `mapstructure:"unique_visitor_identity" validate:"required,oneof=none client_ip user_agent_ip user_id"`
+}
+
+// DimensionConfig registers a custom dimension counting every entry by one of its fields.
+type DimensionConfig struct {
+	Name  string `mapstructure:"name" validate:"required"`                                             // lower case letters, digits and underscores, not a built-in dimension
+	Field string `mapstructure:"field" validate:"required,oneof=method status host client_ip user_id"` // entries without the field are not counted
+}
+
+// RollupConfig holds the configuration of the job rolling window_size results up into coarser window sizes.
+type RollupConfig struct {
+	WindowSizes []string `mapstructure:"window_sizes" validate:"omitempty,dive,window_size"` // target sizes, multiples of window_size
+	Interval    int      `mapstructure:"interval" validate:"required,min=1"`                 // seconds between rollup passes
+}
+
+// OutboxConfig holds the transactional outbox configuration.
+type OutboxConfig struct {
+	RelayInterval  int `mapstructure:"relay_interval" validate:"required,min=1"`  // seconds between relay passes
+	PendingTimeout int `mapstructure:"pending_timeout" validate:"required,min=1"` // seconds before an unpublished batch is republished
+}
+
+// StreamConfig holds the partial insight stream configuration.
+type StreamConfig struct {
+	QueueType       string `mapstructure:"queue_type" validate:"required,oneof=memory durable"`
+	FsyncPolicy     string `mapstructure:"fsync_policy" validate:"required,oneof=always interval none"`
+	FsyncInterval   int    `mapstructure:"fsync_interval" validate:"required,min=1"`    // seconds, used by fsync_policy=interval
+	SegmentMaxBytes int64  `mapstructure:"segment_max_bytes" validate:"required,min=1"` // durable queue segment size before rolling
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"log-analytics/internal/models"
 	"log-analytics/internal/shared/validators"
 
 	"github.com/spf13/viper"
@@ -15,6 +16,30 @@ var LoadConfig = func(configPath string) (*Config, error) {
 	v.SetConfigFile(configPath)
 	v.SetConfigType("yaml")
 
+	// Defaults for optional sections
+	v.SetDefault("ingestion.max_batch_bytes", 2*1024*1024)
+	v.SetDefault("ingestion.max_entries", 10000)
+	v.SetDefault("ingestion.max_path_length", 2048)
+	v.SetDefault("ingestion.max_user_agent_length", 1024)
+	v.SetDefault("ingestion.max_received_at_skew", 0)
+	v.SetDefault("ingestion.partial_accept", false)
+	v.SetDefault("aggregation.allowed_lateness", 300)
+	v.SetDefault("aggregation.finalize_interval", 30)
+	v.SetDefault("aggregation.max_paths_per_window", 1000)
+	v.SetDefault("aggregation.max_user_agents_per_window", 200)
+	v.SetDefault("aggregation.max_browser_major_versions_per_window", 500)
+	v.SetDefault("aggregation.max_unique_visitor_paths_per_window", 50)
+	v.SetDefault("aggregation.max_breakdown_user_agents_per_path", 10)
+	v.SetDefault("aggregation.max_dimension_keys_per_window", 100)
+	v.SetDefault("aggregation.unique_visitor_identity", "client_ip")
+	v.SetDefault("aggregation.rollup.interval", 60)
+	v.SetDefault("outbox.relay_interval", 10)
+	v.SetDefault("outbox.pending_timeout", 30)
+	v.SetDefault("stream.queue_type", "memory")
+	v.SetDefault("stream.fsync_policy", "interval")
+	v.SetDefault("stream.fsync_interval", 1)
+	v.SetDefault("stream.segment_max_bytes", 64*1024*1024)
+
 	// Read from file
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file %q: %w", configPath, err)
@@ -28,6 +53,9 @@ var LoadConfig = func(configPath string) (*Config, error) {
 
 	// Validate config
 	validate := validators.New()
+	if err := validate.RegisterValidation("window_size", validateWindowSize); err != nil {
+		return nil, fmt.Errorf("failed to register window_size validation: %w", err)
+	}
 	if err := validate.Struct(&cfg); err != nil {
 		var validationErrors []string
 		if ve, ok := err.(validators.ValidationErrors); ok {
@@ -41,6 +69,12 @@ var LoadConfig = func(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// validateWindowSize accepts the window sizes supported by models.WindowSize.
+func validateWindowSize(fl validators.FieldLevel) bool {
+	_, err := models.NewWindowSizeFromString(fl.Field().String())
+	return err == nil
+}
+
 // formatValidationError formats a single validation error into a readable string.
 func formatValidationError(e validators.FieldError) string {
 	field := e.Field()
@@ -67,6 +101,8 @@ func formatValidationError(e validators.FieldError) string {
 		msg = fmt.Sprintf("%s (max=%s)", field, e.Param())
 	case "oneof":
 		msg = fmt.Sprintf("%s (oneof=%s)", field, e.Param())
+	case "unique":
+		msg = fmt.Sprintf("%s (unique=%s)", field, e.Param())
 	default:
 		msg = fmt.Sprintf("%s (%s)", field, tag)
 	}
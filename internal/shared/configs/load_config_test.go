@@ -42,6 +42,18 @@ aggregation:
 	assert.Equal(t, "debug", cfg.Log.Level)
 	assert.Equal(t, "./data", cfg.FileStorage.RootDir)
 	assert.Equal(t, "minute", cfg.Aggregation.WindowSize)
+	assert.Empty(t, cfg.Aggregation.Rollup.WindowSizes)
+	assert.Equal(t, 60, cfg.Aggregation.Rollup.Interval)
+	assert.Equal(t, 300, cfg.Aggregation.AllowedLateness)
+	assert.Equal(t, 30, cfg.Aggregation.FinalizeInterval)
+	assert.Equal(t, 1000, cfg.Aggregation.MaxPathsPerWindow)
+	assert.Equal(t, 200, cfg.Aggregation.MaxUserAgentsPerWindow)
+	assert.Equal(t, 500, cfg.Aggregation.MaxBrowserMajorVersionsPerWindow)
+	assert.Equal(t, 50, cfg.Aggregation.MaxUniqueVisitorPathsPerWindow)
+	assert.Equal(t, 10, cfg.Aggregation.MaxBreakdownUserAgentsPerPath)
+	assert.Equal(t, "client_ip", cfg.Aggregation.UniqueVisitorIdentity)
+	assert.Empty(t, cfg.Aggregation.Dimensions)
+	assert.Equal(t, 100, cfg.Aggregation.MaxDimensionKeysPerWindow)
 }
 
 func TestLoadConfig_MissingRequiredFields(t *testing.T) {
@@ -241,7 +253,7 @@ log:
 file_storage:
   root_dir: ./data
 aggregation:
-  window_size: day
+  window_size: 7m
 `
 
 	_, err = tmpfile.WriteString(invalidConfig)
@@ -252,8 +264,7 @@ aggregation:
 	assert.Nil(t, cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "validation failed")
-	assert.Contains(t, err.Error(), "aggregation.windowsize")
-	assert.Contains(t, err.Error(), "oneof")
+	assert.Contains(t, err.Error(), "aggregation.windowsize (window_size)")
 }
 
 func TestLoadConfig_ValidWindowSizeHour(t *testing.T) {
@@ -285,3 +296,308 @@ aggregation:
 	assert.NotNil(t, cfg)
 	assert.Equal(t, "hour", cfg.Aggregation.WindowSize)
 }
+
+func TestLoadConfig_ValidWindowSizes(t *testing.T) {
+	for _, windowSize := range []string{"5m", "15m", "day", "week", "10m", "6h"} {
+		t.Run(windowSize, func(t *testing.T) {
+			tmpfile, err := os.CreateTemp("", "test_config_*.yml")
+			require.NoError(t, err)
+			defer os.Remove(tmpfile.Name())
+
+			validConfig := `server:
+  port: 8080
+  read_header_timeout: 5
+  read_timeout: 10
+  write_timeout: 10
+  idle_timeout: 60
+log:
+  level: info
+file_storage:
+  root_dir: ./data
+aggregation:
+  window_size: ` + windowSize + `
+`
+
+			_, err = tmpfile.WriteString(validConfig)
+			require.NoError(t, err)
+			tmpfile.Close()
+
+			cfg, err := LoadConfig(tmpfile.Name())
+			require.NoError(t, err)
+			assert.Equal(t, windowSize, cfg.Aggregation.WindowSize)
+		})
+	}
+}
+
+func TestLoadConfig_AdditionalWindowSizes(t *testing.T) {
+	tests := []struct {
+		name        string
+		windowSizes string
+		expected    []string
+		wantErr     string
+	}{
+		{
+			name:        "valid",
+			windowSizes: "[hour, day, 15m]",
+			expected:    []string{"hour", "day", "15m"},
+		},
+		{
+			name:        "invalid",
+			windowSizes: "[hour, 7m]",
+			wantErr:     "aggregation.windowsizes[1] (window_size)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpfile, err := os.CreateTemp("", "test_config_*.yml")
+			require.NoError(t, err)
+			defer os.Remove(tmpfile.Name())
+
+			config := `server:
+  port: 8080
+  read_header_timeout: 5
+  read_timeout: 10
+  write_timeout: 10
+  idle_timeout: 60
+log:
+  level: info
+file_storage:
+  root_dir: ./data
+aggregation:
+  window_size: minute
+  window_sizes: ` + tt.windowSizes + `
+`
+
+			_, err = tmpfile.WriteString(config)
+			require.NoError(t, err)
+			tmpfile.Close()
+
+			cfg, err := LoadConfig(tmpfile.Name())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Aggregation.WindowSizes)
+		})
+	}
+}
+
+func TestLoadConfig_Dimensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		dimensions string
+		expected   []DimensionConfig
+		wantErr    string
+	}{
+		{
+			name: "valid",
+			dimensions: `
+    - name: host
+      field: host
+    - name: method
+      field: method
+`,
+			expected: []DimensionConfig{{Name: "host", Field: "host"}, {Name: "method", Field: "method"}},
+		},
+		{
+			name: "unknown field",
+			dimensions: `
+    - name: region
+      field: region
+`,
+			wantErr: "aggregation.dimensions[0].field (oneof",
+		},
+		{
+			name: "duplicate name",
+			dimensions: `
+    - name: host
+      field: host
+    - name: host
+      field: method
+`,
+			wantErr: "aggregation.dimensions (unique=Name)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpfile, err := os.CreateTemp("", "test_config_*.yml")
+			require.NoError(t, err)
+			defer os.Remove(tmpfile.Name())
+
+			config := `server:
+  port: 8080
+  read_header_timeout: 5
+  read_timeout: 10
+  write_timeout: 10
+  idle_timeout: 60
+log:
+  level: info
+file_storage:
+  root_dir: ./data
+aggregation:
+  window_size: minute
+  dimensions:` + tt.dimensions
+
+			_, err = tmpfile.WriteString(config)
+			require.NoError(t, err)
+			tmpfile.Close()
+
+			cfg, err := LoadConfig(tmpfile.Name())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Aggregation.Dimensions)
+		})
+	}
+}
+
+func TestLoadConfig_IngestionDefaultsAndCustomerOverrides(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "test_config_*.yml")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	validConfig := `server:
+  port: 8080
+  read_header_timeout: 5
+  read_timeout: 10
+  write_timeout: 10
+  idle_timeout: 60
+log:
+  level: debug
+file_storage:
+  root_dir: ./data
+aggregation:
+  window_size: minute
+ingestion:
+  max_entries: 500
+customers:
+  - id: cus-axon
+    time_zone: Asia/Tokyo
+    ingestion:
+      max_entries: 50000
+      max_received_at_skew: 3600
+      partial_accept: true
+    path_templates:
+      - /orders/{orderId}/items
+    path_user_agent_breakdown: true
+    group_by_attributes: [region, tenantPlan]
+  - id: cus-plain
+`
+
+	_, err = tmpfile.WriteString(validConfig)
+	require.NoError(t, err)
+	tmpfile.Close()
+
+	cfg, err := LoadConfig(tmpfile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, 2*1024*1024, cfg.Ingestion.MaxBatchBytes)
+	assert.Equal(t, 500, cfg.Ingestion.MaxEntries)
+	assert.Equal(t, 2048, cfg.Ingestion.MaxPathLength)
+	assert.Equal(t, 1024, cfg.Ingestion.MaxUserAgentLength)
+	assert.Equal(t, 0, cfg.Ingestion.MaxReceivedAtSkew)
+	assert.False(t, cfg.Ingestion.PartialAccept)
+
+	require.Len(t, cfg.Customers, 2)
+	assert.Equal(t, "cus-axon", cfg.Customers[0].ID)
+	assert.Equal(t, "Asia/Tokyo", cfg.Customers[0].TimeZone)
+	require.NotNil(t, cfg.Customers[0].Ingestion)
+	assert.Equal(t, 50000, *cfg.Customers[0].Ingestion.MaxEntries)
+	assert.Equal(t, 3600, *cfg.Customers[0].Ingestion.MaxReceivedAtSkew)
+	assert.True(t, *cfg.Customers[0].Ingestion.PartialAccept)
+	assert.Nil(t, cfg.Customers[0].Ingestion.MaxBatchBytes)
+	assert.Equal(t, []string{"/orders/{orderId}/items"}, cfg.Customers[0].PathTemplates)
+	assert.True(t, cfg.Customers[0].PathUserAgentBreakdown)
+	assert.Equal(t, []string{"region", "tenantPlan"}, cfg.Customers[0].GroupByAttributes)
+	assert.Nil(t, cfg.Customers[1].Ingestion)
+	assert.False(t, cfg.Customers[1].PathUserAgentBreakdown)
+}
+
+func TestLoadConfig_InvalidCustomerOverrides(t *testing.T) {
+	tests := []struct {
+		name          string
+		customers     string
+		expectedField string
+	}{
+		{
+			name: "duplicate customer id",
+			customers: `customers:
+  - id: cus-axon
+  - id: cus-axon
+`,
+			expectedField: "customers (unique=ID)",
+		},
+		{
+			name: "invalid override",
+			customers: `customers:
+  - id: cus-axon
+    ingestion:
+      max_entries: 0
+`,
+			expectedField: "maxentries (min=1)",
+		},
+		{
+			name: "unknown time zone",
+			customers: `customers:
+  - id: cus-axon
+    time_zone: Mars/Olympus_Mons
+`,
+			expectedField: "customers[0].timezone (timezone)",
+		},
+		{
+			name: "relative path template",
+			customers: `customers:
+  - id: cus-axon
+    path_templates:
+      - orders/{orderId}
+`,
+			expectedField: "startswith",
+		},
+		{
+			name: "duplicate group by attribute",
+			customers: `customers:
+  - id: cus-axon
+    group_by_attributes: [region, region]
+`,
+			expectedField: "customers[0].groupbyattributes (unique",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpfile, err := os.CreateTemp("", "test_config_*.yml")
+			require.NoError(t, err)
+			defer os.Remove(tmpfile.Name())
+
+			invalidConfig := `server:
+  port: 8080
+  read_header_timeout: 5
+  read_timeout: 10
+  write_timeout: 10
+  idle_timeout: 60
+log:
+  level: debug
+file_storage:
+  root_dir: ./data
+aggregation:
+  window_size: minute
+` + tt.customers
+
+			_, err = tmpfile.WriteString(invalidConfig)
+			require.NoError(t, err)
+			tmpfile.Close()
+
+			cfg, err := LoadConfig(tmpfile.Name())
+			assert.Nil(t, cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "validation failed")
+			assert.Contains(t, err.Error(), tt.expectedField)
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -25,10 +27,26 @@ type PutOptions struct {
 	AllowOverwrite bool
 }
 
+// ListOptions narrows ListRange to the keys within [From, To), like the start-after and max-keys options of
+// object stores.
+type ListOptions struct {
+	From  string // first key listed, "" lists from the first key under the prefix
+	To    string // keys from To on are not listed, "" lists up to the last key under the prefix
+	Limit int    // at most Limit keys are listed, 0 means unlimited
+}
+
 //go:generate mockgen -source=file_storage.go -destination=./mocks/file_storage_mock.go -package=mocks
 type FileStorage interface {
 	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*PutResult, error)
 	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys of all files stored under prefix, sorted lexicographically.
+	// A prefix that does not exist yields an empty result rather than ErrFileNotFound.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// ListRange returns the keys under prefix within opts, sorted lexicographically. Directories holding no key
+	// of the range are not read, and listing stops after opts.Limit keys, so its cost follows the range rather
+	// than everything stored under prefix.
+	ListRange(ctx context.Context, prefix string, opts ListOptions) ([]string, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type fileStorage struct {
@@ -76,6 +94,123 @@ func (s *fileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error
 	return file, nil
 }
 
+func (s *fileStorage) Delete(ctx context.Context, key string) error {
+	if err := s.validateKey(key); err != nil {
+		return err
+	}
+
+	fullPath := filepath.Join(s.dir, key)
+
+	if err := os.Remove(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			return ErrFileNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *fileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := s.validateKey(prefix); err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(s.dir, filepath.Clean(prefix))
+	keys := make([]string, 0)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Skip directories and in-flight temp files written by Put
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *fileStorage) ListRange(ctx context.Context, prefix string, opts ListOptions) ([]string, error) {
+	if err := s.validateKey(prefix); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	if _, err := s.listRange(ctx, filepath.ToSlash(filepath.Clean(prefix)), opts, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// listRange appends the keys of opts under the directory dir to keys, in key order. It reports whether
+// listing is done, because the limit or the end of the range was reached.
+func (s *fileStorage) listRange(ctx context.Context, dir string, opts ListOptions, keys *[]string) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(dir)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Keys below a directory extend its name with "/", so sorting on that visits them in key order
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir():
+			names = append(names, entry.Name()+"/")
+		case !strings.HasPrefix(entry.Name(), ".tmp-"): // in-flight temp files written by Put
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := dir + "/" + name
+		if opts.To != "" && key >= opts.To {
+			return true, nil
+		}
+		if subdir, ok := strings.CutSuffix(key, "/"); ok {
+			// Skip directories whose keys all sort before From
+			if opts.From != "" && key < opts.From && !strings.HasPrefix(opts.From, key) {
+				continue
+			}
+			done, err := s.listRange(ctx, subdir, opts, keys)
+			if done || err != nil {
+				return done, err
+			}
+			continue
+		}
+		if key < opts.From {
+			continue
+		}
+		*keys = append(*keys, key)
+		if opts.Limit > 0 && len(*keys) >= opts.Limit {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (s *fileStorage) validateKey(key string) error {
 	if key == "" {
 		return ErrInvalidKey
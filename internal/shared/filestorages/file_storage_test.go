@@ -223,6 +223,162 @@ func TestPut_LargeData(t *testing.T) {
 	assert.Equal(t, data, string(content))
 }
 
+func TestDelete_RemovesFile(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	key := "outbox/test.json"
+	_, err := storage.Put(ctx, key, strings.NewReader("data"), PutOptions{AllowOverwrite: false})
+	require.NoError(t, err)
+
+	err = storage.Delete(ctx, key)
+	require.NoError(t, err)
+
+	_, err = storage.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestDelete_FileNotFound(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	err := storage.Delete(ctx, "nonexistent.txt")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestList_ReturnsSortedKeysUnderPrefix(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	keys := []string{
+		"results/cus-b/002.json",
+		"results/cus-a/002.json",
+		"results/cus-a/001.json",
+		"other/001.json",
+	}
+	for _, key := range keys {
+		_, err := storage.Put(ctx, key, strings.NewReader("data"), PutOptions{AllowOverwrite: false})
+		require.NoError(t, err)
+	}
+
+	listed, err := storage.List(ctx, "results/cus-a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"results/cus-a/001.json", "results/cus-a/002.json"}, listed)
+
+	listed, err = storage.List(ctx, "results")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"results/cus-a/001.json", "results/cus-a/002.json", "results/cus-b/002.json"}, listed)
+}
+
+func TestList_SkipsTempFiles(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.Put(ctx, "results/001.json", strings.NewReader("data"), PutOptions{AllowOverwrite: false})
+	require.NoError(t, err)
+
+	// Simulate an in-flight Put that has not been published yet
+	tmpPath := filepath.Join(storage.(*fileStorage).dir, "results", ".tmp-12345")
+	require.NoError(t, os.WriteFile(tmpPath, []byte("partial"), 0644))
+
+	listed, err := storage.List(ctx, "results")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"results/001.json"}, listed)
+}
+
+func TestList_PrefixNotFound(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	listed, err := storage.List(ctx, "nonexistent")
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestListRange_ListsKeysWithinRange(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	keys := []string{
+		"results/20251227/0900.json",
+		"results/20251228/0900.json",
+		"results/20251228/1000.json",
+		"results/20251228/1100.json",
+		"results/20251229/0900.json",
+		"results/20251230/0900.json",
+	}
+	for _, key := range keys {
+		_, err := storage.Put(ctx, key, strings.NewReader("data"), PutOptions{AllowOverwrite: false})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected []string
+	}{
+		{name: "whole prefix", opts: ListOptions{}, expected: keys},
+		{
+			name:     "from inclusive, to exclusive",
+			opts:     ListOptions{From: "results/20251228/1000.json", To: "results/20251229/0900.json"},
+			expected: []string{"results/20251228/1000.json", "results/20251228/1100.json"},
+		},
+		{
+			name:     "bounds between keys",
+			opts:     ListOptions{From: "results/20251227/1000.json", To: "results/20251228/0930.json"},
+			expected: []string{"results/20251228/0900.json"},
+		},
+		{
+			name:     "limit",
+			opts:     ListOptions{From: "results/20251228/", Limit: 2},
+			expected: []string{"results/20251228/0900.json", "results/20251228/1000.json"},
+		},
+		{name: "empty range", opts: ListOptions{From: "results/20251231/"}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			listed, err := storage.ListRange(ctx, "results", tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, listed)
+		})
+	}
+}
+
+func TestListRange_PrefixNotFound(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+
+	listed, err := storage.ListRange(context.Background(), "nonexistent", ListOptions{Limit: 1})
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestList_InvalidPrefix(t *testing.T) {
+	t.Parallel()
+
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.List(ctx, "../outside")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
 func newTestStorage(t *testing.T) FileStorage {
 	tmpDir := t.TempDir()
 	storage, err := NewFileStorage(tmpDir)
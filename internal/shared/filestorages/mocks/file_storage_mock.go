@@ -42,6 +42,20 @@ func (m *MockFileStorage) EXPECT() *MockFileStorageMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockFileStorage) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFileStorageMockRecorder) Delete(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileStorage)(nil).Delete), ctx, key)
+}
+
 // Get mocks base method.
 func (m *MockFileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +71,36 @@ func (mr *MockFileStorageMockRecorder) Get(ctx, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFileStorage)(nil).Get), ctx, key)
 }
 
+// List mocks base method.
+func (m *MockFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefix)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFileStorageMockRecorder) List(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFileStorage)(nil).List), ctx, prefix)
+}
+
+// ListRange mocks base method.
+func (m *MockFileStorage) ListRange(ctx context.Context, prefix string, opts filestorages.ListOptions) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRange", ctx, prefix, opts)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRange indicates an expected call of ListRange.
+func (mr *MockFileStorageMockRecorder) ListRange(ctx, prefix, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRange", reflect.TypeOf((*MockFileStorage)(nil).ListRange), ctx, prefix, opts)
+}
+
 // Put mocks base method.
 func (m *MockFileStorage) Put(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,106 @@
+package sketches
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+)
+
+const (
+	// bloomFilterBits and bloomFilterHashes size every BloomFilter to 16KB. The false positive rate is
+	// (1-e^(-k*n/m))^k: about 2e-7 after 4096 values, 1e-3 after 8192 and 5e-2 after 16384.
+	bloomFilterBits   = 1 << 17
+	bloomFilterHashes = 20
+	bloomFilterWords  = bloomFilterBits / 64
+)
+
+// BloomFilter is a fixed size set membership filter (Bloom, 1970). Contains never misses an added value but
+// may report a value that was never added, at the false positive rate documented on bloomFilterBits.
+type BloomFilter struct {
+	words []uint64 // nil until the first value is added
+}
+
+func NewBloomFilter() *BloomFilter {
+	return &BloomFilter{}
+}
+
+// Add adds one value.
+func (b *BloomFilter) Add(value string) {
+	if b.words == nil {
+		b.words = make([]uint64, bloomFilterWords)
+	}
+	hash := bloomHash(value)
+	for i := uint64(0); i < bloomFilterHashes; i++ {
+		bit := bloomBit(hash, i)
+		b.words[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Contains reports whether value may have been added.
+func (b *BloomFilter) Contains(value string) bool {
+	if b.words == nil {
+		return false
+	}
+	hash := bloomHash(value)
+	for i := uint64(0); i < bloomFilterHashes; i++ {
+		bit := bloomBit(hash, i)
+		if b.words[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func bloomHash(value string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(value))
+	return hasher.Sum64()
+}
+
+// bloomBit returns the bit probed by the i-th hash of a value. Remixing the value hash per probe keeps the
+// probes independent, plain double hashing measurably raises the false positive rate at this size.
+func bloomBit(hash uint64, i uint64) uint64 {
+	return mix64(hash+i*0x9e3779b97f4a7c15) % bloomFilterBits
+}
+
+// bloomFilterJSON is the serialized form of a BloomFilter, its bits base64 encoded.
+type bloomFilterJSON struct {
+	Bits string `json:"bits,omitempty"`
+}
+
+func (b *BloomFilter) MarshalJSON() ([]byte, error) {
+	var wire bloomFilterJSON
+	if b.words != nil {
+		encoded := make([]byte, 0, bloomFilterWords*8)
+		for _, word := range b.words {
+			encoded = binary.BigEndian.AppendUint64(encoded, word)
+		}
+		wire.Bits = base64.StdEncoding.EncodeToString(encoded)
+	}
+	return json.Marshal(wire)
+}
+
+func (b *BloomFilter) UnmarshalJSON(data []byte) error {
+	var wire bloomFilterJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*b = BloomFilter{}
+	if wire.Bits == "" {
+		return nil
+	}
+	encoded, err := base64.StdEncoding.DecodeString(wire.Bits)
+	if err != nil {
+		return err
+	}
+	if len(encoded) != bloomFilterWords*8 {
+		return errors.New("invalid bloom filter bits")
+	}
+	b.words = make([]uint64, bloomFilterWords)
+	for i := range b.words {
+		b.words[i] = binary.BigEndian.Uint64(encoded[i*8:])
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sketches
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilter_Contains(t *testing.T) {
+	t.Parallel()
+
+	filter := NewBloomFilter()
+	assert.False(t, filter.Contains("batch-0"))
+
+	for i := range 4096 {
+		filter.Add(fmt.Sprintf("batch-%d", i))
+	}
+	for i := range 4096 {
+		assert.True(t, filter.Contains(fmt.Sprintf("batch-%d", i)), "no false negatives")
+	}
+
+	falsePositives := 0
+	for i := range 100000 {
+		if filter.Contains(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Zero(t, falsePositives, "false positive rate is about 2e-7 at 4096 values")
+}
+
+func TestBloomFilter_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	filter := NewBloomFilter()
+	filter.Add("batch-1")
+	filter.Add("batch-2")
+
+	data, err := json.Marshal(filter)
+	require.NoError(t, err)
+	var decoded BloomFilter
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, filter, &decoded)
+
+	data, err = json.Marshal(NewBloomFilter())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":"AAAA"}`), &decoded))
+}
@@ -0,0 +1,171 @@
+package sketches
+
+import (
+	"encoding/json"
+	"math"
+)
+
+const (
+	// relativeAccuracy bounds the error of every quantile relative to its exact value.
+	relativeAccuracy = 0.01
+	// maxBins bounds the size of a sketch. At 1% accuracy it covers a range of about 1e17 between the
+	// smallest and the largest value, beyond that the lowest bins are collapsed.
+	maxBins = 2048
+	// minIndexableValue is the smallest value given its own bin, smaller values are counted as zero.
+	minIndexableValue = 1e-6
+)
+
+var (
+	gamma    = (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	logGamma = math.Log(gamma)
+)
+
+// DDSketch is a mergeable quantile sketch with relative error guarantees (Masson et al., VLDB 2019).
+// Values are counted in logarithmically sized bins, so any quantile is within relativeAccuracy of the
+// exact value. Sketches merge by adding bin counts, which is exact and order independent, so windows and
+// batches can be merged in any grouping. Only non-negative values are supported; negative values count
+// as zero.
+type DDSketch struct {
+	zeroCount uint64
+	offset    int      // index of bins[0]
+	bins      []uint64 // contiguous bins, bins[i] counts the values of index offset+i
+	count     uint64
+}
+
+func NewDDSketch() *DDSketch {
+	return &DDSketch{}
+}
+
+// Add counts one value.
+func (s *DDSketch) Add(value float64) {
+	s.count++
+	if value < minIndexableValue {
+		s.zeroCount++
+		return
+	}
+	index := binIndex(value)
+	s.resize(s.extend(index, index))
+	s.bins[max(index, s.offset)-s.offset]++
+}
+
+// Merge adds the values counted by other.
+func (s *DDSketch) Merge(other *DDSketch) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	s.count += other.count
+	s.zeroCount += other.zeroCount
+	if len(other.bins) == 0 {
+		return
+	}
+	s.resize(s.extend(other.offset, other.offset+len(other.bins)-1))
+	for i, count := range other.bins {
+		s.bins[max(other.offset+i, s.offset)-s.offset] += count
+	}
+}
+
+// Count returns the number of values counted.
+func (s *DDSketch) Count() uint64 {
+	return s.count
+}
+
+// Quantile returns the value at quantile q, within 0 and 1: the value of rank q*(count-1), rounded down.
+// It returns 0 for an empty sketch.
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	q = min(max(q, 0), 1)
+	rank := q * float64(s.count-1)
+	cumulative := s.zeroCount
+	if float64(cumulative) > rank {
+		return 0
+	}
+	for i, count := range s.bins {
+		cumulative += count
+		if float64(cumulative) > rank {
+			return binValue(s.offset + i)
+		}
+	}
+	return binValue(s.offset + len(s.bins) - 1)
+}
+
+// Clone returns a deep copy of the sketch.
+func (s *DDSketch) Clone() *DDSketch {
+	clone := *s
+	clone.bins = append([]uint64(nil), s.bins...)
+	return &clone
+}
+
+// extend returns the bin range covering both the current bins and [lo, hi], keeping the highest maxBins.
+func (s *DDSketch) extend(lo int, hi int) (int, int) {
+	if len(s.bins) > 0 {
+		lo = min(lo, s.offset)
+		hi = max(hi, s.offset+len(s.bins)-1)
+	}
+	if hi-lo+1 > maxBins {
+		lo = hi - maxBins + 1
+	}
+	return lo, hi
+}
+
+// resize moves the bins to the range [lo, hi], folding the counts of bins below lo into lo.
+func (s *DDSketch) resize(lo int, hi int) {
+	if len(s.bins) > 0 && lo == s.offset && hi == s.offset+len(s.bins)-1 {
+		return
+	}
+	bins := make([]uint64, hi-lo+1)
+	for i, count := range s.bins {
+		bins[max(s.offset+i, lo)-lo] += count
+	}
+	s.offset = lo
+	s.bins = bins
+}
+
+func binIndex(value float64) int {
+	return int(math.Ceil(math.Log(value) / logGamma))
+}
+
+// binValue returns the value representing a bin, the one with the same relative distance to both bounds.
+func binValue(index int) float64 {
+	return 2 * math.Pow(gamma, float64(index)) / (gamma + 1)
+}
+
+// ddSketchJSON is the serialized form of a DDSketch. Only the bins between the lowest and highest non-empty
+// one are written, so a sketch of typical latencies takes a few hundred numbers at most.
+type ddSketchJSON struct {
+	ZeroCount uint64   `json:"zeroCount,omitempty"`
+	Offset    int      `json:"offset"`
+	Bins      []uint64 `json:"bins"`
+}
+
+func (s *DDSketch) MarshalJSON() ([]byte, error) {
+	lo, hi := 0, len(s.bins)
+	for lo < hi && s.bins[lo] == 0 {
+		lo++
+	}
+	for hi > lo && s.bins[hi-1] == 0 {
+		hi--
+	}
+	return json.Marshal(ddSketchJSON{
+		ZeroCount: s.zeroCount,
+		Offset:    s.offset + lo,
+		Bins:      s.bins[lo:hi],
+	})
+}
+
+func (s *DDSketch) UnmarshalJSON(data []byte) error {
+	var wire ddSketchJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*s = DDSketch{zeroCount: wire.ZeroCount, count: wire.ZeroCount}
+	if len(wire.Bins) > 0 {
+		s.offset = wire.Offset
+		s.bins = wire.Bins
+	}
+	for _, count := range s.bins {
+		s.count += count
+	}
+	return nil
+}
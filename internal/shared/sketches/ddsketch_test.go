@@ -0,0 +1,108 @@
+package sketches
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDSketch_Quantile_WithinRelativeAccuracy(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewSource(1))
+	values := make([]float64, 0, 10000)
+	sketch := NewDDSketch()
+	for range 10000 {
+		// Log-normal latencies around 50ms
+		value := math.Exp(random.NormFloat64() + math.Log(50))
+		values = append(values, value)
+		sketch.Add(value)
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0, 0.5, 0.9, 0.95, 0.99, 1} {
+		exact := values[int(q*float64(len(values)-1))]
+		assert.InEpsilon(t, exact, sketch.Quantile(q), relativeAccuracy+1e-9, "quantile %v", q)
+	}
+	assert.Equal(t, uint64(10000), sketch.Count())
+}
+
+func TestDDSketch_Merge_EqualsSingleSketch(t *testing.T) {
+	t.Parallel()
+
+	whole, first, second := NewDDSketch(), NewDDSketch(), NewDDSketch()
+	for i := range 1000 {
+		value := float64(i % 300)
+		whole.Add(value)
+		if i%2 == 0 {
+			first.Add(value)
+		} else {
+			second.Add(value)
+		}
+	}
+
+	merged := NewDDSketch()
+	merged.Merge(first)
+	merged.Merge(second)
+	merged.Merge(nil)
+	assert.Equal(t, whole.Count(), merged.Count())
+	for _, q := range []float64{0, 0.25, 0.5, 0.99, 1} {
+		assert.Equal(t, whole.Quantile(q), merged.Quantile(q), "quantile %v", q)
+	}
+	// Merging does not change the merged sketch
+	assert.Equal(t, uint64(500), first.Count())
+}
+
+func TestDDSketch_Quantile_ZerosAndEmpty(t *testing.T) {
+	t.Parallel()
+
+	sketch := NewDDSketch()
+	assert.Zero(t, sketch.Quantile(0.5))
+
+	sketch.Add(0)
+	sketch.Add(0)
+	sketch.Add(100)
+	assert.Zero(t, sketch.Quantile(0.5))
+	assert.InEpsilon(t, 100, sketch.Quantile(1), relativeAccuracy)
+}
+
+func TestDDSketch_CollapsesLowestBins(t *testing.T) {
+	t.Parallel()
+
+	sketch := NewDDSketch()
+	sketch.Add(1e-5)
+	sketch.Add(1e15)
+	assert.Len(t, sketch.bins, maxBins)
+	assert.Equal(t, uint64(2), sketch.Count())
+	assert.InEpsilon(t, 1e15, sketch.Quantile(1), relativeAccuracy)
+	// The smallest value moved up into the lowest kept bin
+	assert.Greater(t, sketch.Quantile(0), 1e-5)
+}
+
+func TestDDSketch_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sketch := NewDDSketch()
+	for _, value := range []float64{0, 1, 2, 2, 500} {
+		sketch.Add(value)
+	}
+
+	data, err := json.Marshal(sketch)
+	require.NoError(t, err)
+
+	var decoded DDSketch
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, sketch.Count(), decoded.Count())
+	for _, q := range []float64{0, 0.5, 0.75, 1} {
+		assert.Equal(t, sketch.Quantile(q), decoded.Quantile(q), "quantile %v", q)
+	}
+
+	clone := decoded.Clone()
+	clone.Add(1000)
+	assert.Equal(t, uint64(5), decoded.Count())
+}
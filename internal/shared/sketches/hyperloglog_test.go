@@ -0,0 +1,113 @@
+package sketches
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog_Estimate(t *testing.T) {
+	t.Parallel()
+
+	for _, distinct := range []int{0, 1, 100, 10000, 200000} {
+		t.Run(fmt.Sprintf("%d distinct", distinct), func(t *testing.T) {
+			t.Parallel()
+
+			sketch := NewHyperLogLog()
+			for i := range distinct {
+				sketch.Add(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+				// Duplicates do not count
+				sketch.Add(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+			}
+			if distinct == 0 {
+				assert.Zero(t, sketch.Estimate())
+				return
+			}
+			assert.InEpsilon(t, distinct, sketch.Estimate(), 0.05)
+		})
+	}
+}
+
+func TestHyperLogLog_Merge_EqualsUnion(t *testing.T) {
+	t.Parallel()
+
+	union, first, second := NewHyperLogLog(), NewHyperLogLog(), NewHyperLogLog()
+	for i := range 5000 {
+		value := fmt.Sprintf("user-%d", i)
+		union.Add(value)
+		// Overlapping halves
+		if i < 3000 {
+			first.Add(value)
+		}
+		if i >= 2000 {
+			second.Add(value)
+		}
+	}
+
+	merged := NewHyperLogLog()
+	merged.Merge(first)
+	merged.Merge(second)
+	merged.Merge(nil)
+	assert.Equal(t, union.Estimate(), merged.Estimate())
+	assert.InEpsilon(t, 5000, merged.Estimate(), 0.05)
+}
+
+func TestHyperLogLog_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, distinct := range []int{0, 10, 5000} {
+		sketch := NewHyperLogLog()
+		for i := range distinct {
+			sketch.Add(fmt.Sprintf("client-%d", i))
+		}
+
+		data, err := json.Marshal(sketch)
+		require.NoError(t, err)
+
+		var decoded HyperLogLog
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, sketch.Estimate(), decoded.Estimate(), "%d distinct", distinct)
+
+		clone := decoded.Clone()
+		clone.Add("another client")
+		assert.Equal(t, sketch.Estimate(), decoded.Estimate(), "clone is independent")
+	}
+}
+
+func TestHyperLogLog_UnmarshalJSON_InvalidRegisters(t *testing.T) {
+	t.Parallel()
+
+	var sketch HyperLogLog
+	assert.Error(t, json.Unmarshal([]byte(`{"registers":"not base64!"}`), &sketch))
+	assert.Error(t, json.Unmarshal([]byte(`{"registers":"eA=="}`), &sketch))
+}
+
+func TestHyperLogLog_Estimate_CachedUntilRegistersChange(t *testing.T) {
+	t.Parallel()
+
+	sketch := NewHyperLogLog()
+	for i := range 100 {
+		sketch.Add(fmt.Sprintf("client-%d", i))
+	}
+	data, err := json.Marshal(sketch)
+	require.NoError(t, err)
+
+	var decoded HyperLogLog
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	before := decoded.Estimate()
+
+	other := NewHyperLogLog()
+	for i := 100; i < 200; i++ {
+		other.Add(fmt.Sprintf("client-%d", i))
+	}
+	decoded.Merge(other)
+	assert.Greater(t, decoded.Estimate(), before)
+
+	decoded.Add("another client")
+	decoded.Add("yet another client")
+	decoded.Add("a third client")
+	assert.Equal(t, decoded.computeEstimate(), decoded.Estimate())
+}
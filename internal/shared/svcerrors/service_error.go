@@ -8,6 +8,7 @@ import (
 const (
 	categoryInvalidArgument  = "invalid_argument"
 	categoryResourceConflict = "resource_conflict"
+	categoryNotFound         = "not_found"
 	categoryInternal         = "internal"
 )
 
@@ -43,8 +44,17 @@ func NewInternalErrorUndefined(cause error) *ServiceError {
 	return NewInternalError(errorCodeInternalUndefined, cause)
 }
 
+// NewInternalErrorPanic creates a permanent internal ServiceError with code SYS_9000 for a recovered panic.
 func NewInternalErrorPanic(cause error) *ServiceError {
-	return NewInternalError(errorCodeInternalPanic, cause)
+	return NewPermanentInternalError(errorCodeInternalPanic, cause)
+}
+
+// NewPermanentInternalError creates a ServiceError with category internal that retrying cannot resolve,
+// e.g. a message that can never be applied.
+func NewPermanentInternalError(code string, cause error) *ServiceError {
+	svcErr := NewInternalError(code, cause)
+	svcErr.Permanent = true
+	return svcErr
 }
 
 // NewResourceConflictError creates a new ServiceError with category resource_conflict.
@@ -58,6 +68,17 @@ func NewResourceConflictError(code, message string, cause error) *ServiceError {
 	}
 }
 
+// NewNotFoundError creates a new ServiceError with category not_found.
+func NewNotFoundError(code, message string, cause error) *ServiceError {
+	return &ServiceError{
+		Category:       categoryNotFound,
+		Code:           code,
+		Message:        message,
+		Cause:          cause,
+		HttpStatusCode: 404,
+	}
+}
+
 func AsServiceError(err error) (*ServiceError, bool) {
 	var svcErr *ServiceError
 	if errors.As(err, &svcErr) {
@@ -69,11 +90,12 @@ func AsServiceError(err error) (*ServiceError, bool) {
 // ServiceError represents a service-level error with category, code, message, and cause.
 // It implements the error interface and supports error wrapping.
 type ServiceError struct {
-	Category       string // invalid_argument or internal
+	Category       string // invalid_argument, resource_conflict, not_found or internal
 	Code           string // service-owned stable code (e.g. LOGS_1000)
 	Message        string // client-safe, human-readable
 	Cause          error  // wrapped underlying error
 	HttpStatusCode int    // HTTP status code
+	Permanent      bool   // retrying cannot succeed, only meaningful for internal errors
 }
 
 // Error implements the error interface.
@@ -99,3 +121,9 @@ func As(err error) (*ServiceError, bool) {
 func (e *ServiceError) IsInternalError() bool {
 	return e.Category == categoryInternal
 }
+
+// IsRetryable reports whether retrying the failed operation may succeed: internal errors that are not
+// permanent, e.g. a storage outage. Client errors fail the same way on every attempt.
+func (e *ServiceError) IsRetryable() bool {
+	return e.IsInternalError() && !e.Permanent
+}
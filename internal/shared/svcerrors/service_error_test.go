@@ -34,6 +34,12 @@ func TestAsServiceError(t *testing.T) {
 			wantErr: NewInvalidArgumentError("LOGS_1000", "validation failed", nil),
 			wantOk:  true,
 		},
+		{
+			name:    "direct not found ServiceError",
+			err:     NewNotFoundError("LOGS_1001", "not found", nil),
+			wantErr: NewNotFoundError("LOGS_1001", "not found", nil),
+			wantOk:  true,
+		},
 		{
 			name:    "wrapped ServiceError",
 			err:     fmt.Errorf("wrap: %w", NewInternalError("LOGS_2000", nil)),
@@ -59,3 +65,22 @@ func TestAsServiceError(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceError_IsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ServiceError
+		want bool
+	}{
+		{name: "internal", err: NewInternalError("LOGS_2000", nil), want: true},
+		{name: "permanent internal", err: NewPermanentInternalError("LOGS_2001", nil), want: false},
+		{name: "panic", err: NewInternalErrorPanic(errors.New("x")), want: false},
+		{name: "invalid argument", err: NewInvalidArgumentError("LOGS_1000", "validation failed", nil), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.IsRetryable())
+		})
+	}
+}
@@ -13,6 +13,9 @@ type ValidationErrors = validator.ValidationErrors
 // FieldError is a type alias for validator.FieldError.
 type FieldError = validator.FieldError
 
+// FieldLevel is a type alias for validator.FieldLevel, passed to custom validations.
+type FieldLevel = validator.FieldLevel
+
 // New creates a new validator instance.
 func New() *Validate {
 	return validator.New()
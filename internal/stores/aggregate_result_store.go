@@ -6,35 +6,110 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"log-analytics/internal/models"
 	"log-analytics/internal/shared/filestorages"
 )
 
+// AggregateResultStore keeps one aggregate result per customer, window size and window start:
+//   - aggregate-results/{customerID}/{windowSize}/{windowStartDay}/{windowStart}.json
+//
+// Window sizes are kept apart because different sizes can share a window start (e.g. minute and 5m at 18:05).
+// Results are grouped by the UTC day of their window start, so listing a range only reads the days it spans.
+// Earlier versions kept minute and hour results directly under aggregate-results/{customerID}/{windowStart}.json,
+// and then without the day under aggregate-results/{customerID}/{windowSize}/{windowStart}.json;
+// MigrateLegacyKeys moves both to the current layout.
+//
 //go:generate mockgen -source=aggregate_result_store.go -destination=./mocks/aggregate_result_store_mock.go -package=mocks
 type AggregateResultStore interface {
 	Upsert(ctx context.Context, aggregateResult *models.WindowAggregateResult) error
+	// Update reads the result of a window (an empty one if none is stored), applies update to it and stores it
+	// when update reports a change. Updates and upserts of the same window are serialized within the process,
+	// so a read-modify-write never overwrites a concurrent one. It returns the result as stored afterwards.
+	Update(ctx context.Context, customerID string, windowStart time.Time, windowSize models.WindowSize, update func(aggregateResult *models.WindowAggregateResult) (bool, error)) (*models.WindowAggregateResult, error)
 	Get(ctx context.Context, customerID string, windowStart time.Time, windowSize models.WindowSize) (*models.WindowAggregateResult, error)
+	// ListRange returns the stored aggregate results of windowSize whose window start falls within
+	// [from, to), ordered by window start. At most limit results are returned, and only the keys of the range
+	// are listed.
+	ListRange(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error)
+	// ListCustomerIDs returns the sorted IDs of the customers with at least one stored aggregate result.
+	ListCustomerIDs(ctx context.Context) ([]string, error)
+	// MigrateLegacyKeys moves every result stored in the legacy layout to its current key and returns how
+	// many were moved. A result already present under its current key wins, so an interrupted migration
+	// can simply run again.
+	MigrateLegacyKeys(ctx context.Context) (int, error)
 }
 
+// windowLockStripes is the number of locks the windows of a store are spread over
+const windowLockStripes = 256
+
 type aggregateResultStore struct {
 	fileStorage filestorages.FileStorage
 	dir         string
+
+	windowLocks [windowLockStripes]sync.Mutex
 }
 
 func NewAggregateResultStore(fileStorage filestorages.FileStorage) AggregateResultStore {
-	return &aggregateResultStore{fileStorage: fileStorage, dir: "aggregate-results"}
+	return NewAggregateResultStoreWithDir(fileStorage, "aggregate-results")
+}
+
+// NewAggregateResultStoreWithDir creates an AggregateResultStore keeping its results under dir instead of
+// aggregate-results, e.g. to rebuild aggregates next to the live ones.
+func NewAggregateResultStoreWithDir(fileStorage filestorages.FileStorage, dir string) AggregateResultStore {
+	return &aggregateResultStore{fileStorage: fileStorage, dir: dir}
 }
 
 func (s *aggregateResultStore) Upsert(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+	key := s.getKey(aggregateResult.CustomerID, aggregateResult.WindowStart, aggregateResult.WindowSize)
+	windowLock := s.windowLock(key)
+	windowLock.Lock()
+	defer windowLock.Unlock()
+
+	return s.put(ctx, key, aggregateResult)
+}
+
+func (s *aggregateResultStore) Update(ctx context.Context, customerID string, windowStart time.Time, windowSize models.WindowSize, update func(aggregateResult *models.WindowAggregateResult) (bool, error)) (*models.WindowAggregateResult, error) {
+	key := s.getKey(customerID, windowStart, windowSize)
+	windowLock := s.windowLock(key)
+	windowLock.Lock()
+	defer windowLock.Unlock()
+
+	aggregateResult, err := s.get(ctx, key, customerID, windowStart, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := update(aggregateResult)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := s.put(ctx, key, aggregateResult); err != nil {
+			return nil, err
+		}
+	}
+	return aggregateResult, nil
+}
+
+// windowLock returns the lock serializing the writes of the window stored under key.
+func (s *aggregateResultStore) windowLock(key string) *sync.Mutex {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	return &s.windowLocks[hasher.Sum32()%windowLockStripes]
+}
+
+func (s *aggregateResultStore) put(ctx context.Context, key string, aggregateResult *models.WindowAggregateResult) error {
 	jsonData, err := json.Marshal(aggregateResult)
 	if err != nil {
 		return fmt.Errorf("failed to marshal aggregate result: %w", err)
 	}
 	reader := bytes.NewReader(jsonData)
-	key := s.getKey(aggregateResult.CustomerID, aggregateResult.WindowStart, aggregateResult.WindowSize)
 	_, err = s.fileStorage.Put(ctx, key, reader, filestorages.PutOptions{AllowOverwrite: true})
 	if err != nil {
 		return fmt.Errorf("failed to put aggregate result: %w", err)
@@ -43,7 +118,10 @@ func (s *aggregateResultStore) Upsert(ctx context.Context, aggregateResult *mode
 }
 
 func (s *aggregateResultStore) Get(ctx context.Context, customerID string, windowStart time.Time, windowSize models.WindowSize) (*models.WindowAggregateResult, error) {
-	key := s.getKey(customerID, windowStart, windowSize)
+	return s.get(ctx, s.getKey(customerID, windowStart, windowSize), customerID, windowStart, windowSize)
+}
+
+func (s *aggregateResultStore) get(ctx context.Context, key string, customerID string, windowStart time.Time, windowSize models.WindowSize) (*models.WindowAggregateResult, error) {
 	readCloser, err := s.fileStorage.Get(ctx, key)
 	if err != nil {
 		if errors.Is(err, filestorages.ErrFileNotFound) {
@@ -52,6 +130,107 @@ func (s *aggregateResultStore) Get(ctx context.Context, customerID string, windo
 		return nil, fmt.Errorf("failed to get aggregate result: %w", err)
 	}
 
+	return s.readAggregateResult(readCloser)
+}
+
+func (s *aggregateResultStore) ListRange(ctx context.Context, customerID string, windowSize models.WindowSize, from time.Time, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+	if !from.Before(to) || limit <= 0 {
+		return []*models.WindowAggregateResult{}, nil
+	}
+	// Keys of one window size sort chronologically, so the first limit keys of the range are the earliest windows
+	keys, err := s.fileStorage.ListRange(ctx, fmt.Sprintf("%s/%s/%s", s.dir, customerID, windowSize), filestorages.ListOptions{
+		From:  s.keyAtOrAfter(customerID, from, windowSize),
+		To:    s.keyAtOrAfter(customerID, to, windowSize),
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregate results: %w", err)
+	}
+
+	results := make([]*models.WindowAggregateResult, 0, len(keys))
+	for _, key := range keys {
+		if _, err := windowSize.ParseWindowStart(strings.TrimSuffix(path.Base(key), ".json")); err != nil {
+			// Not an aggregate result
+			continue
+		}
+
+		readCloser, err := s.fileStorage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get aggregate result: %w", err)
+		}
+		aggregateResult, err := s.readAggregateResult(readCloser)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, aggregateResult)
+	}
+	return results, nil
+}
+
+func (s *aggregateResultStore) ListCustomerIDs(ctx context.Context) ([]string, error) {
+	keys, err := s.fileStorage.List(ctx, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregate results: %w", err)
+	}
+
+	// Keys are sorted, so the keys of one customer are adjacent
+	customerIDs := make([]string, 0)
+	for _, key := range keys {
+		parts := strings.Split(strings.TrimPrefix(key, s.dir+"/"), "/")
+		if len(parts) != 4 {
+			continue
+		}
+		if n := len(customerIDs); n == 0 || customerIDs[n-1] != parts[0] {
+			customerIDs = append(customerIDs, parts[0])
+		}
+	}
+	return customerIDs, nil
+}
+
+func (s *aggregateResultStore) MigrateLegacyKeys(ctx context.Context) (int, error) {
+	keys, err := s.fileStorage.List(ctx, s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list aggregate results: %w", err)
+	}
+
+	migrated := 0
+	for _, key := range keys {
+		// Legacy keys lack the window size and day levels, {customerID}/{windowStart}.json, or the day level,
+		// {customerID}/{windowSize}/{windowStart}.json
+		if levels := len(strings.Split(strings.TrimPrefix(key, s.dir+"/"), "/")); levels != 2 && levels != 3 {
+			continue
+		}
+		readCloser, err := s.fileStorage.Get(ctx, key)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to get legacy aggregate result: %w", err)
+		}
+		data, err := io.ReadAll(readCloser)
+		_ = readCloser.Close()
+		if err != nil {
+			return migrated, fmt.Errorf("failed to read legacy aggregate result: %w", err)
+		}
+		var aggregateResult models.WindowAggregateResult
+		if err := json.Unmarshal(data, &aggregateResult); err != nil {
+			return migrated, fmt.Errorf("failed to unmarshal legacy aggregate result %s: %w", key, err)
+		}
+		if _, err := models.NewWindowSizeFromString(string(aggregateResult.WindowSize)); err != nil {
+			return migrated, fmt.Errorf("invalid window size of legacy aggregate result %s: %w", key, err)
+		}
+
+		newKey := s.getKey(aggregateResult.CustomerID, aggregateResult.WindowStart, aggregateResult.WindowSize)
+		_, err = s.fileStorage.Put(ctx, newKey, bytes.NewReader(data), filestorages.PutOptions{AllowOverwrite: false})
+		if err != nil && !errors.Is(err, filestorages.ErrFileAlreadyExists) {
+			return migrated, fmt.Errorf("failed to put migrated aggregate result: %w", err)
+		}
+		if err := s.fileStorage.Delete(ctx, key); err != nil && !errors.Is(err, filestorages.ErrFileNotFound) {
+			return migrated, fmt.Errorf("failed to delete legacy aggregate result: %w", err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+func (s *aggregateResultStore) readAggregateResult(readCloser io.ReadCloser) (*models.WindowAggregateResult, error) {
 	defer readCloser.Close()
 	data, err := io.ReadAll(readCloser)
 	if err != nil {
@@ -66,5 +245,16 @@ func (s *aggregateResultStore) Get(ctx context.Context, customerID string, windo
 
 func (s *aggregateResultStore) getKey(customerID string, windowStart time.Time, windowSize models.WindowSize) string {
 	utcTime := windowSize.FormatWindowStart(windowStart)
-	return fmt.Sprintf("%s/%s/%s.json", s.dir, customerID, utcTime)
+	// Formatted window starts begin with their UTC day, e.g. 20251228 of 20251228T1803Z
+	return fmt.Sprintf("%s/%s/%s/%s/%s.json", s.dir, customerID, windowSize, utcTime[:len("20060102")], utcTime)
+}
+
+// keyAtOrAfter returns the first key a window of windowSize starting at t or later can have. Keys hold the
+// truncated window start, so when t falls within the key of an earlier start the bound sorts right after it.
+func (s *aggregateResultStore) keyAtOrAfter(customerID string, t time.Time, windowSize models.WindowSize) string {
+	key := s.getKey(customerID, t, windowSize)
+	if start, err := windowSize.ParseWindowStart(windowSize.FormatWindowStart(t)); err == nil && start.Before(t) {
+		return key + "\x00"
+	}
+	return key
 }
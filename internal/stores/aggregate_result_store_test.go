@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
@@ -45,17 +46,21 @@ func TestAggregateResultStore_Upsert_Success(t *testing.T) {
 		CustomerID:  "cus-axon",
 		WindowStart: windowStart,
 		WindowSize:  models.WindowMinute,
-		RequestsByPath: map[string]int64{
-			"GET /":      4000,
-			"GET /about": 4000,
-		},
-		RequestsByUserAgent: map[string]int64{
-			"Chrome":  4000,
-			"Firefox": 4000,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {
+					"GET /":      4000,
+					"GET /about": 4000,
+				},
+				models.DimensionUserAgent: {
+					"Chrome":  4000,
+					"Firefox": 4000,
+				},
+			},
 		},
 	}
 
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 	expectedJSON, _ := json.Marshal(aggregateResult)
 
 	mockFileStorage.EXPECT().
@@ -86,15 +91,19 @@ func TestAggregateResultStore_Upsert_PutError(t *testing.T) {
 		CustomerID:  "cus-axon",
 		WindowStart: windowStart,
 		WindowSize:  models.WindowMinute,
-		RequestsByPath: map[string]int64{
-			"GET /": 4000,
-		},
-		RequestsByUserAgent: map[string]int64{
-			"Chrome": 4000,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {
+					"GET /": 4000,
+				},
+				models.DimensionUserAgent: {
+					"Chrome": 4000,
+				},
+			},
 		},
 	}
 
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 	putError := errors.New("storage error")
 
 	mockFileStorage.EXPECT().
@@ -122,17 +131,21 @@ func TestAggregateResultStore_Get_Success(t *testing.T) {
 		CustomerID:  "cus-axon",
 		WindowStart: windowStart,
 		WindowSize:  models.WindowMinute,
-		RequestsByPath: map[string]int64{
-			"GET /":      4000,
-			"GET /about": 4000,
-		},
-		RequestsByUserAgent: map[string]int64{
-			"Chrome":  4000,
-			"Firefox": 4000,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {
+					"GET /":      4000,
+					"GET /about": 4000,
+				},
+				models.DimensionUserAgent: {
+					"Chrome":  4000,
+					"Firefox": 4000,
+				},
+			},
 		},
 	}
 
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 	jsonData, _ := json.Marshal(expectedResult)
 	readCloser := io.NopCloser(bytes.NewReader(jsonData))
 
@@ -145,8 +158,8 @@ func TestAggregateResultStore_Get_Success(t *testing.T) {
 	assert.Equal(t, expectedResult.CustomerID, result.CustomerID)
 	assert.Equal(t, expectedResult.WindowStart, result.WindowStart)
 	assert.Equal(t, expectedResult.WindowSize, result.WindowSize)
-	assert.Equal(t, expectedResult.RequestsByPath, result.RequestsByPath)
-	assert.Equal(t, expectedResult.RequestsByUserAgent, result.RequestsByUserAgent)
+	assert.Equal(t, expectedResult.Dimensions[models.DimensionPath], result.Dimensions[models.DimensionPath])
+	assert.Equal(t, expectedResult.Dimensions[models.DimensionUserAgent], result.Dimensions[models.DimensionUserAgent])
 }
 
 func TestAggregateResultStore_Get_FileNotFound(t *testing.T) {
@@ -160,7 +173,7 @@ func TestAggregateResultStore_Get_FileNotFound(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 
 	mockFileStorage.EXPECT().
 		Get(ctx, expectedKey).
@@ -172,10 +185,8 @@ func TestAggregateResultStore_Get_FileNotFound(t *testing.T) {
 	assert.Equal(t, "cus-axon", result.CustomerID)
 	assert.Equal(t, windowStart, result.WindowStart)
 	assert.Equal(t, models.WindowMinute, result.WindowSize)
-	assert.NotNil(t, result.RequestsByPath)
-	assert.NotNil(t, result.RequestsByUserAgent)
-	assert.Empty(t, result.RequestsByPath)
-	assert.Empty(t, result.RequestsByUserAgent)
+	assert.NotNil(t, result.Dimensions)
+	assert.Empty(t, result.Dimensions)
 }
 
 func TestAggregateResultStore_Get_StorageError(t *testing.T) {
@@ -189,7 +200,7 @@ func TestAggregateResultStore_Get_StorageError(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 	storageError := errors.New("storage error")
 
 	mockFileStorage.EXPECT().
@@ -214,7 +225,7 @@ func TestAggregateResultStore_Get_ReadError(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 
 	// Create a ReadCloser that will fail on Read
 	readCloser := io.NopCloser(&errorReader{err: errors.New("read error")})
@@ -241,7 +252,7 @@ func TestAggregateResultStore_Get_UnmarshalError(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 
 	// Invalid JSON
 	invalidJSON := []byte(`{"invalid": json}`)
@@ -280,21 +291,21 @@ func TestAggregateResultStore_GetKey_Formatting(t *testing.T) {
 			customerID:  "cus-axon",
 			windowStart: time.Date(2025, 12, 28, 18, 3, 45, 0, time.UTC),
 			windowSize:  models.WindowMinute,
-			expectedKey: "aggregate-results/cus-axon/20251228T1803Z.json",
+			expectedKey: "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json",
 		},
 		{
 			name:        "hour window",
 			customerID:  "cus-axon",
 			windowStart: time.Date(2025, 12, 28, 18, 30, 0, 0, time.UTC),
 			windowSize:  models.WindowHour,
-			expectedKey: "aggregate-results/cus-axon/20251228T18Z.json",
+			expectedKey: "aggregate-results/cus-axon/hour/20251228/20251228T18Z.json",
 		},
 		{
 			name:        "different customer",
 			customerID:  "cus-other",
 			windowStart: time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC),
 			windowSize:  models.WindowMinute,
-			expectedKey: "aggregate-results/cus-other/20251228T1803Z.json",
+			expectedKey: "aggregate-results/cus-other/minute/20251228/20251228T1803Z.json",
 		},
 	}
 
@@ -306,11 +317,15 @@ func TestAggregateResultStore_GetKey_Formatting(t *testing.T) {
 				CustomerID:  tt.customerID,
 				WindowStart: tt.windowStart,
 				WindowSize:  tt.windowSize,
-				RequestsByPath: map[string]int64{
-					"GET /": 1000,
-				},
-				RequestsByUserAgent: map[string]int64{
-					"Chrome": 1000,
+				WindowAggregates: models.WindowAggregates{
+					Dimensions: models.Dimensions{
+						models.DimensionPath: {
+							"GET /": 1000,
+						},
+						models.DimensionUserAgent: {
+							"Chrome": 1000,
+						},
+					},
 				},
 			}
 
@@ -335,17 +350,21 @@ func TestAggregateResultStore_Get_ClosesReadCloser(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 
 	expectedResult := &models.WindowAggregateResult{
 		CustomerID:  "cus-axon",
 		WindowStart: windowStart,
 		WindowSize:  models.WindowMinute,
-		RequestsByPath: map[string]int64{
-			"GET /": 4000,
-		},
-		RequestsByUserAgent: map[string]int64{
-			"Chrome": 4000,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {
+					"GET /": 4000,
+				},
+				models.DimensionUserAgent: {
+					"Chrome": 4000,
+				},
+			},
 		},
 	}
 
@@ -397,15 +416,19 @@ func TestAggregateResultStore_Upsert_HourWindow(t *testing.T) {
 		CustomerID:  "cus-axon",
 		WindowStart: windowStart,
 		WindowSize:  models.WindowHour,
-		RequestsByPath: map[string]int64{
-			"GET /": 10000,
-		},
-		RequestsByUserAgent: map[string]int64{
-			"Chrome": 10000,
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath: {
+					"GET /": 10000,
+				},
+				models.DimensionUserAgent: {
+					"Chrome": 10000,
+				},
+			},
 		},
 	}
 
-	expectedKey := "aggregate-results/cus-axon/20251228T18Z.json"
+	expectedKey := "aggregate-results/cus-axon/hour/20251228/20251228T18Z.json"
 
 	mockFileStorage.EXPECT().
 		Put(ctx, expectedKey, gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
@@ -426,7 +449,7 @@ func TestAggregateResultStore_Get_InvalidJSONStructure(t *testing.T) {
 
 	ctx := context.Background()
 	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
-	expectedKey := "aggregate-results/cus-axon/20251228T1803Z.json"
+	expectedKey := "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json"
 
 	// Valid JSON but wrong structure (missing required fields)
 	invalidJSON := []byte(`{"customerId": "cus-axon"}`)
@@ -444,3 +467,258 @@ func TestAggregateResultStore_Get_InvalidJSONStructure(t *testing.T) {
 	// The unmarshal will succeed but WindowStart will be zero time
 	assert.True(t, result.WindowStart.IsZero())
 }
+
+func TestAggregateResultStore_ListRange_FiltersByRange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewAggregateResultStore(mockFileStorage)
+
+	ctx := context.Background()
+	from := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 18, 5, 0, 0, time.UTC)
+
+	// Only the keys of the range are listed
+	mockFileStorage.EXPECT().
+		ListRange(ctx, "aggregate-results/cus-axon/minute", filestorages.ListOptions{
+			From:  "aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json",
+			To:    "aggregate-results/cus-axon/minute/20251228/20251228T1805Z.json",
+			Limit: 10,
+		}).
+		Return([]string{
+			"aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json",
+			"aggregate-results/cus-axon/minute/20251228/20251228T1804Z.json",
+		}, nil)
+
+	for _, minute := range []int{3, 4} {
+		windowStart := time.Date(2025, 12, 28, 18, minute, 0, 0, time.UTC)
+		stored := models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)
+		stored.Counts(models.DimensionPath)["GET /"] = int64(minute)
+		jsonData, _ := json.Marshal(stored)
+		mockFileStorage.EXPECT().
+			Get(ctx, "aggregate-results/cus-axon/minute/20251228/"+models.WindowMinute.FormatWindowStart(windowStart)+".json").
+			Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+	}
+
+	results, err := store.ListRange(ctx, "cus-axon", models.WindowMinute, from, to, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, int64(3), results[0].Dimensions[models.DimensionPath]["GET /"])
+	assert.Equal(t, int64(4), results[1].Dimensions[models.DimensionPath]["GET /"])
+}
+
+func TestAggregateResultStore_ListRange_RespectsLimit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewAggregateResultStore(mockFileStorage)
+
+	ctx := context.Background()
+	from := time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)
+
+	mockFileStorage.EXPECT().
+		ListRange(ctx, "aggregate-results/cus-axon/hour", filestorages.ListOptions{
+			From:  "aggregate-results/cus-axon/hour/20251228/20251228T00Z.json",
+			To:    "aggregate-results/cus-axon/hour/20251229/20251229T00Z.json",
+			Limit: 1,
+		}).
+		Return([]string{"aggregate-results/cus-axon/hour/20251228/20251228T17Z.json"}, nil)
+
+	windowStart := time.Date(2025, 12, 28, 17, 0, 0, 0, time.UTC)
+	jsonData, _ := json.Marshal(models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowHour))
+	mockFileStorage.EXPECT().
+		Get(ctx, "aggregate-results/cus-axon/hour/20251228/20251228T17Z.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+
+	results, err := store.ListRange(ctx, "cus-axon", models.WindowHour, from, to, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, windowStart.Equal(results[0].WindowStart))
+}
+
+func TestAggregateResultStore_ListRange_ListError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewAggregateResultStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		ListRange(ctx, "aggregate-results/cus-axon/minute", gomock.Any()).
+		Return(nil, errors.New("storage error"))
+
+	results, err := store.ListRange(ctx, "cus-axon", models.WindowMinute, time.Time{}, time.Now(), 10)
+	assert.Nil(t, results)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list aggregate results")
+}
+
+func TestAggregateResultStore_ListRange_UnalignedBoundsAcrossDays(t *testing.T) {
+	t.Parallel()
+
+	fileStorage, err := filestorages.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	store := NewAggregateResultStore(fileStorage)
+
+	ctx := context.Background()
+	for _, windowStart := range []time.Time{
+		time.Date(2025, 12, 27, 23, 58, 0, 0, time.UTC),
+		time.Date(2025, 12, 27, 23, 59, 0, 0, time.UTC),
+		time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 12, 28, 0, 1, 0, 0, time.UTC),
+		time.Date(2025, 12, 28, 0, 2, 0, 0, time.UTC),
+	} {
+		require.NoError(t, store.Upsert(ctx, models.NewEmptyWindowAggregateResult("cus-axon", windowStart, models.WindowMinute)))
+	}
+
+	// The window containing from starts before it and is left out, the one containing to starts before it
+	from := time.Date(2025, 12, 27, 23, 58, 30, 0, time.UTC)
+	to := time.Date(2025, 12, 28, 0, 1, 30, 0, time.UTC)
+	results, err := store.ListRange(ctx, "cus-axon", models.WindowMinute, from, to, 10)
+	require.NoError(t, err)
+	starts := make([]string, 0, len(results))
+	for _, result := range results {
+		starts = append(starts, models.WindowMinute.FormatWindowStart(result.WindowStart))
+	}
+	assert.Equal(t, []string{"20251227T2359Z", "20251228T0000Z", "20251228T0001Z"}, starts)
+
+	results, err = store.ListRange(ctx, "cus-axon", models.WindowMinute, from, to, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "20251227T2359Z", models.WindowMinute.FormatWindowStart(results[0].WindowStart))
+}
+
+func TestAggregateResultStore_ListCustomerIDs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewAggregateResultStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		List(ctx, "aggregate-results").
+		Return([]string{
+			"aggregate-results/cus-axon/hour/20251228/20251228T18Z.json",
+			"aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json",
+			"aggregate-results/cus-bolt/minute/20251228/20251228T1803Z.json",
+			"aggregate-results/stray.json",
+		}, nil)
+
+	customerIDs, err := store.ListCustomerIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cus-axon", "cus-bolt"}, customerIDs)
+}
+
+func TestAggregateResultStore_MigrateLegacyKeys(t *testing.T) {
+	t.Parallel()
+
+	fileStorage, err := filestorages.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	store := NewAggregateResultStore(fileStorage)
+
+	ctx := context.Background()
+	minuteResult := models.NewEmptyWindowAggregateResult("cus-axon", time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC), models.WindowMinute)
+	minuteResult.Counts(models.DimensionPath)["GET /"] = 3
+	hourResult := models.NewEmptyWindowAggregateResult("cus-axon", time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC), models.WindowHour)
+	hourResult.Counts(models.DimensionPath)["GET /"] = 5
+	dayResult := models.NewEmptyWindowAggregateResult("cus-axon", time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC), models.WindowDay)
+	dayResult.Counts(models.DimensionPath)["GET /"] = 9
+	for key, result := range map[string]*models.WindowAggregateResult{
+		"aggregate-results/cus-axon/20251228T1803Z.json":     minuteResult,
+		"aggregate-results/cus-axon/20251228T18Z.json":       hourResult,
+		"aggregate-results/cus-axon/day/20251228T0000Z.json": dayResult,
+	} {
+		data, err := json.Marshal(result)
+		require.NoError(t, err)
+		_, err = fileStorage.Put(ctx, key, bytes.NewReader(data), filestorages.PutOptions{})
+		require.NoError(t, err)
+	}
+	// Already migrated before an interrupted run, the current result wins
+	current := models.NewEmptyWindowAggregateResult("cus-axon", hourResult.WindowStart, models.WindowHour)
+	current.Counts(models.DimensionPath)["GET /"] = 7
+	require.NoError(t, store.Upsert(ctx, current))
+
+	migrated, err := store.MigrateLegacyKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, migrated)
+
+	stored, err := store.Get(ctx, "cus-axon", minuteResult.WindowStart, models.WindowMinute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored.Dimensions[models.DimensionPath]["GET /"])
+	stored, err = store.Get(ctx, "cus-axon", hourResult.WindowStart, models.WindowHour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), stored.Dimensions[models.DimensionPath]["GET /"])
+	stored, err = store.Get(ctx, "cus-axon", dayResult.WindowStart, models.WindowDay)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), stored.Dimensions[models.DimensionPath]["GET /"])
+
+	keys, err := fileStorage.List(ctx, "aggregate-results")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"aggregate-results/cus-axon/day/20251228/20251228T0000Z.json",
+		"aggregate-results/cus-axon/hour/20251228/20251228T18Z.json",
+		"aggregate-results/cus-axon/minute/20251228/20251228T1803Z.json",
+	}, keys)
+
+	migrated, err = store.MigrateLegacyKeys(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, migrated)
+}
+
+func TestAggregateResultStore_Update_SerializesWindowWrites(t *testing.T) {
+	t.Parallel()
+
+	fileStorage, err := filestorages.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	store := NewAggregateResultStore(fileStorage)
+
+	ctx := context.Background()
+	windowStart := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := store.Update(ctx, "cus-axon", windowStart, models.WindowMinute, func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+				aggregateResult.Counts(models.DimensionPath)["GET /"]++
+				return true, nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	stored, err := store.Get(ctx, "cus-axon", windowStart, models.WindowMinute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), stored.Dimensions[models.DimensionPath]["GET /"], "no update is lost")
+
+	// An unchanged window is not written, an update error is returned as is
+	updated, err := store.Update(ctx, "cus-axon", windowStart, models.WindowMinute, func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+		aggregateResult.Counts(models.DimensionPath)["GET /"] = 0
+		return false, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), updated.Dimensions[models.DimensionPath]["GET /"])
+	_, err = store.Update(ctx, "cus-axon", windowStart, models.WindowMinute, func(aggregateResult *models.WindowAggregateResult) (bool, error) {
+		return true, assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	stored, err = store.Get(ctx, "cus-axon", windowStart, models.WindowMinute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), stored.Dimensions[models.DimensionPath]["GET /"])
+}
@@ -0,0 +1,58 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"log-analytics/internal/events"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// DeadLetter is a partial insight the consumer gave up on, together with the error that rejected it.
+type DeadLetter struct {
+	Event          *events.PartialInsightEvent `json:"event"`
+	ErrorCode      string                      `json:"errorCode"`
+	Error          string                      `json:"error"`
+	DeadLetteredAt time.Time                   `json:"deadLetteredAt"`
+}
+
+// DeadLetterStore keeps the partial insights that failed with a non-retryable error, so the consumer can
+// move past them without losing them, one file per window and batch:
+//   - dead-letters/{customerID}/{windowSize}/{windowStart}/{batchID}.json
+//
+// A redelivered dead letter overwrites the stored one.
+//
+//go:generate mockgen -source=dead_letter_store.go -destination=./mocks/dead_letter_store_mock.go -package=mocks
+type DeadLetterStore interface {
+	Put(ctx context.Context, deadLetter *DeadLetter) error
+}
+
+type deadLetterStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewDeadLetterStore(fileStorage filestorages.FileStorage) DeadLetterStore {
+	return &deadLetterStore{fileStorage: fileStorage, dir: "dead-letters"}
+}
+
+func (s *deadLetterStore) Put(ctx context.Context, deadLetter *DeadLetter) error {
+	jsonData, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getKey(deadLetter.Event), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put dead letter: %w", err)
+	}
+	return nil
+}
+
+func (s *deadLetterStore) getKey(partialInsightEvent *events.PartialInsightEvent) string {
+	windowSize := partialInsightEvent.WindowSize
+	return fmt.Sprintf("%s/%s/%s/%s/%s.json", s.dir, partialInsightEvent.CustomerID, windowSize,
+		windowSize.FormatWindowStart(partialInsightEvent.WindowStart), partialInsightEvent.BatchID)
+}
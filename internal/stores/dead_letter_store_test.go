@@ -0,0 +1,71 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLetterStore_Put(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewDeadLetterStore(mockFileStorage)
+
+	ctx := context.Background()
+	deadLetter := &DeadLetter{
+		Event: &events.PartialInsightEvent{
+			CustomerID:  "cus-axon",
+			BatchID:     "batch-1",
+			WindowStart: time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC),
+			WindowSize:  models.WindowMinute,
+		},
+		ErrorCode:      "AGG_9000",
+		Error:          "windowSize mismatch",
+		DeadLetteredAt: time.Date(2025, 12, 28, 18, 5, 0, 0, time.UTC),
+	}
+
+	mockFileStorage.EXPECT().
+		Put(ctx, "dead-letters/cus-axon/minute/20251228T1803Z/batch-1.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored DeadLetter
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, deadLetter, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.Put(ctx, deadLetter)
+	assert.NoError(t, err)
+}
+
+func TestDeadLetterStore_Put_Error(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewDeadLetterStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("storage error"))
+
+	err := store.Put(ctx, &DeadLetter{Event: &events.PartialInsightEvent{CustomerID: "cus-axon", WindowSize: models.WindowMinute}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to put dead letter")
+}
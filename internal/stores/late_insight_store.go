@@ -0,0 +1,102 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"log-analytics/internal/events"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// LateInsightStore keeps the partial insights that arrived for an already finalized window until the
+// reconciliation pass applies them, one file per window and batch:
+//   - late-insights/{customerID}/{windowSize}/{windowStart}/{batchID}.json
+//
+// A redelivered late insight overwrites the stored one.
+//
+//go:generate mockgen -source=late_insight_store.go -destination=./mocks/late_insight_store_mock.go -package=mocks
+type LateInsightStore interface {
+	Put(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error
+	// List returns every stored late insight, ordered by customer, window size, window start and batch ID.
+	List(ctx context.Context) ([]*events.PartialInsightEvent, error)
+	// Delete removes a late insight once it was applied. Deleting a missing late insight is not an error.
+	Delete(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error
+}
+
+type lateInsightStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewLateInsightStore(fileStorage filestorages.FileStorage) LateInsightStore {
+	return &lateInsightStore{fileStorage: fileStorage, dir: "late-insights"}
+}
+
+func (s *lateInsightStore) Put(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error {
+	jsonData, err := json.Marshal(partialInsightEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal late insight: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getKey(partialInsightEvent), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put late insight: %w", err)
+	}
+	return nil
+}
+
+func (s *lateInsightStore) List(ctx context.Context) ([]*events.PartialInsightEvent, error) {
+	keys, err := s.fileStorage.List(ctx, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list late insights: %w", err)
+	}
+
+	partialInsightEvents := make([]*events.PartialInsightEvent, 0, len(keys))
+	for _, key := range keys {
+		partialInsightEvent, err := s.getLateInsight(ctx, key)
+		if err != nil {
+			if errors.Is(err, filestorages.ErrFileNotFound) {
+				// Applied concurrently since listing
+				continue
+			}
+			return nil, err
+		}
+		partialInsightEvents = append(partialInsightEvents, partialInsightEvent)
+	}
+	return partialInsightEvents, nil
+}
+
+func (s *lateInsightStore) Delete(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error {
+	err := s.fileStorage.Delete(ctx, s.getKey(partialInsightEvent))
+	if err != nil && !errors.Is(err, filestorages.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete late insight: %w", err)
+	}
+	return nil
+}
+
+func (s *lateInsightStore) getLateInsight(ctx context.Context, key string) (*events.PartialInsightEvent, error) {
+	readCloser, err := s.fileStorage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read late insight: %w", err)
+	}
+	var partialInsightEvent events.PartialInsightEvent
+	if err := json.Unmarshal(data, &partialInsightEvent); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal late insight: %w", err)
+	}
+	return &partialInsightEvent, nil
+}
+
+func (s *lateInsightStore) getKey(partialInsightEvent *events.PartialInsightEvent) string {
+	windowSize := partialInsightEvent.WindowSize
+	return fmt.Sprintf("%s/%s/%s/%s/%s.json", s.dir, partialInsightEvent.CustomerID, windowSize,
+		windowSize.FormatWindowStart(partialInsightEvent.WindowStart), partialInsightEvent.BatchID)
+}
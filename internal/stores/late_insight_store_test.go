@@ -0,0 +1,138 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/events"
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLateInsightStore_Put_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLateInsightStore(mockFileStorage)
+
+	ctx := context.Background()
+	event := &events.PartialInsightEvent{
+		CustomerID:    "cus-axon",
+		BatchID:       "batch-1",
+		WindowStart:   time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC),
+		WindowSize:    models.WindowMinute,
+		MaxReceivedAt: time.Date(2025, 12, 28, 18, 3, 30, 0, time.UTC),
+		WindowAggregates: models.WindowAggregates{
+			Dimensions: models.Dimensions{
+				models.DimensionPath:      {"GET /": 1},
+				models.DimensionUserAgent: {"Chrome": 1},
+			},
+		},
+	}
+
+	mockFileStorage.EXPECT().
+		Put(ctx, "late-insights/cus-axon/minute/20251228T1803Z/batch-1.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored events.PartialInsightEvent
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, event, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.Put(ctx, event)
+	assert.NoError(t, err)
+}
+
+func TestLateInsightStore_List(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLateInsightStore(mockFileStorage)
+
+	ctx := context.Background()
+	jsonData, _ := json.Marshal(&events.PartialInsightEvent{CustomerID: "cus-axon", BatchID: "batch-1", WindowSize: models.WindowMinute})
+
+	mockFileStorage.EXPECT().
+		List(ctx, "late-insights").
+		Return([]string{"late-insights/cus-axon/minute/20251228T1803Z/batch-1.json", "late-insights/cus-axon/minute/20251228T1803Z/batch-2.json"}, nil)
+	mockFileStorage.EXPECT().
+		Get(ctx, "late-insights/cus-axon/minute/20251228T1803Z/batch-1.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+	// batch-2 was applied between List and Get
+	mockFileStorage.EXPECT().
+		Get(ctx, "late-insights/cus-axon/minute/20251228T1803Z/batch-2.json").
+		Return(nil, filestorages.ErrFileNotFound)
+
+	lateInsights, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, lateInsights, 1)
+	assert.Equal(t, "batch-1", lateInsights[0].BatchID)
+}
+
+func TestLateInsightStore_List_LegacyDimensions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLateInsightStore(mockFileStorage)
+
+	ctx := context.Background()
+	// Stored before the built-in dimensions moved into dimensions
+	jsonData := []byte(`{"customerId":"cus-axon","batchId":"batch-1","windowSize":"minute","requestsByPath":{"GET /":2},"requestsByUserAgent":{"Chrome":2}}`)
+
+	mockFileStorage.EXPECT().
+		List(ctx, "late-insights").
+		Return([]string{"late-insights/cus-axon/minute/20251228T1803Z/batch-1.json"}, nil)
+	mockFileStorage.EXPECT().
+		Get(ctx, "late-insights/cus-axon/minute/20251228T1803Z/batch-1.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+
+	lateInsights, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, lateInsights, 1)
+	assert.Equal(t, "batch-1", lateInsights[0].BatchID)
+	assert.Equal(t, models.Dimensions{
+		models.DimensionPath:      {"GET /": 2},
+		models.DimensionUserAgent: {"Chrome": 2},
+	}, lateInsights[0].Dimensions)
+}
+
+func TestLateInsightStore_Delete_IgnoresMissing(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLateInsightStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Delete(ctx, "late-insights/cus-axon/hour/20251228T18Z/batch-1.json").
+		Return(filestorages.ErrFileNotFound)
+
+	err := store.Delete(ctx, &events.PartialInsightEvent{
+		CustomerID:  "cus-axon",
+		BatchID:     "batch-1",
+		WindowStart: time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+		WindowSize:  models.WindowHour,
+	})
+	assert.NoError(t, err)
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"log-analytics/internal/models"
 	"log-analytics/internal/shared/filestorages"
@@ -13,6 +15,7 @@ import (
 
 var (
 	ErrLogBatchAlreadyExist = errors.New("log batch already exists")
+	ErrLogBatchNotFound     = errors.New("log batch not found")
 )
 
 // LogBatchStore simulates S3's atomic PUT operations for deduplication. When Put is called with
@@ -28,6 +31,11 @@ var (
 //go:generate mockgen -source=log_batch_store.go -destination=./mocks/log_batch_store_mock.go -package=mocks
 type LogBatchStore interface {
 	Put(ctx context.Context, logBatch *models.LogBatch) error
+	Get(ctx context.Context, customerID string, batchID string) (*models.LogBatch, error)
+	// Exists reports whether a batch has been stored, without reading its entries.
+	Exists(ctx context.Context, customerID string, batchID string) (bool, error)
+	// ListBatchIDs returns the sorted IDs of every stored batch of the customer.
+	ListBatchIDs(ctx context.Context, customerID string) ([]string, error)
 }
 
 type logBatchStore struct {
@@ -46,7 +54,7 @@ func (s *logBatchStore) Put(ctx context.Context, logBatch *models.LogBatch) erro
 	}
 	reader := bytes.NewReader(jsonData)
 
-	key := fmt.Sprintf("%s/%s/%s.json", s.dir, logBatch.CustomerID, logBatch.BatchID)
+	key := s.getKey(logBatch.CustomerID, logBatch.BatchID)
 
 	_, err = s.fileStorage.Put(ctx, key, reader, filestorages.PutOptions{AllowOverwrite: false})
 	if err != nil {
@@ -57,3 +65,58 @@ func (s *logBatchStore) Put(ctx context.Context, logBatch *models.LogBatch) erro
 	}
 	return nil
 }
+
+func (s *logBatchStore) Get(ctx context.Context, customerID string, batchID string) (*models.LogBatch, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(customerID, batchID))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return nil, ErrLogBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get log batch: %w", err)
+	}
+
+	defer readCloser.Close()
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log batch: %w", err)
+	}
+	var logBatch models.LogBatch
+	if err := json.Unmarshal(data, &logBatch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal log batch: %w", err)
+	}
+	return &logBatch, nil
+}
+
+func (s *logBatchStore) Exists(ctx context.Context, customerID string, batchID string) (bool, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(customerID, batchID))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get log batch: %w", err)
+	}
+	_ = readCloser.Close()
+	return true, nil
+}
+
+func (s *logBatchStore) ListBatchIDs(ctx context.Context, customerID string) ([]string, error) {
+	prefix := fmt.Sprintf("%s/%s", s.dir, customerID)
+	keys, err := s.fileStorage.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log batches: %w", err)
+	}
+
+	batchIDs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		batchID, ok := strings.CutSuffix(strings.TrimPrefix(key, prefix+"/"), ".json")
+		if !ok || strings.Contains(batchID, "/") {
+			continue
+		}
+		batchIDs = append(batchIDs, batchID)
+	}
+	return batchIDs, nil
+}
+
+func (s *logBatchStore) getKey(customerID string, batchID string) string {
+	return fmt.Sprintf("%s/%s/%s.json", s.dir, customerID, batchID)
+}
@@ -1,6 +1,7 @@
 package stores
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -337,3 +338,149 @@ func TestLogBatchStore_Put_JSONMarshaling(t *testing.T) {
 	err := store.Put(ctx, logBatch)
 	assert.NoError(t, err)
 }
+
+func TestLogBatchStore_Get_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLogBatchStore(mockFileStorage)
+
+	ctx := context.Background()
+	logBatch := &models.LogBatch{
+		BatchID:    "batch-123",
+		CustomerID: "cus-axon",
+		IngestedAt: time.Date(2025, 12, 28, 18, 3, 20, 0, time.UTC),
+		Entries: []*models.LogEntry{
+			{
+				ReceivedAt: time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC),
+				Method:     "GET",
+				Path:       "/",
+				UserAgent:  "Chrome",
+			},
+		},
+	}
+	jsonData, _ := json.Marshal(logBatch)
+
+	mockFileStorage.EXPECT().
+		Get(ctx, "raw-batches/cus-axon/batch-123.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+
+	result, err := store.Get(ctx, "cus-axon", "batch-123")
+	require.NoError(t, err)
+	assert.Equal(t, logBatch, result)
+}
+
+func TestLogBatchStore_Get_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLogBatchStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Get(ctx, "raw-batches/cus-axon/batch-123.json").
+		Return(nil, filestorages.ErrFileNotFound)
+
+	result, err := store.Get(ctx, "cus-axon", "batch-123")
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrLogBatchNotFound)
+}
+
+func TestLogBatchStore_Get_StorageError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLogBatchStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Get(ctx, "raw-batches/cus-axon/batch-123.json").
+		Return(nil, errors.New("storage error"))
+
+	result, err := store.Get(ctx, "cus-axon", "batch-123")
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get log batch")
+	assert.NotErrorIs(t, err, ErrLogBatchNotFound)
+}
+
+func TestLogBatchStore_Exists(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getResult io.ReadCloser
+		getError  error
+		expected  bool
+		wantErr   bool
+	}{
+		{
+			name:      "stored",
+			getResult: io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			expected:  true,
+		},
+		{
+			name:     "not stored",
+			getError: filestorages.ErrFileNotFound,
+			expected: false,
+		},
+		{
+			name:     "storage error",
+			getError: errors.New("storage error"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			store := NewLogBatchStore(mockFileStorage)
+
+			ctx := context.Background()
+			mockFileStorage.EXPECT().
+				Get(ctx, "raw-batches/cus-axon/batch-123.json").
+				Return(tt.getResult, tt.getError)
+
+			exists, err := store.Exists(ctx, "cus-axon", "batch-123")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, exists)
+		})
+	}
+}
+
+func TestLogBatchStore_ListBatchIDs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewLogBatchStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		List(ctx, "raw-batches/cus-axon").
+		Return([]string{"raw-batches/cus-axon/batch-1.json", "raw-batches/cus-axon/batch-2.json", "raw-batches/cus-axon/notes.txt"}, nil)
+
+	batchIDs, err := store.ListBatchIDs(ctx, "cus-axon")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"batch-1", "batch-2"}, batchIDs)
+}
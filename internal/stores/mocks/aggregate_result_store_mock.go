@@ -57,30 +57,76 @@ func (mr *MockAggregateResultStoreMockRecorder) Get(ctx, customerID, windowStart
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAggregateResultStore)(nil).Get), ctx, customerID, windowStart, windowSize)
 }
 
-// Insert mocks base method.
-func (m *MockAggregateResultStore) Insert(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+// ListCustomerIDs mocks base method.
+func (m *MockAggregateResultStore) ListCustomerIDs(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Insert", ctx, aggregateResult)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListCustomerIDs", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomerIDs indicates an expected call of ListCustomerIDs.
+func (mr *MockAggregateResultStoreMockRecorder) ListCustomerIDs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomerIDs", reflect.TypeOf((*MockAggregateResultStore)(nil).ListCustomerIDs), ctx)
+}
+
+// ListRange mocks base method.
+func (m *MockAggregateResultStore) ListRange(ctx context.Context, customerID string, windowSize models.WindowSize, from, to time.Time, limit int) ([]*models.WindowAggregateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRange", ctx, customerID, windowSize, from, to, limit)
+	ret0, _ := ret[0].([]*models.WindowAggregateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Insert indicates an expected call of Insert.
-func (mr *MockAggregateResultStoreMockRecorder) Insert(ctx, aggregateResult any) *gomock.Call {
+// ListRange indicates an expected call of ListRange.
+func (mr *MockAggregateResultStoreMockRecorder) ListRange(ctx, customerID, windowSize, from, to, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAggregateResultStore)(nil).Insert), ctx, aggregateResult)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRange", reflect.TypeOf((*MockAggregateResultStore)(nil).ListRange), ctx, customerID, windowSize, from, to, limit)
+}
+
+// MigrateLegacyKeys mocks base method.
+func (m *MockAggregateResultStore) MigrateLegacyKeys(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateLegacyKeys", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrateLegacyKeys indicates an expected call of MigrateLegacyKeys.
+func (mr *MockAggregateResultStoreMockRecorder) MigrateLegacyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateLegacyKeys", reflect.TypeOf((*MockAggregateResultStore)(nil).MigrateLegacyKeys), ctx)
 }
 
 // Update mocks base method.
-func (m *MockAggregateResultStore) Update(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+func (m *MockAggregateResultStore) Update(ctx context.Context, customerID string, windowStart time.Time, windowSize models.WindowSize, update func(*models.WindowAggregateResult) (bool, error)) (*models.WindowAggregateResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, aggregateResult)
+	ret := m.ctrl.Call(m, "Update", ctx, customerID, windowStart, windowSize, update)
+	ret0, _ := ret[0].(*models.WindowAggregateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockAggregateResultStoreMockRecorder) Update(ctx, customerID, windowStart, windowSize, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAggregateResultStore)(nil).Update), ctx, customerID, windowStart, windowSize, update)
+}
+
+// Upsert mocks base method.
+func (m *MockAggregateResultStore) Upsert(ctx context.Context, aggregateResult *models.WindowAggregateResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, aggregateResult)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockAggregateResultStoreMockRecorder) Update(ctx, aggregateResult any) *gomock.Call {
+// Upsert indicates an expected call of Upsert.
+func (mr *MockAggregateResultStoreMockRecorder) Upsert(ctx, aggregateResult any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAggregateResultStore)(nil).Update), ctx, aggregateResult)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockAggregateResultStore)(nil).Upsert), ctx, aggregateResult)
 }
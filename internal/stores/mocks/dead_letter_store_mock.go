@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dead_letter_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=dead_letter_store.go -destination=./mocks/dead_letter_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	stores "log-analytics/internal/stores"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterStore is a mock of DeadLetterStore interface.
+type MockDeadLetterStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLetterStoreMockRecorder is the mock recorder for MockDeadLetterStore.
+type MockDeadLetterStoreMockRecorder struct {
+	mock *MockDeadLetterStore
+}
+
+// NewMockDeadLetterStore creates a new mock instance.
+func NewMockDeadLetterStore(ctrl *gomock.Controller) *MockDeadLetterStore {
+	mock := &MockDeadLetterStore{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterStore) EXPECT() *MockDeadLetterStoreMockRecorder {
+	return m.recorder
+}
+
+// Put mocks base method.
+func (m *MockDeadLetterStore) Put(ctx context.Context, deadLetter *stores.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, deadLetter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockDeadLetterStoreMockRecorder) Put(ctx, deadLetter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockDeadLetterStore)(nil).Put), ctx, deadLetter)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: late_insight_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=late_insight_store.go -destination=./mocks/late_insight_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	events "log-analytics/internal/events"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLateInsightStore is a mock of LateInsightStore interface.
+type MockLateInsightStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLateInsightStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockLateInsightStoreMockRecorder is the mock recorder for MockLateInsightStore.
+type MockLateInsightStoreMockRecorder struct {
+	mock *MockLateInsightStore
+}
+
+// NewMockLateInsightStore creates a new mock instance.
+func NewMockLateInsightStore(ctrl *gomock.Controller) *MockLateInsightStore {
+	mock := &MockLateInsightStore{ctrl: ctrl}
+	mock.recorder = &MockLateInsightStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLateInsightStore) EXPECT() *MockLateInsightStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockLateInsightStore) Delete(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, partialInsightEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockLateInsightStoreMockRecorder) Delete(ctx, partialInsightEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLateInsightStore)(nil).Delete), ctx, partialInsightEvent)
+}
+
+// List mocks base method.
+func (m *MockLateInsightStore) List(ctx context.Context) ([]*events.PartialInsightEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*events.PartialInsightEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLateInsightStoreMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLateInsightStore)(nil).List), ctx)
+}
+
+// Put mocks base method.
+func (m *MockLateInsightStore) Put(ctx context.Context, partialInsightEvent *events.PartialInsightEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, partialInsightEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockLateInsightStoreMockRecorder) Put(ctx, partialInsightEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockLateInsightStore)(nil).Put), ctx, partialInsightEvent)
+}
@@ -41,6 +41,51 @@ func (m *MockLogBatchStore) EXPECT() *MockLogBatchStoreMockRecorder {
 	return m.recorder
 }
 
+// Exists mocks base method.
+func (m *MockLogBatchStore) Exists(ctx context.Context, customerID, batchID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, customerID, batchID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockLogBatchStoreMockRecorder) Exists(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockLogBatchStore)(nil).Exists), ctx, customerID, batchID)
+}
+
+// Get mocks base method.
+func (m *MockLogBatchStore) Get(ctx context.Context, customerID, batchID string) (*models.LogBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, customerID, batchID)
+	ret0, _ := ret[0].(*models.LogBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLogBatchStoreMockRecorder) Get(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLogBatchStore)(nil).Get), ctx, customerID, batchID)
+}
+
+// ListBatchIDs mocks base method.
+func (m *MockLogBatchStore) ListBatchIDs(ctx context.Context, customerID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBatchIDs", ctx, customerID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBatchIDs indicates an expected call of ListBatchIDs.
+func (mr *MockLogBatchStoreMockRecorder) ListBatchIDs(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBatchIDs", reflect.TypeOf((*MockLogBatchStore)(nil).ListBatchIDs), ctx, customerID)
+}
+
 // Put mocks base method.
 func (m *MockLogBatchStore) Put(ctx context.Context, logBatch *models.LogBatch) error {
 	m.ctrl.T.Helper()
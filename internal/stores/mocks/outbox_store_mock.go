@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=outbox_store.go -destination=./mocks/outbox_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	stores "log-analytics/internal/stores"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxStore is a mock of OutboxStore interface.
+type MockOutboxStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxStoreMockRecorder is the mock recorder for MockOutboxStore.
+type MockOutboxStoreMockRecorder struct {
+	mock *MockOutboxStore
+}
+
+// NewMockOutboxStore creates a new mock instance.
+func NewMockOutboxStore(ctrl *gomock.Controller) *MockOutboxStore {
+	mock := &MockOutboxStore{ctrl: ctrl}
+	mock.recorder = &MockOutboxStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxStore) EXPECT() *MockOutboxStoreMockRecorder {
+	return m.recorder
+}
+
+// ClearPending mocks base method.
+func (m *MockOutboxStore) ClearPending(ctx context.Context, customerID, batchID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearPending", ctx, customerID, batchID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearPending indicates an expected call of ClearPending.
+func (mr *MockOutboxStoreMockRecorder) ClearPending(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearPending", reflect.TypeOf((*MockOutboxStore)(nil).ClearPending), ctx, customerID, batchID)
+}
+
+// IsPublished mocks base method.
+func (m *MockOutboxStore) IsPublished(ctx context.Context, customerID, batchID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPublished", ctx, customerID, batchID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsPublished indicates an expected call of IsPublished.
+func (mr *MockOutboxStoreMockRecorder) IsPublished(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPublished", reflect.TypeOf((*MockOutboxStore)(nil).IsPublished), ctx, customerID, batchID)
+}
+
+// ListPending mocks base method.
+func (m *MockOutboxStore) ListPending(ctx context.Context) ([]*stores.OutboxRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", ctx)
+	ret0, _ := ret[0].([]*stores.OutboxRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockOutboxStoreMockRecorder) ListPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockOutboxStore)(nil).ListPending), ctx)
+}
+
+// MarkPending mocks base method.
+func (m *MockOutboxStore) MarkPending(ctx context.Context, customerID, batchID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPending", ctx, customerID, batchID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPending indicates an expected call of MarkPending.
+func (mr *MockOutboxStoreMockRecorder) MarkPending(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPending", reflect.TypeOf((*MockOutboxStore)(nil).MarkPending), ctx, customerID, batchID)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxStore) MarkPublished(ctx context.Context, customerID, batchID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, customerID, batchID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxStoreMockRecorder) MarkPublished(ctx, customerID, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxStore)(nil).MarkPublished), ctx, customerID, batchID)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rejected_entry_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=rejected_entry_store.go -destination=./mocks/rejected_entry_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRejectedEntryStore is a mock of RejectedEntryStore interface.
+type MockRejectedEntryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRejectedEntryStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockRejectedEntryStoreMockRecorder is the mock recorder for MockRejectedEntryStore.
+type MockRejectedEntryStoreMockRecorder struct {
+	mock *MockRejectedEntryStore
+}
+
+// NewMockRejectedEntryStore creates a new mock instance.
+func NewMockRejectedEntryStore(ctrl *gomock.Controller) *MockRejectedEntryStore {
+	mock := &MockRejectedEntryStore{ctrl: ctrl}
+	mock.recorder = &MockRejectedEntryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRejectedEntryStore) EXPECT() *MockRejectedEntryStoreMockRecorder {
+	return m.recorder
+}
+
+// Put mocks base method.
+func (m *MockRejectedEntryStore) Put(ctx context.Context, rejectedBatch *models.RejectedBatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, rejectedBatch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockRejectedEntryStoreMockRecorder) Put(ctx, rejectedBatch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRejectedEntryStore)(nil).Put), ctx, rejectedBatch)
+}
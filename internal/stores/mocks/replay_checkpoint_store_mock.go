@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: replay_checkpoint_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=replay_checkpoint_store.go -destination=./mocks/replay_checkpoint_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReplayCheckpointStore is a mock of ReplayCheckpointStore interface.
+type MockReplayCheckpointStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplayCheckpointStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockReplayCheckpointStoreMockRecorder is the mock recorder for MockReplayCheckpointStore.
+type MockReplayCheckpointStoreMockRecorder struct {
+	mock *MockReplayCheckpointStore
+}
+
+// NewMockReplayCheckpointStore creates a new mock instance.
+func NewMockReplayCheckpointStore(ctrl *gomock.Controller) *MockReplayCheckpointStore {
+	mock := &MockReplayCheckpointStore{ctrl: ctrl}
+	mock.recorder = &MockReplayCheckpointStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplayCheckpointStore) EXPECT() *MockReplayCheckpointStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockReplayCheckpointStore) Get(ctx context.Context, replayID string) (*models.ReplayCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, replayID)
+	ret0, _ := ret[0].(*models.ReplayCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockReplayCheckpointStoreMockRecorder) Get(ctx, replayID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReplayCheckpointStore)(nil).Get), ctx, replayID)
+}
+
+// Put mocks base method.
+func (m *MockReplayCheckpointStore) Put(ctx context.Context, checkpoint *models.ReplayCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockReplayCheckpointStoreMockRecorder) Put(ctx, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockReplayCheckpointStore)(nil).Put), ctx, checkpoint)
+}
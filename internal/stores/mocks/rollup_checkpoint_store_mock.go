@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rollup_checkpoint_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=rollup_checkpoint_store.go -destination=./mocks/rollup_checkpoint_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRollupCheckpointStore is a mock of RollupCheckpointStore interface.
+type MockRollupCheckpointStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollupCheckpointStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockRollupCheckpointStoreMockRecorder is the mock recorder for MockRollupCheckpointStore.
+type MockRollupCheckpointStoreMockRecorder struct {
+	mock *MockRollupCheckpointStore
+}
+
+// NewMockRollupCheckpointStore creates a new mock instance.
+func NewMockRollupCheckpointStore(ctrl *gomock.Controller) *MockRollupCheckpointStore {
+	mock := &MockRollupCheckpointStore{ctrl: ctrl}
+	mock.recorder = &MockRollupCheckpointStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRollupCheckpointStore) EXPECT() *MockRollupCheckpointStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteRequeued mocks base method.
+func (m *MockRollupCheckpointStore) DeleteRequeued(ctx context.Context, requeue *models.RollupRequeue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRequeued", ctx, requeue)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRequeued indicates an expected call of DeleteRequeued.
+func (mr *MockRollupCheckpointStoreMockRecorder) DeleteRequeued(ctx, requeue any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRequeued", reflect.TypeOf((*MockRollupCheckpointStore)(nil).DeleteRequeued), ctx, requeue)
+}
+
+// Get mocks base method.
+func (m *MockRollupCheckpointStore) Get(ctx context.Context, customerID string, windowSize models.WindowSize) (*models.RollupCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, customerID, windowSize)
+	ret0, _ := ret[0].(*models.RollupCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRollupCheckpointStoreMockRecorder) Get(ctx, customerID, windowSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRollupCheckpointStore)(nil).Get), ctx, customerID, windowSize)
+}
+
+// ListRequeued mocks base method.
+func (m *MockRollupCheckpointStore) ListRequeued(ctx context.Context, customerID string, windowSize models.WindowSize) ([]*models.RollupRequeue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRequeued", ctx, customerID, windowSize)
+	ret0, _ := ret[0].([]*models.RollupRequeue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRequeued indicates an expected call of ListRequeued.
+func (mr *MockRollupCheckpointStoreMockRecorder) ListRequeued(ctx, customerID, windowSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRequeued", reflect.TypeOf((*MockRollupCheckpointStore)(nil).ListRequeued), ctx, customerID, windowSize)
+}
+
+// Put mocks base method.
+func (m *MockRollupCheckpointStore) Put(ctx context.Context, checkpoint *models.RollupCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockRollupCheckpointStoreMockRecorder) Put(ctx, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRollupCheckpointStore)(nil).Put), ctx, checkpoint)
+}
+
+// Requeue mocks base method.
+func (m *MockRollupCheckpointStore) Requeue(ctx context.Context, requeue *models.RollupRequeue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Requeue", ctx, requeue)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Requeue indicates an expected call of Requeue.
+func (mr *MockRollupCheckpointStoreMockRecorder) Requeue(ctx, requeue any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Requeue", reflect.TypeOf((*MockRollupCheckpointStore)(nil).Requeue), ctx, requeue)
+}
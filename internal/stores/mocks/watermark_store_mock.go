@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: watermark_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=watermark_store.go -destination=./mocks/watermark_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWatermarkStore is a mock of WatermarkStore interface.
+type MockWatermarkStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatermarkStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockWatermarkStoreMockRecorder is the mock recorder for MockWatermarkStore.
+type MockWatermarkStoreMockRecorder struct {
+	mock *MockWatermarkStore
+}
+
+// NewMockWatermarkStore creates a new mock instance.
+func NewMockWatermarkStore(ctrl *gomock.Controller) *MockWatermarkStore {
+	mock := &MockWatermarkStore{ctrl: ctrl}
+	mock.recorder = &MockWatermarkStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatermarkStore) EXPECT() *MockWatermarkStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockWatermarkStore) Get(ctx context.Context, customerID string) (*models.Watermark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, customerID)
+	ret0, _ := ret[0].(*models.Watermark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWatermarkStoreMockRecorder) Get(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWatermarkStore)(nil).Get), ctx, customerID)
+}
+
+// Put mocks base method.
+func (m *MockWatermarkStore) Put(ctx context.Context, watermark *models.Watermark) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, watermark)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockWatermarkStoreMockRecorder) Put(ctx, watermark any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockWatermarkStore)(nil).Put), ctx, watermark)
+}
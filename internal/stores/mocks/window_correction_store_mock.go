@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: window_correction_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=window_correction_store.go -destination=./mocks/window_correction_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "log-analytics/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWindowCorrectionStore is a mock of WindowCorrectionStore interface.
+type MockWindowCorrectionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWindowCorrectionStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockWindowCorrectionStoreMockRecorder is the mock recorder for MockWindowCorrectionStore.
+type MockWindowCorrectionStoreMockRecorder struct {
+	mock *MockWindowCorrectionStore
+}
+
+// NewMockWindowCorrectionStore creates a new mock instance.
+func NewMockWindowCorrectionStore(ctrl *gomock.Controller) *MockWindowCorrectionStore {
+	mock := &MockWindowCorrectionStore{ctrl: ctrl}
+	mock.recorder = &MockWindowCorrectionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWindowCorrectionStore) EXPECT() *MockWindowCorrectionStoreMockRecorder {
+	return m.recorder
+}
+
+// Put mocks base method.
+func (m *MockWindowCorrectionStore) Put(ctx context.Context, windowCorrection *models.WindowCorrection) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, windowCorrection)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockWindowCorrectionStoreMockRecorder) Put(ctx, windowCorrection any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockWindowCorrectionStore)(nil).Put), ctx, windowCorrection)
+}
@@ -0,0 +1,141 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"log-analytics/internal/shared/filestorages"
+)
+
+// OutboxRecord marks a stored log batch whose partial insights have not been acknowledged yet.
+type OutboxRecord struct {
+	CustomerID string    `json:"customerId"`
+	BatchID    string    `json:"batchId"`
+	MarkedAt   time.Time `json:"markedAt"`
+}
+
+// OutboxStore implements a transactional outbox next to the raw batches. A batch is marked pending
+// before its raw batch is stored and marked published once all of its partial insights have been
+// produced. Any batch that stays pending (producer failure, crash, cancelled request) is picked up again
+// by the outbox relay, so a stored batch can never silently lose its partial insights.
+//
+// Layout:
+//   - outbox/pending/{customerID}/{batchID}.json   → batch stored, insights not acknowledged yet
+//   - outbox/published/{customerID}/{batchID}.json → insights acknowledged by the producer
+//
+//go:generate mockgen -source=outbox_store.go -destination=./mocks/outbox_store_mock.go -package=mocks
+type OutboxStore interface {
+	MarkPending(ctx context.Context, customerID string, batchID string) error
+	MarkPublished(ctx context.Context, customerID string, batchID string) error
+	// ClearPending removes the pending marker of a batch that was never stored. A missing marker is not an error.
+	ClearPending(ctx context.Context, customerID string, batchID string) error
+	IsPublished(ctx context.Context, customerID string, batchID string) (bool, error)
+	ListPending(ctx context.Context) ([]*OutboxRecord, error)
+}
+
+type outboxStore struct {
+	fileStorage  filestorages.FileStorage
+	pendingDir   string
+	publishedDir string
+}
+
+func NewOutboxStore(fileStorage filestorages.FileStorage) OutboxStore {
+	return &outboxStore{fileStorage: fileStorage, pendingDir: "outbox/pending", publishedDir: "outbox/published"}
+}
+
+func (s *outboxStore) MarkPending(ctx context.Context, customerID string, batchID string) error {
+	if err := s.putRecord(ctx, s.getKey(s.pendingDir, customerID, batchID), customerID, batchID); err != nil {
+		return fmt.Errorf("failed to mark outbox record pending: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) MarkPublished(ctx context.Context, customerID string, batchID string) error {
+	// Write the acknowledgement before removing the pending marker, so a crash in between
+	// leaves a batch that is both pending and published (relay skips it) instead of neither.
+	if err := s.putRecord(ctx, s.getKey(s.publishedDir, customerID, batchID), customerID, batchID); err != nil {
+		return fmt.Errorf("failed to mark outbox record published: %w", err)
+	}
+	return s.ClearPending(ctx, customerID, batchID)
+}
+
+func (s *outboxStore) ClearPending(ctx context.Context, customerID string, batchID string) error {
+	err := s.fileStorage.Delete(ctx, s.getKey(s.pendingDir, customerID, batchID))
+	if err != nil && !errors.Is(err, filestorages.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete pending outbox record: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) IsPublished(ctx context.Context, customerID string, batchID string) (bool, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(s.publishedDir, customerID, batchID))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get published outbox record: %w", err)
+	}
+	_ = readCloser.Close()
+	return true, nil
+}
+
+func (s *outboxStore) ListPending(ctx context.Context) ([]*OutboxRecord, error) {
+	keys, err := s.fileStorage.List(ctx, s.pendingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending outbox records: %w", err)
+	}
+
+	records := make([]*OutboxRecord, 0, len(keys))
+	for _, key := range keys {
+		record, err := s.getRecord(ctx, key)
+		if err != nil {
+			if errors.Is(err, filestorages.ErrFileNotFound) {
+				// Published concurrently since listing
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *outboxStore) putRecord(ctx context.Context, key string, customerID string, batchID string) error {
+	jsonData, err := json.Marshal(&OutboxRecord{
+		CustomerID: customerID,
+		BatchID:    batchID,
+		MarkedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.fileStorage.Put(ctx, key, bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	return err
+}
+
+func (s *outboxStore) getRecord(ctx context.Context, key string) (*OutboxRecord, error) {
+	readCloser, err := s.fileStorage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox record: %w", err)
+	}
+	var record OutboxRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox record: %w", err)
+	}
+	return &record, nil
+}
+
+func (s *outboxStore) getKey(dir string, customerID string, batchID string) string {
+	return fmt.Sprintf("%s/%s/%s.json", dir, customerID, batchID)
+}
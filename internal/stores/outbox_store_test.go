@@ -0,0 +1,207 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxStore_MarkPending_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewOutboxStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Put(ctx, "outbox/pending/cus-axon/batch-123.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var record OutboxRecord
+			require.NoError(t, json.NewDecoder(r).Decode(&record))
+			assert.Equal(t, "cus-axon", record.CustomerID)
+			assert.Equal(t, "batch-123", record.BatchID)
+			assert.False(t, record.MarkedAt.IsZero())
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.MarkPending(ctx, "cus-axon", "batch-123")
+	assert.NoError(t, err)
+}
+
+func TestOutboxStore_MarkPublished_WritesAckBeforeDeletingPending(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewOutboxStore(mockFileStorage)
+
+	ctx := context.Background()
+	gomock.InOrder(
+		mockFileStorage.EXPECT().
+			Put(ctx, "outbox/published/cus-axon/batch-123.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+			Return(&filestorages.PutResult{FileKey: "outbox/published/cus-axon/batch-123.json"}, nil),
+		mockFileStorage.EXPECT().
+			Delete(ctx, "outbox/pending/cus-axon/batch-123.json").
+			Return(filestorages.ErrFileNotFound),
+	)
+
+	err := store.MarkPublished(ctx, "cus-axon", "batch-123")
+	assert.NoError(t, err)
+}
+
+func TestOutboxStore_MarkPublished_PutError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewOutboxStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Put(ctx, "outbox/published/cus-axon/batch-123.json", gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("storage error"))
+
+	err := store.MarkPublished(ctx, "cus-axon", "batch-123")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to mark outbox record published")
+}
+
+func TestOutboxStore_ClearPending(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		deleteError error
+		wantErr     bool
+	}{
+		{name: "cleared"},
+		{name: "already cleared", deleteError: filestorages.ErrFileNotFound},
+		{name: "storage error", deleteError: errors.New("storage error"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			store := NewOutboxStore(mockFileStorage)
+
+			ctx := context.Background()
+			mockFileStorage.EXPECT().Delete(ctx, "outbox/pending/cus-axon/batch-123.json").Return(tt.deleteError)
+
+			err := store.ClearPending(ctx, "cus-axon", "batch-123")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOutboxStore_IsPublished(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getResult io.ReadCloser
+		getError  error
+		expected  bool
+		wantErr   bool
+	}{
+		{
+			name:      "published",
+			getResult: io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			expected:  true,
+		},
+		{
+			name:     "not published",
+			getError: filestorages.ErrFileNotFound,
+			expected: false,
+		},
+		{
+			name:     "storage error",
+			getError: errors.New("storage error"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			store := NewOutboxStore(mockFileStorage)
+
+			ctx := context.Background()
+			mockFileStorage.EXPECT().
+				Get(ctx, "outbox/published/cus-axon/batch-123.json").
+				Return(tt.getResult, tt.getError)
+
+			published, err := store.IsPublished(ctx, "cus-axon", "batch-123")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, published)
+		})
+	}
+}
+
+func TestOutboxStore_ListPending(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewOutboxStore(mockFileStorage)
+
+	ctx := context.Background()
+	markedAt := time.Date(2025, 12, 28, 18, 3, 0, 0, time.UTC)
+	record := &OutboxRecord{CustomerID: "cus-axon", BatchID: "batch-1", MarkedAt: markedAt}
+	jsonData, _ := json.Marshal(record)
+
+	mockFileStorage.EXPECT().
+		List(ctx, "outbox/pending").
+		Return([]string{"outbox/pending/cus-axon/batch-1.json", "outbox/pending/cus-axon/batch-2.json"}, nil)
+	mockFileStorage.EXPECT().
+		Get(ctx, "outbox/pending/cus-axon/batch-1.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+	// batch-2 was published between List and Get
+	mockFileStorage.EXPECT().
+		Get(ctx, "outbox/pending/cus-axon/batch-2.json").
+		Return(nil, filestorages.ErrFileNotFound)
+
+	records, err := store.ListPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "cus-axon", records[0].CustomerID)
+	assert.Equal(t, "batch-1", records[0].BatchID)
+	assert.True(t, markedAt.Equal(records[0].MarkedAt))
+}
@@ -0,0 +1,47 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// RejectedEntryStore quarantines the entries dropped from partially accepted batches, one file per batch:
+//   - rejected-entries/{customerID}/{batchID}.json
+//
+// A retried batch rejects the same entries, so Put overwrites.
+//
+//go:generate mockgen -source=rejected_entry_store.go -destination=./mocks/rejected_entry_store_mock.go -package=mocks
+type RejectedEntryStore interface {
+	Put(ctx context.Context, rejectedBatch *models.RejectedBatch) error
+}
+
+type rejectedEntryStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewRejectedEntryStore(fileStorage filestorages.FileStorage) RejectedEntryStore {
+	return &rejectedEntryStore{fileStorage: fileStorage, dir: "rejected-entries"}
+}
+
+func (s *rejectedEntryStore) Put(ctx context.Context, rejectedBatch *models.RejectedBatch) error {
+	jsonData, err := json.Marshal(rejectedBatch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rejected batch: %w", err)
+	}
+	key := s.getKey(rejectedBatch.CustomerID, rejectedBatch.BatchID)
+	_, err = s.fileStorage.Put(ctx, key, bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put rejected entries: %w", err)
+	}
+	return nil
+}
+
+func (s *rejectedEntryStore) getKey(customerID string, batchID string) string {
+	return fmt.Sprintf("%s/%s/%s.json", s.dir, customerID, batchID)
+}
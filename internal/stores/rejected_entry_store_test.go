@@ -0,0 +1,69 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRejectedEntryStore_Put_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewRejectedEntryStore(mockFileStorage)
+
+	ctx := context.Background()
+	rejectedBatch := &models.RejectedBatch{
+		BatchID:    "batch-123",
+		CustomerID: "cus-axon",
+		RejectedAt: time.Date(2025, 12, 28, 18, 3, 20, 0, time.UTC),
+		Entries: []*models.RejectedEntry{
+			{Index: 1, Reason: "invalid_entry", Code: "ING_1000", Message: "item at index 1: missing path", Raw: `{"method":"GET"}`},
+		},
+	}
+
+	mockFileStorage.EXPECT().
+		Put(ctx, "rejected-entries/cus-axon/batch-123.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored models.RejectedBatch
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, rejectedBatch, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.Put(ctx, rejectedBatch)
+	assert.NoError(t, err)
+}
+
+func TestRejectedEntryStore_Put_StorageError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewRejectedEntryStore(mockFileStorage)
+
+	ctx := context.Background()
+	mockFileStorage.EXPECT().
+		Put(ctx, "rejected-entries/cus-axon/batch-123.json", gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("storage error"))
+
+	err := store.Put(ctx, &models.RejectedBatch{BatchID: "batch-123", CustomerID: "cus-axon"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to put rejected entries")
+}
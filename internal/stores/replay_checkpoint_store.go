@@ -0,0 +1,69 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// ReplayCheckpointStore keeps the progress of every replay next to its output:
+//   - replays/{replayID}/checkpoint.json
+//
+//go:generate mockgen -source=replay_checkpoint_store.go -destination=./mocks/replay_checkpoint_store_mock.go -package=mocks
+type ReplayCheckpointStore interface {
+	// Get returns the checkpoint of the replay, or nil when the replay was not started yet.
+	Get(ctx context.Context, replayID string) (*models.ReplayCheckpoint, error)
+	Put(ctx context.Context, checkpoint *models.ReplayCheckpoint) error
+}
+
+type replayCheckpointStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewReplayCheckpointStore(fileStorage filestorages.FileStorage) ReplayCheckpointStore {
+	return &replayCheckpointStore{fileStorage: fileStorage, dir: "replays"}
+}
+
+func (s *replayCheckpointStore) Get(ctx context.Context, replayID string) (*models.ReplayCheckpoint, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(replayID))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get replay checkpoint: %w", err)
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay checkpoint: %w", err)
+	}
+	var checkpoint models.ReplayCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal replay checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+func (s *replayCheckpointStore) Put(ctx context.Context, checkpoint *models.ReplayCheckpoint) error {
+	jsonData, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal replay checkpoint: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getKey(checkpoint.ReplayID), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put replay checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *replayCheckpointStore) getKey(replayID string) string {
+	return fmt.Sprintf("%s/%s/checkpoint.json", s.dir, replayID)
+}
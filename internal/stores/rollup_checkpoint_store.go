@@ -0,0 +1,145 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// RollupCheckpointStore keeps the progress of the rollup job, one file per customer and target window size, and
+// the target windows requeued for the job, one file per requeue:
+//   - rollup-checkpoints/{customerID}/{windowSize}.json
+//   - rollup-requeues/{customerID}/{windowSize}/{windowStart}/{requeueID}.json
+//
+//go:generate mockgen -source=rollup_checkpoint_store.go -destination=./mocks/rollup_checkpoint_store_mock.go -package=mocks
+type RollupCheckpointStore interface {
+	// Get returns the checkpoint of windowSize, or an empty checkpoint when nothing was rolled up yet.
+	Get(ctx context.Context, customerID string, windowSize models.WindowSize) (*models.RollupCheckpoint, error)
+	Put(ctx context.Context, checkpoint *models.RollupCheckpoint) error
+	// Requeue asks the rollup job to roll a target window up again. Every requeue is kept apart, so one written
+	// while the job rolls the window up is not deleted with the ones it handled.
+	Requeue(ctx context.Context, requeue *models.RollupRequeue) error
+	// ListRequeued returns the requeues of windowSize, ordered by window start.
+	ListRequeued(ctx context.Context, customerID string, windowSize models.WindowSize) ([]*models.RollupRequeue, error)
+	// DeleteRequeued removes a requeue once its window was rolled up again. Deleting a missing requeue is not an
+	// error.
+	DeleteRequeued(ctx context.Context, requeue *models.RollupRequeue) error
+}
+
+type rollupCheckpointStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+	requeueDir  string
+}
+
+func NewRollupCheckpointStore(fileStorage filestorages.FileStorage) RollupCheckpointStore {
+	return &rollupCheckpointStore{fileStorage: fileStorage, dir: "rollup-checkpoints", requeueDir: "rollup-requeues"}
+}
+
+func (s *rollupCheckpointStore) Get(ctx context.Context, customerID string, windowSize models.WindowSize) (*models.RollupCheckpoint, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(customerID, windowSize))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return &models.RollupCheckpoint{CustomerID: customerID, WindowSize: windowSize}, nil
+		}
+		return nil, fmt.Errorf("failed to get rollup checkpoint: %w", err)
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollup checkpoint: %w", err)
+	}
+	var checkpoint models.RollupCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rollup checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+func (s *rollupCheckpointStore) Put(ctx context.Context, checkpoint *models.RollupCheckpoint) error {
+	jsonData, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollup checkpoint: %w", err)
+	}
+	key := s.getKey(checkpoint.CustomerID, checkpoint.WindowSize)
+	_, err = s.fileStorage.Put(ctx, key, bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put rollup checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *rollupCheckpointStore) Requeue(ctx context.Context, requeue *models.RollupRequeue) error {
+	jsonData, err := json.Marshal(requeue)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollup requeue: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getRequeueKey(requeue), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put rollup requeue: %w", err)
+	}
+	return nil
+}
+
+func (s *rollupCheckpointStore) ListRequeued(ctx context.Context, customerID string, windowSize models.WindowSize) ([]*models.RollupRequeue, error) {
+	keys, err := s.fileStorage.List(ctx, fmt.Sprintf("%s/%s/%s", s.requeueDir, customerID, windowSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rollup requeues: %w", err)
+	}
+
+	requeues := make([]*models.RollupRequeue, 0, len(keys))
+	for _, key := range keys {
+		requeue, err := s.getRequeue(ctx, key)
+		if err != nil {
+			if errors.Is(err, filestorages.ErrFileNotFound) {
+				// Handled concurrently since listing
+				continue
+			}
+			return nil, err
+		}
+		requeues = append(requeues, requeue)
+	}
+	return requeues, nil
+}
+
+func (s *rollupCheckpointStore) DeleteRequeued(ctx context.Context, requeue *models.RollupRequeue) error {
+	err := s.fileStorage.Delete(ctx, s.getRequeueKey(requeue))
+	if err != nil && !errors.Is(err, filestorages.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete rollup requeue: %w", err)
+	}
+	return nil
+}
+
+func (s *rollupCheckpointStore) getRequeue(ctx context.Context, key string) (*models.RollupRequeue, error) {
+	readCloser, err := s.fileStorage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollup requeue: %w", err)
+	}
+	var requeue models.RollupRequeue
+	if err := json.Unmarshal(data, &requeue); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rollup requeue: %w", err)
+	}
+	return &requeue, nil
+}
+
+func (s *rollupCheckpointStore) getRequeueKey(requeue *models.RollupRequeue) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s.json", s.requeueDir, requeue.CustomerID, requeue.WindowSize,
+		requeue.WindowSize.FormatWindowStart(requeue.WindowStart), requeue.ID)
+}
+
+func (s *rollupCheckpointStore) getKey(customerID string, windowSize models.WindowSize) string {
+	return fmt.Sprintf("%s/%s/%s.json", s.dir, customerID, windowSize)
+}
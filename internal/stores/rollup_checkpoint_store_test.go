@@ -0,0 +1,174 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRollupCheckpointStore_Put_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewRollupCheckpointStore(mockFileStorage)
+
+	ctx := context.Background()
+	checkpoint := &models.RollupCheckpoint{
+		CustomerID: "cus-axon",
+		WindowSize: models.WindowHour,
+		RolledUpTo: time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2025, 12, 28, 19, 10, 0, 0, time.UTC),
+	}
+
+	mockFileStorage.EXPECT().
+		Put(ctx, "rollup-checkpoints/cus-axon/hour.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored models.RollupCheckpoint
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, checkpoint, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.Put(ctx, checkpoint)
+	assert.NoError(t, err)
+}
+
+func TestRollupCheckpointStore_Get(t *testing.T) {
+	t.Parallel()
+
+	rolledUpTo := time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC)
+	jsonData, _ := json.Marshal(&models.RollupCheckpoint{CustomerID: "cus-axon", WindowSize: models.WindowHour, RolledUpTo: rolledUpTo})
+
+	tests := []struct {
+		name       string
+		getResult  io.ReadCloser
+		getError   error
+		rolledUpTo time.Time
+		wantErr    bool
+	}{
+		{
+			name:       "stored checkpoint",
+			getResult:  io.NopCloser(bytes.NewReader(jsonData)),
+			rolledUpTo: rolledUpTo,
+		},
+		{
+			name:     "no checkpoint yet",
+			getError: filestorages.ErrFileNotFound,
+		},
+		{
+			name:     "storage error",
+			getError: errors.New("storage error"),
+			wantErr:  true,
+		},
+		{
+			name:      "invalid json",
+			getResult: io.NopCloser(bytes.NewReader([]byte(`{`))),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			store := NewRollupCheckpointStore(mockFileStorage)
+
+			ctx := context.Background()
+			mockFileStorage.EXPECT().
+				Get(ctx, "rollup-checkpoints/cus-axon/hour.json").
+				Return(tt.getResult, tt.getError)
+
+			checkpoint, err := store.Get(ctx, "cus-axon", models.WindowHour)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "cus-axon", checkpoint.CustomerID)
+			assert.Equal(t, models.WindowHour, checkpoint.WindowSize)
+			assert.True(t, tt.rolledUpTo.Equal(checkpoint.RolledUpTo))
+		})
+	}
+}
+
+func TestRollupCheckpointStore_Requeue(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewRollupCheckpointStore(mockFileStorage)
+
+	ctx := context.Background()
+	requeue := &models.RollupRequeue{
+		ID:          "requeue-1",
+		CustomerID:  "cus-axon",
+		WindowSize:  models.WindowHour,
+		WindowStart: time.Date(2025, 12, 28, 18, 0, 0, 0, time.UTC),
+		RequeuedAt:  time.Date(2025, 12, 28, 19, 10, 0, 0, time.UTC),
+	}
+	key := "rollup-requeues/cus-axon/hour/20251228T18Z/requeue-1.json"
+
+	mockFileStorage.EXPECT().
+		Put(ctx, key, gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored models.RollupRequeue
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, requeue, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+	mockFileStorage.EXPECT().Delete(ctx, key).Return(filestorages.ErrFileNotFound)
+
+	require.NoError(t, store.Requeue(ctx, requeue))
+	// Deleting a requeue handled already is not an error
+	assert.NoError(t, store.DeleteRequeued(ctx, requeue))
+}
+
+func TestRollupCheckpointStore_ListRequeued(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewRollupCheckpointStore(mockFileStorage)
+
+	ctx := context.Background()
+	jsonData, _ := json.Marshal(&models.RollupRequeue{ID: "requeue-1", CustomerID: "cus-axon", WindowSize: models.WindowHour})
+
+	mockFileStorage.EXPECT().
+		List(ctx, "rollup-requeues/cus-axon/hour").
+		Return([]string{"rollup-requeues/cus-axon/hour/20251228T18Z/requeue-1.json", "rollup-requeues/cus-axon/hour/20251228T18Z/requeue-2.json"}, nil)
+	mockFileStorage.EXPECT().
+		Get(ctx, "rollup-requeues/cus-axon/hour/20251228T18Z/requeue-1.json").
+		Return(io.NopCloser(bytes.NewReader(jsonData)), nil)
+	// requeue-2 was handled between List and Get
+	mockFileStorage.EXPECT().
+		Get(ctx, "rollup-requeues/cus-axon/hour/20251228T18Z/requeue-2.json").
+		Return(nil, filestorages.ErrFileNotFound)
+
+	requeues, err := store.ListRequeued(ctx, "cus-axon", models.WindowHour)
+	require.NoError(t, err)
+	require.Len(t, requeues, 1)
+	assert.Equal(t, "requeue-1", requeues[0].ID)
+}
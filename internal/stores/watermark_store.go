@@ -0,0 +1,69 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// WatermarkStore keeps the event-time watermark of every customer, one file per customer:
+//   - watermarks/{customerID}.json
+//
+//go:generate mockgen -source=watermark_store.go -destination=./mocks/watermark_store_mock.go -package=mocks
+type WatermarkStore interface {
+	// Get returns the watermark of the customer, or an empty watermark when nothing was aggregated yet.
+	Get(ctx context.Context, customerID string) (*models.Watermark, error)
+	Put(ctx context.Context, watermark *models.Watermark) error
+}
+
+type watermarkStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewWatermarkStore(fileStorage filestorages.FileStorage) WatermarkStore {
+	return &watermarkStore{fileStorage: fileStorage, dir: "watermarks"}
+}
+
+func (s *watermarkStore) Get(ctx context.Context, customerID string) (*models.Watermark, error) {
+	readCloser, err := s.fileStorage.Get(ctx, s.getKey(customerID))
+	if err != nil {
+		if errors.Is(err, filestorages.ErrFileNotFound) {
+			return models.NewEmptyWatermark(customerID), nil
+		}
+		return nil, fmt.Errorf("failed to get watermark: %w", err)
+	}
+	defer readCloser.Close()
+
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark: %w", err)
+	}
+	watermark := models.NewEmptyWatermark(customerID)
+	if err := json.Unmarshal(data, watermark); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+func (s *watermarkStore) Put(ctx context.Context, watermark *models.Watermark) error {
+	jsonData, err := json.Marshal(watermark)
+	if err != nil {
+		return fmt.Errorf("failed to marshal watermark: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getKey(watermark.CustomerID), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put watermark: %w", err)
+	}
+	return nil
+}
+
+func (s *watermarkStore) getKey(customerID string) string {
+	return fmt.Sprintf("%s/%s.json", s.dir, customerID)
+}
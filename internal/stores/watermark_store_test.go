@@ -0,0 +1,112 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+	"log-analytics/internal/shared/filestorages/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWatermarkStore_Put_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFileStorage := mocks.NewMockFileStorage(ctrl)
+	store := NewWatermarkStore(mockFileStorage)
+
+	ctx := context.Background()
+	watermark := &models.Watermark{
+		CustomerID:    "cus-axon",
+		MaxReceivedAt: time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC),
+		FinalizedUpTo: map[models.WindowSize]time.Time{models.WindowMinute: time.Date(2025, 12, 28, 17, 58, 0, 0, time.UTC)},
+		UpdatedAt:     time.Date(2025, 12, 28, 18, 3, 20, 0, time.UTC),
+	}
+
+	mockFileStorage.EXPECT().
+		Put(ctx, "watermarks/cus-axon.json", gomock.Any(), filestorages.PutOptions{AllowOverwrite: true}).
+		DoAndReturn(func(ctx context.Context, key string, r io.Reader, opts filestorages.PutOptions) (*filestorages.PutResult, error) {
+			var stored models.Watermark
+			require.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, watermark, &stored)
+			return &filestorages.PutResult{FileKey: key}, nil
+		})
+
+	err := store.Put(ctx, watermark)
+	assert.NoError(t, err)
+}
+
+func TestWatermarkStore_Get(t *testing.T) {
+	t.Parallel()
+
+	maxReceivedAt := time.Date(2025, 12, 28, 18, 3, 15, 0, time.UTC)
+	jsonData, _ := json.Marshal(&models.Watermark{CustomerID: "cus-axon", MaxReceivedAt: maxReceivedAt})
+
+	tests := []struct {
+		name          string
+		getResult     io.ReadCloser
+		getError      error
+		maxReceivedAt time.Time
+		wantErr       bool
+	}{
+		{
+			name:          "stored watermark",
+			getResult:     io.NopCloser(bytes.NewReader(jsonData)),
+			maxReceivedAt: maxReceivedAt,
+		},
+		{
+			name:     "no watermark yet",
+			getError: filestorages.ErrFileNotFound,
+		},
+		{
+			name:     "storage error",
+			getError: errors.New("storage error"),
+			wantErr:  true,
+		},
+		{
+			name:      "invalid json",
+			getResult: io.NopCloser(bytes.NewReader([]byte(`{`))),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFileStorage := mocks.NewMockFileStorage(ctrl)
+			store := NewWatermarkStore(mockFileStorage)
+
+			ctx := context.Background()
+			mockFileStorage.EXPECT().
+				Get(ctx, "watermarks/cus-axon.json").
+				Return(tt.getResult, tt.getError)
+
+			watermark, err := store.Get(ctx, "cus-axon")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "cus-axon", watermark.CustomerID)
+			assert.True(t, tt.maxReceivedAt.Equal(watermark.MaxReceivedAt))
+			// Writable even when the stored watermark has no finalized windows yet
+			assert.NotNil(t, watermark.FinalizedUpTo)
+		})
+	}
+}
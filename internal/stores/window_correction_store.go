@@ -0,0 +1,49 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"log-analytics/internal/models"
+	"log-analytics/internal/shared/filestorages"
+)
+
+// WindowCorrectionStore keeps the audit trail of corrections applied to finalized windows, one file per
+// window revision:
+//   - window-corrections/{customerID}/{windowSize}/{windowStart}/{revision}.json
+//
+// A correction retried after a crash is written again for the same revision, so Put overwrites.
+//
+//go:generate mockgen -source=window_correction_store.go -destination=./mocks/window_correction_store_mock.go -package=mocks
+type WindowCorrectionStore interface {
+	Put(ctx context.Context, windowCorrection *models.WindowCorrection) error
+}
+
+type windowCorrectionStore struct {
+	fileStorage filestorages.FileStorage
+	dir         string
+}
+
+func NewWindowCorrectionStore(fileStorage filestorages.FileStorage) WindowCorrectionStore {
+	return &windowCorrectionStore{fileStorage: fileStorage, dir: "window-corrections"}
+}
+
+func (s *windowCorrectionStore) Put(ctx context.Context, windowCorrection *models.WindowCorrection) error {
+	jsonData, err := json.Marshal(windowCorrection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal window correction: %w", err)
+	}
+	_, err = s.fileStorage.Put(ctx, s.getKey(windowCorrection), bytes.NewReader(jsonData), filestorages.PutOptions{AllowOverwrite: true})
+	if err != nil {
+		return fmt.Errorf("failed to put window correction: %w", err)
+	}
+	return nil
+}
+
+func (s *windowCorrectionStore) getKey(windowCorrection *models.WindowCorrection) string {
+	windowSize := windowCorrection.WindowSize
+	return fmt.Sprintf("%s/%s/%s/%s/%d.json", s.dir, windowCorrection.CustomerID, windowSize,
+		windowSize.FormatWindowStart(windowCorrection.WindowStart), windowCorrection.Revision)
+}
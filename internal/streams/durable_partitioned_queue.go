@@ -0,0 +1,673 @@
+package streams
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"log-analytics/internal/shared/loggers"
+)
+
+// FsyncPolicy controls when appended records are flushed to disk.
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // fsync after every publish and commit
+	FsyncInterval FsyncPolicy = "interval" // fsync dirty segments on a fixed interval
+	FsyncNone     FsyncPolicy = "none"     // leave flushing to the OS, fsync only on close
+)
+
+const (
+	recordHeaderSize       = 8 // uint32 payload length + uint32 crc32 (IEEE) of the payload
+	maxRecordBytes         = 16 * 1024 * 1024
+	defaultSegmentMaxBytes = 64 * 1024 * 1024
+	defaultFsyncInterval   = time.Second
+	readRetryInitialDelay  = 100 * time.Millisecond
+	readRetryMaxDelay      = 30 * time.Second
+
+	segmentFileExt = ".log"
+	offsetFileName = "offset"
+	deadLetterDir  = "dead-letters"
+)
+
+var (
+	ErrQueueClosed   = errors.New("queue closed")
+	errCorruptRecord = errors.New("corrupt record")
+)
+
+// DurableQueueOptions configures a durable PartitionedQueue. Zero values fall back to defaults.
+type DurableQueueOptions struct {
+	Dir             string
+	NumPartitions   int            // defaults to 8
+	Buffer          int            // delivery channel buffer per partition, defaults to 1024
+	SegmentMaxBytes int64          // a partition rolls to a new segment past this size, defaults to 64MB
+	FsyncPolicy     FsyncPolicy    // defaults to FsyncInterval
+	FsyncInterval   time.Duration  // defaults to 1s
+	Logger          loggers.Logger // reports dead-lettered records, the zero Logger discards them
+}
+
+// durablePartitionedQueue is a PartitionedQueue backed by a write-ahead log on local disk.
+//
+// Layout (one directory per partition):
+//   - {dir}/partition-{n}/{baseOffset}.log → segment files, named after the offset of their first record
+//   - {dir}/partition-{n}/offset           → next offset to deliver, written on Commit
+//   - {dir}/partition-{n}/dead-letters/{offset}.json → payloads of records that could not be decoded
+//
+// Each record is framed as [payload length][crc32][JSON payload]. On open every segment is replayed to
+// rebuild the next offset; a torn record at the tail of the last segment (crash mid-write) is truncated.
+// Delivery starts right after the last committed offset, so a restarted consumer resumes exactly where
+// it stopped. Messages processed but not committed before a crash are delivered again. A record whose
+// payload does not decode is copied to the dead letters of its partition and logged before delivery moves
+// past it.
+type durablePartitionedQueue[T any] struct {
+	partitions []*walPartition
+	outs       []chan Message[T]
+	logger     loggers.Logger
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+// NewDurablePartitionedQueue opens (or creates) a durable PartitionedQueue under options.Dir and replays
+// its segments. Delivery goroutines start immediately.
+func NewDurablePartitionedQueue[T any](options DurableQueueOptions) (PartitionedQueue[T], error) {
+	if options.Dir == "" {
+		return nil, errors.New("durable queue dir is required")
+	}
+	if options.NumPartitions <= 0 {
+		options.NumPartitions = defaultNumPartitions
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = defaultBuffer
+	}
+	if options.SegmentMaxBytes <= 0 {
+		options.SegmentMaxBytes = defaultSegmentMaxBytes
+	}
+	if options.FsyncPolicy == "" {
+		options.FsyncPolicy = FsyncInterval
+	}
+	if options.FsyncInterval <= 0 {
+		options.FsyncInterval = defaultFsyncInterval
+	}
+	switch options.FsyncPolicy {
+	case FsyncAlways, FsyncInterval, FsyncNone:
+	default:
+		return nil, fmt.Errorf("invalid fsync policy: %s", options.FsyncPolicy)
+	}
+
+	queue := &durablePartitionedQueue[T]{
+		partitions: make([]*walPartition, options.NumPartitions),
+		outs:       make([]chan Message[T], options.NumPartitions),
+		logger:     options.Logger,
+		closeCh:    make(chan struct{}),
+	}
+	for i := range queue.partitions {
+		partitionDir := filepath.Join(options.Dir, fmt.Sprintf("partition-%d", i))
+		partition, err := openWALPartition(partitionDir, options.SegmentMaxBytes, options.FsyncPolicy == FsyncAlways)
+		if err != nil {
+			for _, opened := range queue.partitions[:i] {
+				_ = opened.close()
+			}
+			return nil, fmt.Errorf("failed to open partition %d: %w", i, err)
+		}
+		queue.partitions[i] = partition
+		queue.outs[i] = make(chan Message[T], options.Buffer)
+	}
+
+	for i := range queue.partitions {
+		queue.wg.Add(1)
+		go func() {
+			defer queue.wg.Done()
+			defer close(queue.outs[i])
+
+			queue.deliver(i)
+		}()
+	}
+	if options.FsyncPolicy == FsyncInterval {
+		queue.wg.Add(1)
+		go func() {
+			defer queue.wg.Done()
+
+			queue.syncPeriodically(options.FsyncInterval)
+		}()
+	}
+	return queue, nil
+}
+
+func (queue *durablePartitionedQueue[T]) PartitionCount() int { return len(queue.partitions) }
+
+func (queue *durablePartitionedQueue[T]) Publish(partitionKey string, msg T) error {
+	select {
+	case <-queue.closeCh:
+		return ErrQueueClosed
+	default:
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	idx := partitionIndex(partitionKey, len(queue.partitions))
+	if _, err := queue.partitions[idx].append(payload); err != nil {
+		return fmt.Errorf("failed to append to partition %d: %w", idx, err)
+	}
+	return nil
+}
+
+func (queue *durablePartitionedQueue[T]) Messages(partition int) <-chan Message[T] {
+	return queue.outs[partition]
+}
+
+func (queue *durablePartitionedQueue[T]) Commit(partition int, offset int64) error {
+	return queue.partitions[partition].commit(offset)
+}
+
+// Close stops delivery, flushes every partition and closes the delivery channels.
+func (queue *durablePartitionedQueue[T]) Close() error {
+	var errs []error
+	queue.closeOnce.Do(func() {
+		close(queue.closeCh)
+		queue.wg.Wait()
+		for _, partition := range queue.partitions {
+			errs = append(errs, partition.close())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// deliver tails a partition from its committed offset and forwards every record to the delivery channel.
+// A record that cannot be read is retried with backoff rather than skipped, so delivery resumes once the
+// segment becomes readable again (e.g. a transient I/O error). A record that reads but does not decode is
+// dead-lettered, retrying until its payload is stored, and skipped.
+func (queue *durablePartitionedQueue[T]) deliver(partitionIdx int) {
+	partition := queue.partitions[partitionIdx]
+	reader := partition.newReader()
+	defer reader.close()
+
+	retryDelay := readRetryInitialDelay
+	// retry waits before the record at offset is read again, and reports false once the queue is closed
+	retry := func(offset int64) bool {
+		metricQueueDeliveryFailedTotal.WithLabelValues(strconv.Itoa(partitionIdx)).Inc()
+		// Reopen the segment on the next attempt, the reader position is unknown after a failed read
+		reader.close()
+		reader.offset = offset
+		timer := time.NewTimer(retryDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-queue.closeCh:
+			return false
+		}
+		retryDelay = min(retryDelay*2, readRetryMaxDelay)
+		return true
+	}
+
+	for {
+		end := partition.endOffset()
+		for reader.offset < end {
+			offset := reader.offset
+			payload, err := reader.next()
+			if err != nil {
+				if !retry(offset) {
+					return
+				}
+				continue
+			}
+			var value T
+			if err := json.Unmarshal(payload, &value); err != nil {
+				deadLetterPath, deadLetterErr := partition.deadLetter(offset, payload)
+				if deadLetterErr != nil {
+					queue.logger.Error().Err(deadLetterErr).
+						Str(loggers.FieldPartitionId, strconv.Itoa(partitionIdx)).
+						Int64("offset", offset).
+						Msg("failed to dead-letter undecodable record")
+					if !retry(offset) {
+						return
+					}
+					continue
+				}
+				metricQueueDeliveryFailedTotal.WithLabelValues(strconv.Itoa(partitionIdx)).Inc()
+				queue.logger.Error().Err(err).
+					Str(loggers.FieldPartitionId, strconv.Itoa(partitionIdx)).
+					Int64("offset", offset).
+					Str("dead_letter", deadLetterPath).
+					Msg("skipped undecodable record")
+				retryDelay = readRetryInitialDelay
+				continue
+			}
+			retryDelay = readRetryInitialDelay
+			select {
+			case queue.outs[partitionIdx] <- Message[T]{Offset: offset, Value: value}:
+			case <-queue.closeCh:
+				return
+			}
+		}
+
+		select {
+		case <-partition.notify:
+		case <-queue.closeCh:
+			return
+		}
+	}
+}
+
+func (queue *durablePartitionedQueue[T]) syncPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-queue.closeCh:
+			return
+		case <-ticker.C:
+			for _, partition := range queue.partitions {
+				_ = partition.sync()
+			}
+		}
+	}
+}
+
+// walPartition is the append-only log of a single partition.
+type walPartition struct {
+	dir             string
+	segmentMaxBytes int64
+	syncEveryWrite  bool
+
+	mu         sync.Mutex
+	segments   []int64 // base offsets, ascending
+	active     *os.File
+	activeSize int64
+	nextOffset int64 // offset of the next appended record
+	committed  int64 // next offset to deliver after a restart
+	dirty      bool
+
+	notify chan struct{}
+}
+
+func openWALPartition(dir string, segmentMaxBytes int64, syncEveryWrite bool) (*walPartition, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	partition := &walPartition{
+		dir:             dir,
+		segmentMaxBytes: segmentMaxBytes,
+		syncEveryWrite:  syncEveryWrite,
+		notify:          make(chan struct{}, 1),
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		segments = []int64{0}
+	}
+	partition.segments = segments
+
+	// Replay every segment to rebuild the next offset
+	var activeSize int64
+	partition.nextOffset = segments[0]
+	for i, base := range segments {
+		if base != partition.nextOffset {
+			return nil, fmt.Errorf("segment %d does not follow offset %d", base, partition.nextOffset)
+		}
+		count, validEnd, size, err := scanSegment(partition.segmentPath(base))
+		if err != nil {
+			return nil, err
+		}
+		isLast := i == len(segments)-1
+		if validEnd < size {
+			if !isLast {
+				return nil, fmt.Errorf("segment %d: %w", base, errCorruptRecord)
+			}
+			// Torn write at the tail, drop the incomplete record
+			if err := os.Truncate(partition.segmentPath(base), validEnd); err != nil {
+				return nil, fmt.Errorf("failed to truncate segment %d: %w", base, err)
+			}
+		}
+		partition.nextOffset += count
+		activeSize = validEnd
+	}
+
+	lastBase := segments[len(segments)-1]
+	partition.active, err = os.OpenFile(partition.segmentPath(lastBase), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	partition.activeSize = activeSize
+
+	committed, err := readOffsetFile(filepath.Join(dir, offsetFileName))
+	if err != nil {
+		_ = partition.active.Close()
+		return nil, err
+	}
+	partition.committed = min(max(committed, segments[0]), partition.nextOffset)
+	return partition, nil
+}
+
+func (p *walPartition) append(payload []byte) (int64, error) {
+	if len(payload) > maxRecordBytes {
+		return 0, fmt.Errorf("record too large: %d bytes", len(payload))
+	}
+	record := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+
+	p.mu.Lock()
+	if p.activeSize > 0 && p.activeSize+int64(len(record)) > p.segmentMaxBytes {
+		if err := p.roll(); err != nil {
+			p.mu.Unlock()
+			return 0, err
+		}
+	}
+	if _, err := p.active.Write(record); err != nil {
+		// Drop a partially written record so the log stays readable
+		_ = p.active.Truncate(p.activeSize)
+		p.mu.Unlock()
+		return 0, err
+	}
+	if p.syncEveryWrite {
+		if err := p.active.Sync(); err != nil {
+			p.mu.Unlock()
+			return 0, err
+		}
+	} else {
+		p.dirty = true
+	}
+	offset := p.nextOffset
+	p.nextOffset++
+	p.activeSize += int64(len(record))
+	p.mu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return offset, nil
+}
+
+// roll closes the active segment and starts a new one at the next offset. Callers hold p.mu.
+func (p *walPartition) roll() error {
+	if err := p.active.Sync(); err != nil {
+		return err
+	}
+	if err := p.active.Close(); err != nil {
+		return err
+	}
+	active, err := os.OpenFile(p.segmentPath(p.nextOffset), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	p.active = active
+	p.activeSize = 0
+	p.dirty = false
+	p.segments = append(p.segments, p.nextOffset)
+	return nil
+}
+
+// commit persists offset+1 as the next offset to deliver and removes segments that are fully committed.
+func (p *walPartition) commit(offset int64) error {
+	next := offset + 1
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if next <= p.committed {
+		return nil
+	}
+	if err := writeOffsetFile(filepath.Join(p.dir, offsetFileName), next, p.syncEveryWrite); err != nil {
+		return fmt.Errorf("failed to write offset: %w", err)
+	}
+	p.committed = next
+
+	for len(p.segments) > 1 && p.segments[1] <= next {
+		if err := os.Remove(p.segmentPath(p.segments[0])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove segment %d: %w", p.segments[0], err)
+		}
+		p.segments = p.segments[1:]
+	}
+	return nil
+}
+
+// deadLetter stores the payload of the record at offset under the dead letters of the partition and returns
+// its path. A record dead-lettered again, e.g. after a restart, overwrites its earlier copy.
+func (p *walPartition) deadLetter(offset int64, payload []byte) (string, error) {
+	dir := filepath.Join(p.dir, deadLetterDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%020d.json", offset))
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (p *walPartition) sync() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.dirty {
+		return nil
+	}
+	p.dirty = false
+	return p.active.Sync()
+}
+
+func (p *walPartition) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.active.Sync(), p.active.Close())
+}
+
+func (p *walPartition) endOffset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nextOffset
+}
+
+// segmentFor returns the base offset of the segment holding offset.
+func (p *walPartition) segmentFor(offset int64) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx := sort.Search(len(p.segments), func(i int) bool { return p.segments[i] > offset })
+	if idx == 0 {
+		return p.segments[0]
+	}
+	return p.segments[idx-1]
+}
+
+func (p *walPartition) segmentPath(base int64) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%020d%s", base, segmentFileExt))
+}
+
+func (p *walPartition) newReader() *walReader {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &walReader{partition: p, offset: p.committed}
+}
+
+// walReader reads records sequentially across the segments of a partition.
+type walReader struct {
+	partition *walPartition
+	offset    int64 // offset of the next record to read
+	base      int64
+	file      *os.File
+	reader    *bufio.Reader
+}
+
+// next returns the record at r.offset. Callers only read offsets below the partition end offset,
+// so the record is always fully written.
+func (r *walReader) next() ([]byte, error) {
+	for {
+		if r.file == nil {
+			if err := r.open(); err != nil {
+				return nil, err
+			}
+		}
+		payload, err := readRecord(r.reader)
+		if errors.Is(err, io.EOF) {
+			// End of this segment, continue in the next one
+			base := r.base
+			r.close()
+			if r.partition.segmentFor(r.offset) == base {
+				return nil, io.ErrUnexpectedEOF
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.offset++
+		return payload, nil
+	}
+}
+
+func (r *walReader) open() error {
+	base := r.partition.segmentFor(r.offset)
+	file, err := os.Open(r.partition.segmentPath(base))
+	if err != nil {
+		return err
+	}
+	r.base = base
+	r.file = file
+	r.reader = bufio.NewReader(file)
+
+	// Skip records before the read offset
+	for skip := r.offset - base; skip > 0; skip-- {
+		if _, err := readRecord(r.reader); err != nil {
+			r.close()
+			return fmt.Errorf("failed to seek to offset %d: %w", r.offset, err)
+		}
+	}
+	return nil
+}
+
+func (r *walReader) close() {
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file = nil
+		r.reader = nil
+	}
+}
+
+// readRecord reads one framed record. It returns io.EOF only at a record boundary.
+func readRecord(reader io.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordBytes {
+		return nil, errCorruptRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errCorruptRecord
+	}
+	return payload, nil
+}
+
+// scanSegment counts the valid records of a segment and returns the byte position right after the last one.
+func scanSegment(path string) (count int64, validEnd int64, size int64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, 0, 0, nil
+		}
+		return 0, 0, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	reader := bufio.NewReader(file)
+	for {
+		payload, err := readRecord(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errCorruptRecord) {
+				return count, validEnd, info.Size(), nil
+			}
+			return 0, 0, 0, err
+		}
+		count++
+		validEnd += int64(recordHeaderSize + len(payload))
+	}
+}
+
+func listSegments(dir string) ([]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileExt) {
+			continue
+		}
+		base, err := strconv.ParseInt(strings.TrimSuffix(name, segmentFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, base)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+func readOffsetFile(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset file %s: %w", path, err)
+	}
+	return offset, nil
+}
+
+// writeOffsetFile replaces the offset file atomically via a temp file and rename.
+func writeOffsetFile(path string, offset int64, fsync bool) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(strconv.FormatInt(offset, 10)); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if fsync {
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,203 @@
+package streams
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	ID int `json:"id"`
+}
+
+func openTestQueue(t *testing.T, dir string, segmentMaxBytes int64) PartitionedQueue[testMessage] {
+	t.Helper()
+	queue, err := NewDurablePartitionedQueue[testMessage](DurableQueueOptions{
+		Dir:             dir,
+		NumPartitions:   1,
+		SegmentMaxBytes: segmentMaxBytes,
+		FsyncPolicy:     FsyncNone,
+	})
+	require.NoError(t, err)
+	return queue
+}
+
+func receive(t *testing.T, queue PartitionedQueue[testMessage], n int) []Message[testMessage] {
+	t.Helper()
+	messages := make([]Message[testMessage], 0, n)
+	for len(messages) < n {
+		select {
+		case message, ok := <-queue.Messages(0):
+			require.True(t, ok, "delivery channel closed")
+			messages = append(messages, message)
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "timed out waiting for messages", "received %d of %d", len(messages), n)
+		}
+	}
+	return messages
+}
+
+func assertNoMessage(t *testing.T, queue PartitionedQueue[testMessage]) {
+	t.Helper()
+	select {
+	case message := <-queue.Messages(0):
+		assert.Fail(t, "unexpected message", "offset %d", message.Offset)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDurablePartitionedQueue_PublishAndReceive(t *testing.T) {
+	t.Parallel()
+
+	queue := openTestQueue(t, t.TempDir(), 0)
+	defer queue.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, queue.Publish("key", testMessage{ID: i}))
+	}
+
+	messages := receive(t, queue, 5)
+	for i, message := range messages {
+		assert.Equal(t, int64(i), message.Offset)
+		assert.Equal(t, i, message.Value.ID)
+	}
+}
+
+func TestDurablePartitionedQueue_ResumesAfterCommittedOffset(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queue := openTestQueue(t, dir, 0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, queue.Publish("key", testMessage{ID: i}))
+	}
+	messages := receive(t, queue, 5)
+	// Only the first 3 messages were processed before shutdown
+	require.NoError(t, queue.Commit(0, messages[2].Offset))
+	require.NoError(t, queue.Close())
+
+	reopened := openTestQueue(t, dir, 0)
+	defer reopened.Close()
+
+	replayed := receive(t, reopened, 2)
+	assert.Equal(t, int64(3), replayed[0].Offset)
+	assert.Equal(t, 3, replayed[0].Value.ID)
+	assert.Equal(t, int64(4), replayed[1].Offset)
+	assert.Equal(t, 4, replayed[1].Value.ID)
+	assertNoMessage(t, reopened)
+
+	// Offsets continue after the replayed log
+	require.NoError(t, reopened.Publish("key", testMessage{ID: 5}))
+	next := receive(t, reopened, 1)
+	assert.Equal(t, int64(5), next[0].Offset)
+}
+
+func TestDurablePartitionedQueue_TruncatesTornTail(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queue := openTestQueue(t, dir, 0)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, queue.Publish("key", testMessage{ID: i}))
+	}
+	require.NoError(t, queue.Close())
+
+	// Simulate a crash in the middle of writing a third record
+	segmentPath := filepath.Join(dir, "partition-0", "00000000000000000000.log")
+	file, err := os.OpenFile(segmentPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{0, 0, 0, 42, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened := openTestQueue(t, dir, 0)
+	defer reopened.Close()
+
+	require.NoError(t, reopened.Publish("key", testMessage{ID: 2}))
+	messages := receive(t, reopened, 3)
+	for i, message := range messages {
+		assert.Equal(t, int64(i), message.Offset)
+		assert.Equal(t, i, message.Value.ID)
+	}
+}
+
+func TestDurablePartitionedQueue_RollsAndRemovesCommittedSegments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	// Every record is larger than half a segment, so each one gets its own segment
+	queue := openTestQueue(t, dir, 16)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, queue.Publish("key", testMessage{ID: i}))
+	}
+	messages := receive(t, queue, 4)
+
+	segments, err := listSegments(filepath.Join(dir, "partition-0"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 2, 3}, segments)
+
+	require.NoError(t, queue.Commit(0, messages[2].Offset))
+	segments, err = listSegments(filepath.Join(dir, "partition-0"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, segments)
+	require.NoError(t, queue.Close())
+
+	reopened := openTestQueue(t, dir, 16)
+	defer reopened.Close()
+
+	replayed := receive(t, reopened, 1)
+	assert.Equal(t, int64(3), replayed[0].Offset)
+	assert.Equal(t, 3, replayed[0].Value.ID)
+}
+
+func TestDurablePartitionedQueue_PublishAfterClose(t *testing.T) {
+	t.Parallel()
+
+	queue := openTestQueue(t, t.TempDir(), 0)
+	require.NoError(t, queue.Close())
+
+	err := queue.Publish("key", testMessage{ID: 1})
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestDurablePartitionedQueue_DeadLettersUndecodableRecord(t *testing.T) {
+	t.Parallel()
+
+	// Written by a producer disagreeing on the message type
+	dir := t.TempDir()
+	writer, err := NewDurablePartitionedQueue[map[string]any](DurableQueueOptions{Dir: dir, NumPartitions: 1, FsyncPolicy: FsyncNone})
+	require.NoError(t, err)
+	require.NoError(t, writer.Publish("key", map[string]any{"id": 0}))
+	require.NoError(t, writer.Publish("key", map[string]any{"id": "one"}))
+	require.NoError(t, writer.Publish("key", map[string]any{"id": 2}))
+	require.NoError(t, writer.Close())
+
+	var logs bytes.Buffer
+	queue, err := NewDurablePartitionedQueue[testMessage](DurableQueueOptions{
+		Dir:           dir,
+		NumPartitions: 1,
+		FsyncPolicy:   FsyncNone,
+		Logger:        zerolog.New(&logs),
+	})
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// Delivery moves past the undecodable record
+	messages := receive(t, queue, 2)
+	assert.Equal(t, int64(0), messages[0].Offset)
+	assert.Equal(t, int64(2), messages[1].Offset)
+	assert.Equal(t, 2, messages[1].Value.ID)
+
+	// But keeps its payload and reports where
+	payload, err := os.ReadFile(filepath.Join(dir, "partition-0", "dead-letters", "00000000000000000001.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"one"}`, string(payload))
+	assert.Contains(t, logs.String(), `"offset":1`)
+	assert.Contains(t, logs.String(), `"partition_id":"0"`)
+}
@@ -23,4 +23,24 @@ var (
 		},
 		[]string{"stream_id", metrics.FieldErrorCode},
 	)
+
+	// metricPartialInsightDeadLetteredTotal counts partial insights moved to the dead letter store.
+	metricPartialInsightDeadLetteredTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubStream,
+			Name:      "partial_insight_dead_lettered_total",
+		},
+		[]string{"stream_id", metrics.FieldErrorCode},
+	)
+
+	// metricQueueDeliveryFailedTotal counts durable queue records that could not be read or decoded.
+	metricQueueDeliveryFailedTotal = metrics.NewCounterVec(
+		metrics.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.SubStream,
+			Name:      "queue_delivery_failed_total",
+		},
+		[]string{"partition_id"},
+	)
 )
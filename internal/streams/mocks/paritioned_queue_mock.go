@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: paritioned_queue.go
+//
+// Generated by this command:
+//
+//	mockgen -source=paritioned_queue.go -destination=./mocks/paritioned_queue_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	streams "log-analytics/internal/streams"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPartitionedQueue is a mock of PartitionedQueue interface.
+type MockPartitionedQueue[T any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockPartitionedQueueMockRecorder[T]
+	isgomock struct{}
+}
+
+// MockPartitionedQueueMockRecorder is the mock recorder for MockPartitionedQueue.
+type MockPartitionedQueueMockRecorder[T any] struct {
+	mock *MockPartitionedQueue[T]
+}
+
+// NewMockPartitionedQueue creates a new mock instance.
+func NewMockPartitionedQueue[T any](ctrl *gomock.Controller) *MockPartitionedQueue[T] {
+	mock := &MockPartitionedQueue[T]{ctrl: ctrl}
+	mock.recorder = &MockPartitionedQueueMockRecorder[T]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPartitionedQueue[T]) EXPECT() *MockPartitionedQueueMockRecorder[T] {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockPartitionedQueue[T]) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockPartitionedQueueMockRecorder[T]) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockPartitionedQueue[T])(nil).Close))
+}
+
+// Commit mocks base method.
+func (m *MockPartitionedQueue[T]) Commit(partition int, offset int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", partition, offset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockPartitionedQueueMockRecorder[T]) Commit(partition, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockPartitionedQueue[T])(nil).Commit), partition, offset)
+}
+
+// Messages mocks base method.
+func (m *MockPartitionedQueue[T]) Messages(partition int) <-chan streams.Message[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Messages", partition)
+	ret0, _ := ret[0].(<-chan streams.Message[T])
+	return ret0
+}
+
+// Messages indicates an expected call of Messages.
+func (mr *MockPartitionedQueueMockRecorder[T]) Messages(partition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Messages", reflect.TypeOf((*MockPartitionedQueue[T])(nil).Messages), partition)
+}
+
+// PartitionCount mocks base method.
+func (m *MockPartitionedQueue[T]) PartitionCount() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PartitionCount")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// PartitionCount indicates an expected call of PartitionCount.
+func (mr *MockPartitionedQueueMockRecorder[T]) PartitionCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PartitionCount", reflect.TypeOf((*MockPartitionedQueue[T])(nil).PartitionCount))
+}
+
+// Publish mocks base method.
+func (m *MockPartitionedQueue[T]) Publish(partitionKey string, msg T) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", partitionKey, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPartitionedQueueMockRecorder[T]) Publish(partitionKey, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPartitionedQueue[T])(nil).Publish), partitionKey, msg)
+}
@@ -3,18 +3,48 @@ package streams
 import (
 	"encoding/binary"
 	"hash/fnv"
+	"sync"
 )
 
-type PartitionedQueue[T any] struct {
-	partitions []chan T
+// Message is a queued value together with its offset within its partition.
+type Message[T any] struct {
+	Offset int64
+	Value  T
 }
 
-func channelsNewPartitionedQueue[T any](numPartitions, buffer int) *PartitionedQueue[T] {
-	channels := make([]chan T, numPartitions)
+// PartitionedQueue routes messages to a fixed number of partitions by partition key.
+// Messages with the same key always land in the same partition and are delivered in publish order.
+//
+//go:generate mockgen -source=paritioned_queue.go -destination=./mocks/paritioned_queue_mock.go -package=mocks
+type PartitionedQueue[T any] interface {
+	PartitionCount() int
+	Publish(partitionKey string, msg T) error
+	// Messages returns the delivery channel of a partition. It is closed when the queue is closed.
+	Messages(partition int) <-chan Message[T]
+	// Commit records that every message of the partition up to and including offset has been processed.
+	// Durable queues resume delivery after the last committed offset on restart.
+	Commit(partition int, offset int64) error
+	Close() error
+}
+
+// memoryPartitionedQueue keeps messages in buffered channels only; anything not yet consumed is lost
+// on crash or shutdown.
+type memoryPartitionedQueue[T any] struct {
+	partitions []chan Message[T]
+	mu         []sync.Mutex
+	offsets    []int64
+}
+
+func channelsNewPartitionedQueue[T any](numPartitions, buffer int) *memoryPartitionedQueue[T] {
+	channels := make([]chan Message[T], numPartitions)
 	for i := range channels {
-		channels[i] = make(chan T, buffer)
+		channels[i] = make(chan Message[T], buffer)
+	}
+	return &memoryPartitionedQueue[T]{
+		partitions: channels,
+		mu:         make([]sync.Mutex, numPartitions),
+		offsets:    make([]int64, numPartitions),
 	}
-	return &PartitionedQueue[T]{partitions: channels}
 }
 
 const (
@@ -22,21 +52,37 @@ const (
 	defaultBuffer        = 1024
 )
 
-func NewPartitionedQueue[T any]() *PartitionedQueue[T] {
+// NewPartitionedQueue creates an in-memory PartitionedQueue.
+func NewPartitionedQueue[T any]() PartitionedQueue[T] {
 	return channelsNewPartitionedQueue[T](defaultNumPartitions, defaultBuffer)
 }
 
-func (queue *PartitionedQueue[T]) PartitionCount() int { return len(queue.partitions) }
+func (queue *memoryPartitionedQueue[T]) PartitionCount() int { return len(queue.partitions) }
 
-func (queue *PartitionedQueue[T]) Publish(partitionKey string, msg T) {
+func (queue *memoryPartitionedQueue[T]) Publish(partitionKey string, msg T) error {
 	idx := partitionIndex(partitionKey, len(queue.partitions))
-	queue.partitions[idx] <- msg
+
+	queue.mu[idx].Lock()
+	defer queue.mu[idx].Unlock()
+	queue.partitions[idx] <- Message[T]{Offset: queue.offsets[idx], Value: msg}
+	queue.offsets[idx]++
+	return nil
+}
+
+func (queue *memoryPartitionedQueue[T]) Messages(partition int) <-chan Message[T] {
+	return queue.partitions[partition]
+}
+
+// Commit is a no-op, there is nothing to resume from after a restart.
+func (queue *memoryPartitionedQueue[T]) Commit(partition int, offset int64) error {
+	return nil
 }
 
-func (queue *PartitionedQueue[T]) Close() {
+func (queue *memoryPartitionedQueue[T]) Close() error {
 	for _, ch := range queue.partitions {
 		close(ch)
 	}
+	return nil
 }
 
 func partitionIndex(key string, n int) int {
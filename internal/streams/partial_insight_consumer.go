@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	"log-analytics/internal/aggregators"
 	"log-analytics/internal/events"
@@ -12,6 +13,7 @@ import (
 	"log-analytics/internal/shared/metrics"
 	"log-analytics/internal/shared/svcerrors"
 	"log-analytics/internal/shared/ulid"
+	"log-analytics/internal/stores"
 )
 
 //go:generate mockgen -source=partial_insight_consumer.go -destination=./mocks/partial_insight_consumer_mock.go -package=mocks
@@ -20,9 +22,19 @@ type PartialInsightConsumer interface {
 	Stop()
 }
 
+// Backoff between attempts of an event that failed with a retryable error
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+)
+
 type partialInsightConsumer struct {
-	queue              *PartitionedQueue[events.PartialInsightEvent]
+	queue              PartitionedQueue[events.PartialInsightEvent]
 	aggregationService aggregators.AggregationService
+	deadLetterStore    stores.DeadLetterStore
+
+	retryInitialBackoff time.Duration
+	retryMaxBackoff     time.Duration
 
 	wg sync.WaitGroup
 
@@ -32,12 +44,18 @@ type partialInsightConsumer struct {
 	logger loggers.Logger
 }
 
-func NewPartialInsightConsumer(queue *PartitionedQueue[events.PartialInsightEvent], aggregationService aggregators.AggregationService, logger loggers.Logger) PartialInsightConsumer {
+// NewPartialInsightConsumer creates a PartialInsightConsumer. An event is committed once it is aggregated, or
+// once it failed with a non-retryable error and was moved to deadLetterStore; retryable failures are retried
+// with backoff, holding back the rest of the partition so no event is skipped.
+func NewPartialInsightConsumer(queue PartitionedQueue[events.PartialInsightEvent], aggregationService aggregators.AggregationService, deadLetterStore stores.DeadLetterStore, logger loggers.Logger) PartialInsightConsumer {
 	return &partialInsightConsumer{
-		queue:              queue,
-		aggregationService: aggregationService,
-		stopCh:             make(chan struct{}),
-		logger:             logger,
+		queue:               queue,
+		aggregationService:  aggregationService,
+		deadLetterStore:     deadLetterStore,
+		retryInitialBackoff: defaultRetryInitialBackoff,
+		retryMaxBackoff:     defaultRetryMaxBackoff,
+		stopCh:              make(chan struct{}),
+		logger:              logger,
 	}
 }
 
@@ -45,7 +63,7 @@ func NewPartialInsightConsumer(queue *PartitionedQueue[events.PartialInsightEven
 // Each partition is a single-writer lane for aggregate keys routed by the producer.
 func (consumer *partialInsightConsumer) Start(ctx context.Context) {
 	for partitionIndex := 0; partitionIndex < consumer.queue.PartitionCount(); partitionIndex++ {
-		ch := consumer.queue.partitions[partitionIndex]
+		ch := consumer.queue.Messages(partitionIndex)
 		consumer.wg.Add(1)
 		go func() {
 			defer consumer.wg.Done()
@@ -61,7 +79,7 @@ func (consumer *partialInsightConsumer) Stop() {
 	consumer.wg.Wait()
 }
 
-func (consumer *partialInsightConsumer) runPartitionWorker(ctx context.Context, partitionIndex int, ch <-chan events.PartialInsightEvent) {
+func (consumer *partialInsightConsumer) runPartitionWorker(ctx context.Context, partitionIndex int, ch <-chan Message[events.PartialInsightEvent]) {
 
 	for {
 		select {
@@ -69,46 +87,101 @@ func (consumer *partialInsightConsumer) runPartitionWorker(ctx context.Context,
 			return
 		case <-consumer.stopCh:
 			return
-		case event, ok := <-ch:
+		case message, ok := <-ch:
 			if !ok {
 				// Channel closed, exit worker
 				return
 			}
-			// Handle panic recovery to prevent worker goroutine from crashing
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						// Log panic details
-						loggers.Ctx(ctx).Error().
-							Bytes(loggers.FieldErrorStack, debug.Stack()).
-							Msg("coonsumer panic recovered")
-
-						// Convert panic value to error
-						var panicErr error
-						if err, ok := r.(error); ok {
-							panicErr = err
-						} else {
-							panicErr = fmt.Errorf("%v", r)
-						}
-
-						// Increment metric with panic error code
-						svcErr := svcerrors.NewInternalErrorPanic(panicErr)
-						metricPartialInsightConsumedTotal.WithLabelValues(streamPartialInsight, svcErr.Code).Inc()
-					}
-				}()
-
-				requestLogger := consumer.logger.With().
+
+			// Commit only when the event was handled to completion; an event interrupted by shutdown
+			// is delivered again after restart by a durable queue.
+			if !consumer.process(ctx, partitionIndex, &message.Value) {
+				return
+			}
+			if err := consumer.queue.Commit(partitionIndex, message.Offset); err != nil {
+				consumer.logger.Error().Err(err).
 					Str(loggers.FieldPartitionId, fmt.Sprintf("%d", partitionIndex)).
-					Str(loggers.FieldRequestID, ulid.NewULID()).
-					Logger()
-
-				svcError := consumer.aggregationService.Aggregate(requestLogger.WithContext(ctx), &event)
-				if svcError != nil {
-					metricPartialInsightConsumedTotal.WithLabelValues(streamPartialInsight, svcError.Code).Inc()
-				} else {
-					metricPartialInsightConsumedTotal.WithLabelValues(streamPartialInsight, metrics.ValueNoError).Inc()
-				}
-			}()
+					Msg("failed to commit partial insight offset")
+			}
+		}
+	}
+}
+
+// process aggregates event until it is applied or dead-lettered and reports whether its offset may be
+// committed. It returns false only when the consumer stops while the event is still failing.
+func (consumer *partialInsightConsumer) process(ctx context.Context, partitionIndex int, event *events.PartialInsightEvent) bool {
+	backoff := consumer.retryInitialBackoff
+	for {
+		requestLogger := consumer.logger.With().
+			Str(loggers.FieldPartitionId, fmt.Sprintf("%d", partitionIndex)).
+			Str(loggers.FieldRequestID, ulid.NewULID()).
+			Logger()
+		requestCtx := requestLogger.WithContext(ctx)
+
+		svcError := consumer.aggregate(requestCtx, event)
+		if svcError == nil {
+			metricPartialInsightConsumedTotal.WithLabelValues(streamPartialInsight, metrics.ValueNoError).Inc()
+			return true
+		}
+		metricPartialInsightConsumedTotal.WithLabelValues(streamPartialInsight, svcError.Code).Inc()
+
+		if !svcError.IsRetryable() {
+			reason := svcError.Error()
+			if svcError.Cause != nil {
+				reason = svcError.Cause.Error()
+			}
+			err := consumer.deadLetterStore.Put(requestCtx, &stores.DeadLetter{
+				Event:          event,
+				ErrorCode:      svcError.Code,
+				Error:          reason,
+				DeadLetteredAt: time.Now().UTC(),
+			})
+			if err == nil {
+				requestLogger.Error().Err(svcError).
+					Msgf("dead-lettered partial insight of batch %s for customer %s", event.BatchID, event.CustomerID)
+				metricPartialInsightDeadLetteredTotal.WithLabelValues(streamPartialInsight, svcError.Code).Inc()
+				return true
+			}
+			// Retry until the event is kept somewhere, committing it now would lose it
+			requestLogger.Error().Err(err).Msg("failed to dead-letter partial insight")
+		} else {
+			requestLogger.Warn().Err(svcError).Msgf("failed to aggregate partial insight, retrying in %s", backoff)
 		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-consumer.stopCh:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, consumer.retryMaxBackoff)
 	}
 }
+
+// aggregate runs one aggregation attempt, converting a panic into a permanent error.
+func (consumer *partialInsightConsumer) aggregate(ctx context.Context, event *events.PartialInsightEvent) (svcError *svcerrors.ServiceError) {
+	// Handle panic recovery to prevent worker goroutine from crashing
+	defer func() {
+		if r := recover(); r != nil {
+			// Log panic details
+			loggers.Ctx(ctx).Error().
+				Bytes(loggers.FieldErrorStack, debug.Stack()).
+				Msg("consumer panic recovered")
+
+			// Convert panic value to error
+			var panicErr error
+			if err, ok := r.(error); ok {
+				panicErr = err
+			} else {
+				panicErr = fmt.Errorf("%v", r)
+			}
+			svcError = svcerrors.NewInternalErrorPanic(panicErr)
+		}
+	}()
+
+	return consumer.aggregationService.Aggregate(ctx, event)
+}
@@ -0,0 +1,113 @@
+package streams
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	aggregatormocks "log-analytics/internal/aggregators/mocks"
+	"log-analytics/internal/events"
+	"log-analytics/internal/shared/svcerrors"
+	"log-analytics/internal/stores"
+	storemocks "log-analytics/internal/stores/mocks"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestConsumer(ctrl *gomock.Controller) (*partialInsightConsumer, *aggregatormocks.MockAggregationService, *storemocks.MockDeadLetterStore) {
+	aggregationService := aggregatormocks.NewMockAggregationService(ctrl)
+	deadLetterStore := storemocks.NewMockDeadLetterStore(ctrl)
+	consumer := NewPartialInsightConsumer(NewPartitionedQueue[events.PartialInsightEvent](), aggregationService, deadLetterStore, zerolog.Nop()).(*partialInsightConsumer)
+	consumer.retryInitialBackoff = time.Millisecond
+	consumer.retryMaxBackoff = time.Millisecond
+	return consumer, aggregationService, deadLetterStore
+}
+
+func TestPartialInsightConsumer_Process_RetriesRetryableErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consumer, aggregationService, _ := newTestConsumer(ctrl)
+	event := &events.PartialInsightEvent{CustomerID: "customer1", BatchID: "batch-1"}
+	gomock.InOrder(
+		aggregationService.EXPECT().Aggregate(gomock.Any(), event).Return(svcerrors.NewInternalError("AGG_9001", assert.AnError)),
+		aggregationService.EXPECT().Aggregate(gomock.Any(), event).Return(svcerrors.NewInternalError("AGG_9001", assert.AnError)),
+		aggregationService.EXPECT().Aggregate(gomock.Any(), event).Return(nil),
+	)
+
+	assert.True(t, consumer.process(context.Background(), 0, event))
+}
+
+func TestPartialInsightConsumer_Process_DeadLettersNonRetryableErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		aggregate func(ctx context.Context, event *events.PartialInsightEvent) *svcerrors.ServiceError
+		errorCode string
+	}{
+		{
+			name: "permanent error",
+			aggregate: func(ctx context.Context, event *events.PartialInsightEvent) *svcerrors.ServiceError {
+				return svcerrors.NewPermanentInternalError("AGG_9000", assert.AnError)
+			},
+			errorCode: "AGG_9000",
+		},
+		{
+			name: "panic",
+			aggregate: func(ctx context.Context, event *events.PartialInsightEvent) *svcerrors.ServiceError {
+				panic("boom")
+			},
+			errorCode: "SYS_9000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consumer, aggregationService, deadLetterStore := newTestConsumer(ctrl)
+			event := &events.PartialInsightEvent{CustomerID: "customer1", BatchID: "batch-1"}
+			aggregationService.EXPECT().Aggregate(gomock.Any(), event).DoAndReturn(tt.aggregate)
+			// A failed dead-letter write is retried, the event must not be committed before it is kept
+			gomock.InOrder(
+				deadLetterStore.EXPECT().Put(gomock.Any(), gomock.Any()).Return(assert.AnError),
+				deadLetterStore.EXPECT().Put(gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, deadLetter *stores.DeadLetter) {
+						assert.Equal(t, event, deadLetter.Event)
+						assert.Equal(t, tt.errorCode, deadLetter.ErrorCode)
+					}).
+					Return(nil),
+			)
+			aggregationService.EXPECT().Aggregate(gomock.Any(), event).DoAndReturn(tt.aggregate)
+
+			assert.True(t, consumer.process(context.Background(), 0, event))
+		})
+	}
+}
+
+func TestPartialInsightConsumer_Process_StopsWithoutCommitting(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consumer, aggregationService, _ := newTestConsumer(ctrl)
+	consumer.retryInitialBackoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	event := &events.PartialInsightEvent{CustomerID: "customer1", BatchID: "batch-1"}
+	aggregationService.EXPECT().Aggregate(gomock.Any(), event).
+		DoAndReturn(func(ctx context.Context, event *events.PartialInsightEvent) *svcerrors.ServiceError {
+			cancel()
+			return svcerrors.NewInternalError("AGG_9001", assert.AnError)
+		})
+
+	assert.False(t, consumer.process(ctx, 0, event))
+}
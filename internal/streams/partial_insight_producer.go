@@ -37,12 +37,16 @@ type PartialInsightProducer interface {
 }
 
 type partialInsightProducer struct {
-	queue *PartitionedQueue[events.PartialInsightEvent]
+	queue     PartitionedQueue[events.PartialInsightEvent]
+	timeZones map[string]*time.Location
 }
 
-func NewPartialInsightProducer(queue *PartitionedQueue[events.PartialInsightEvent]) PartialInsightProducer {
+// NewPartialInsightProducer creates a PartialInsightProducer. timeZones holds the time zone of every customer
+// with a non-UTC one, see models.WindowSize.BucketID.
+func NewPartialInsightProducer(queue PartitionedQueue[events.PartialInsightEvent], timeZones map[string]*time.Location) PartialInsightProducer {
 	return &partialInsightProducer{
-		queue: queue,
+		queue:     queue,
+		timeZones: timeZones,
 	}
 }
 
@@ -56,18 +60,11 @@ func (producer *partialInsightProducer) Produce(ctx context.Context, batchSummar
 		}
 
 		// Create PartialInsightEvent for this window
-		event := events.PartialInsightEvent{
-			CustomerID:          batchSummary.CustomerID,
-			BatchID:             batchSummary.BatchID,
-			WindowStart:         windowStart,
-			WindowSize:          batchSummary.WindowSize,
-			RequestsByPath:      windowAggregates.RequestsByPath,
-			RequestsByUserAgent: windowAggregates.RequestsByUserAgent,
-		}
-		partitionKey := event.WindowSize.BucketID(event.WindowStart)
+		event := events.NewPartialInsightEvent(batchSummary, windowStart, windowAggregates)
+		partitionKey := event.WindowSize.BucketID(event.WindowStart, producer.timeZones[event.CustomerID])
 
 		// Publish the event
-		if err := producer.publishPartialInsightEvent(ctx, partitionKey, event); err != nil {
+		if err := producer.publishPartialInsightEvent(ctx, partitionKey, *event); err != nil {
 			return err
 		}
 		metricPartialInsightProducedTotal.WithLabelValues(streamPartialInsight).Inc()
@@ -85,6 +82,5 @@ func (producer *partialInsightProducer) publishPartialInsightEvent(ctx context.C
 	}
 
 	// Partition by aggregate identity (single-writer guarantee).
-	producer.queue.Publish(partitionKey, event)
-	return nil
+	return producer.queue.Publish(partitionKey, event)
 }